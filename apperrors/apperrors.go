@@ -0,0 +1,105 @@
+// Package apperrors defines the errors that services report to their callers. Every error has a
+// kind, which decides how it is presented, and a stable code that clients can match on.
+package apperrors
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+type Kind string
+
+const (
+	KindValidation   Kind = "validation"
+	KindUnauthorized Kind = "unauthorized"
+	KindForbidden    Kind = "forbidden"
+	KindNotFound     Kind = "not_found"
+	KindConflict     Kind = "conflict"
+	KindRateLimited  Kind = "rate_limited"
+	KindTooLarge     Kind = "too_large"
+	KindInternal     Kind = "internal"
+)
+
+// CodeInternal is the code of every error that is not an *Error.
+const CodeInternal = "internal_error"
+
+type Error struct {
+	Kind Kind
+	// Code identifies the error for clients. Codes are part of the API and must not change.
+	Code string
+	// Message is the English message, with Params filled in.
+	Message string
+	// Params are the values of the {0}, {1}, ... placeholders of the message, so that it can be
+	// translated by code.
+	Params []string
+	Err    error
+}
+
+// New creates an error whose message template has a placeholder {i} for each of params.
+func New(kind Kind, code string, message string, params ...string) *Error {
+	for i, param := range params {
+		message = strings.ReplaceAll(message, "{"+strconv.Itoa(i)+"}", param)
+	}
+	return &Error{Kind: kind, Code: code, Message: message, Params: params}
+}
+
+func Validation(code string, message string, params ...string) *Error {
+	return New(KindValidation, code, message, params...)
+}
+
+func Unauthorized(code string, message string, params ...string) *Error {
+	return New(KindUnauthorized, code, message, params...)
+}
+
+func Forbidden(code string, message string, params ...string) *Error {
+	return New(KindForbidden, code, message, params...)
+}
+
+func NotFound(code string, message string, params ...string) *Error {
+	return New(KindNotFound, code, message, params...)
+}
+
+func Conflict(code string, message string, params ...string) *Error {
+	return New(KindConflict, code, message, params...)
+}
+
+func RateLimited(code string, message string, params ...string) *Error {
+	return New(KindRateLimited, code, message, params...)
+}
+
+func TooLarge(code string, message string, params ...string) *Error {
+	return New(KindTooLarge, code, message, params...)
+}
+
+// Internal wraps an unexpected error. Its message is never shown to clients.
+func Internal(err error) *Error {
+	return &Error{Kind: KindInternal, Code: CodeInternal, Message: "internal server error", Err: err}
+}
+
+// From returns err as an *Error, treating any other error as internal.
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return Internal(err)
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports errors with the same code as equal, so that errors.Is matches a predefined error
+// even when it was returned with other fields or a cause.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
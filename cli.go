@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"home-monitor-backend/services"
+	"io"
+	"os"
+	"strings"
+)
+
+const usage = `Usage: home-monitor-backend [command] [arguments]
+
+Commands:
+  serve                                     run the API server and background workers (default)
+  migrate up|down|status|force              manage the database schema
+  seed                                      insert a seed profile (dev, demo or test)
+  user create|list|reset-password|set-role  manage user accounts
+  device create                             register a device and print its token
+  token issue                               issue an API token for a user
+  import                                    import a CSV file of readings
+  simulate                                  send readings from virtual devices to a running server
+
+Run a command with -h for its arguments.
+`
+
+// commands lists the valid first arguments, so that a typo fails before anything is started.
+var commands = map[string]bool{
+	"serve":    true,
+	"migrate":  true,
+	"seed":     true,
+	"user":     true,
+	"device":   true,
+	"token":    true,
+	"import":   true,
+	"simulate": true,
+}
+
+// parseCommand splits the arguments into the command and its own arguments. Without a command
+// the server is started.
+func parseCommand(args []string) (string, []string, error) {
+	if len(args) == 0 {
+		return "serve", nil, nil
+	}
+	if args[0] == "help" || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+		fmt.Print(usage)
+		os.Exit(0)
+	}
+	if !commands[args[0]] {
+		fmt.Fprint(os.Stderr, usage)
+		return "", nil, fmt.Errorf("unknown command %q", args[0])
+	}
+	return args[0], args[1:], nil
+}
+
+// runCommand runs one of the administration commands that work on the services directly.
+func runCommand(ctx context.Context, command string, args []string, userService services.UserService, deviceTypeService services.DeviceTypeService, deviceService services.DeviceService, importService services.ImportService) error {
+	switch command {
+	case "user":
+		return runUser(ctx, userService, args)
+	case "device":
+		return runDevice(ctx, userService, deviceTypeService, deviceService, args)
+	case "token":
+		return runToken(ctx, userService, args)
+	case "import":
+		return runImport(ctx, importService, args)
+	case "simulate":
+		return runSimulate(ctx, userService, deviceTypeService, deviceService, args)
+	}
+	return fmt.Errorf("unknown command %q", command)
+}
+
+// subcommand splits off the action of commands like "user create".
+func subcommand(command string, actions string, args []string) (string, []string, error) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return "", nil, fmt.Errorf("usage: %s %s", command, actions)
+	}
+	return args[0], args[1:], nil
+}
+
+// readPassword reads the password from the first line of stdin when fromStdin is set, and
+// otherwise generates one. Passwords are never taken as arguments, which end up in the shell
+// history and the process list.
+func readPassword(fromStdin bool) (string, bool, error) {
+	if !fromStdin {
+		b := make([]byte, 18)
+		if _, err := rand.Read(b); err != nil {
+			return "", false, err
+		}
+		return base64.RawURLEncoding.EncodeToString(b), true, nil
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", false, err
+	}
+	password := strings.TrimRight(line, "\r\n")
+	if len(password) < 6 {
+		return "", false, errors.New("password must be at least 6 characters")
+	}
+	return password, false, nil
+}
@@ -0,0 +1,414 @@
+package config
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"home-monitor-backend/ratelimit"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the whole application configuration. Every field is read from the environment
+// variable named by its env tag; fields tagged secret are redacted when the configuration is
+// printed or logged.
+type Config struct {
+	GinMode     string `env:"GIN_MODE"`
+	LogLevel    string `env:"LOG_LEVEL"`
+	Seed        bool   `env:"SEED"`
+	SeedProfile string `env:"SEED_PROFILE"`
+
+	Server    Server
+	Database  Database
+	JWT       JWT
+	RateLimit RateLimit
+	CORS      CORS
+
+	ExportDir      string `env:"EXPORT_DIR"`
+	ImportDir      string `env:"IMPORT_DIR"`
+	ImportMaxBytes int64  `env:"IMPORT_MAX_BYTES"`
+	MetricsToken   string `env:"METRICS_TOKEN" secret:"true"`
+}
+
+type Server struct {
+	Addr            string        `env:"HTTP_ADDR"`
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT"`
+	// TrustedProxies are the addresses and CIDR ranges of the reverse proxies whose
+	// X-Forwarded-For and X-Real-IP headers are believed. Without any, the client address is the
+	// address of the peer.
+	TrustedProxies []string `env:"TRUSTED_PROXIES"`
+	// HSTSMaxAge is sent in Strict-Transport-Security; 0 leaves the header out.
+	HSTSMaxAge time.Duration `env:"HSTS_MAX_AGE"`
+}
+
+// CORS lets browser applications on other origins call the API. Without allowed origins, no
+// cross-origin request is allowed.
+type CORS struct {
+	// AllowedOrigins are origins such as https://app.example.com, or * for any origin.
+	AllowedOrigins   []string      `env:"CORS_ALLOWED_ORIGINS"`
+	AllowCredentials bool          `env:"CORS_ALLOW_CREDENTIALS"`
+	MaxAge           time.Duration `env:"CORS_MAX_AGE"`
+}
+
+// RateLimit holds the policies of the rate limited routes, written as limit/period or off, and
+// where the buckets are kept: in memory, per instance, or in the database, shared by all instances.
+type RateLimit struct {
+	Store  string           `env:"RATE_LIMIT_STORE"`
+	Login  ratelimit.Policy `env:"RATE_LIMIT_LOGIN"`
+	Ingest ratelimit.Policy `env:"RATE_LIMIT_INGEST"`
+	Query  ratelimit.Policy `env:"RATE_LIMIT_QUERY"`
+}
+
+// Rate limit stores.
+const (
+	RateLimitStoreMemory   = "memory"
+	RateLimitStoreDatabase = "database"
+)
+
+// Database selects the driver and how to reach it. SQLite only needs Path; MySQL and PostgreSQL
+// need the server settings.
+type Database struct {
+	Driver       string        `env:"DB_DRIVER"`
+	Path         string        `env:"DB_PATH"`
+	User         string        `env:"DB_USER"`
+	Password     string        `env:"DB_PASSWORD" secret:"true"`
+	Host         string        `env:"DB_HOST"`
+	Port         string        `env:"DB_PORT"`
+	Name         string        `env:"DB_NAME"`
+	SSLMode      string        `env:"DB_SSLMODE"`
+	QueryTimeout time.Duration `env:"DB_QUERY_TIMEOUT"`
+}
+
+// Database drivers.
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+var defaultPorts = map[string]string{
+	DriverMySQL:    "3306",
+	DriverPostgres: "5432",
+}
+
+type JWT struct {
+	Secret     string        `env:"JWT_SECRET" required:"true" secret:"true"`
+	Expiration time.Duration `env:"JWT_EXPIRATION"`
+}
+
+func Default() *Config {
+	return &Config{
+		GinMode:     "debug",
+		LogLevel:    "info",
+		SeedProfile: "dev",
+		Server: Server{
+			Addr:            ":8080",
+			ShutdownTimeout: 15 * time.Second,
+			HSTSMaxAge:      365 * 24 * time.Hour,
+		},
+		Database: Database{
+			Driver:       DriverMySQL,
+			Path:         "storage/home-monitor.db",
+			SSLMode:      "prefer",
+			QueryTimeout: 30 * time.Second,
+		},
+		JWT: JWT{
+			Expiration: 24 * time.Hour,
+		},
+		RateLimit: RateLimit{
+			Store:  RateLimitStoreMemory,
+			Login:  ratelimit.Policy{Limit: 10, Period: time.Minute},
+			Ingest: ratelimit.Policy{Limit: 600, Period: time.Minute},
+			Query:  ratelimit.Policy{Limit: 300, Period: time.Minute},
+		},
+		CORS: CORS{
+			MaxAge: 10 * time.Minute,
+		},
+		ExportDir:      "storage/exports",
+		ImportDir:      "storage/imports",
+		ImportMaxBytes: 64 << 20,
+	}
+}
+
+// Load builds the configuration from, in increasing order of precedence, the defaults, the
+// YAML or TOML file named by CONFIG_FILE, a .env file in the working directory and the
+// environment. The file and .env are optional. The result is validated.
+func Load() (*Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("loading .env: %w", err)
+	}
+
+	cfg := Default()
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		values, err := readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := cfg.apply(func(key string) (string, bool) {
+			value, ok := values[key]
+			return value, ok
+		}); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	if err := cfg.apply(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	if cfg.Database.Port == "" {
+		cfg.Database.Port = defaultPorts[cfg.Database.Driver]
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate reports every missing required field and invalid value at once.
+func (c *Config) Validate() error {
+	var errs []error
+	eachField(reflect.ValueOf(c).Elem(), func(field reflect.StructField, value reflect.Value) {
+		if field.Tag.Get("required") == "true" && value.IsZero() {
+			errs = append(errs, fmt.Errorf("%s is required", field.Tag.Get("env")))
+		}
+	})
+
+	switch c.Database.Driver {
+	case DriverMySQL, DriverPostgres:
+		for _, setting := range [][2]string{
+			{"DB_USER", c.Database.User},
+			{"DB_HOST", c.Database.Host},
+			{"DB_PORT", c.Database.Port},
+			{"DB_NAME", c.Database.Name},
+		} {
+			if setting[1] == "" {
+				errs = append(errs, fmt.Errorf("%s is required with DB_DRIVER=%s", setting[0], c.Database.Driver))
+			}
+		}
+	case DriverSQLite:
+		if c.Database.Path == "" {
+			errs = append(errs, errors.New("DB_PATH is required with DB_DRIVER=sqlite"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("DB_DRIVER must be mysql, postgres or sqlite, got %q", c.Database.Driver))
+	}
+
+	if c.GinMode != "debug" && c.GinMode != "release" && c.GinMode != "test" {
+		errs = append(errs, fmt.Errorf("GIN_MODE must be debug, release or test, got %q", c.GinMode))
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		errs = append(errs, fmt.Errorf("LOG_LEVEL must be debug, info, warn or error, got %q", c.LogLevel))
+	}
+	if c.JWT.Expiration <= 0 {
+		errs = append(errs, errors.New("JWT_EXPIRATION must be positive"))
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("SHUTDOWN_TIMEOUT must be positive"))
+	}
+	if c.Database.QueryTimeout < 0 {
+		errs = append(errs, errors.New("DB_QUERY_TIMEOUT must not be negative"))
+	}
+	if c.Server.HSTSMaxAge < 0 {
+		errs = append(errs, errors.New("HSTS_MAX_AGE must not be negative"))
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		if _, err := netip.ParsePrefix(proxy); err != nil {
+			if _, err := netip.ParseAddr(proxy); err != nil {
+				errs = append(errs, fmt.Errorf("TRUSTED_PROXIES must hold IP addresses or CIDR ranges, got %q", proxy))
+			}
+		}
+	}
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			if c.CORS.AllowCredentials {
+				errs = append(errs, errors.New("CORS_ALLOWED_ORIGINS must list the origins instead of * with CORS_ALLOW_CREDENTIALS"))
+			}
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" || u.RawQuery != "" {
+			errs = append(errs, fmt.Errorf("CORS_ALLOWED_ORIGINS must hold origins such as https://app.example.com or *, got %q", origin))
+		}
+	}
+	if c.ImportMaxBytes <= 0 {
+		errs = append(errs, errors.New("IMPORT_MAX_BYTES must be positive"))
+	}
+	if c.CORS.MaxAge < 0 {
+		errs = append(errs, errors.New("CORS_MAX_AGE must not be negative"))
+	}
+	if c.RateLimit.Store != RateLimitStoreMemory && c.RateLimit.Store != RateLimitStoreDatabase {
+		errs = append(errs, fmt.Errorf("RATE_LIMIT_STORE must be memory or database, got %q", c.RateLimit.Store))
+	}
+	return errors.Join(errs...)
+}
+
+// Redacted returns every setting keyed by its environment variable, with secrets masked.
+func (c *Config) Redacted() map[string]string {
+	settings := make(map[string]string)
+	eachField(reflect.ValueOf(c).Elem(), func(field reflect.StructField, value reflect.Value) {
+		text := fmt.Sprint(value.Interface())
+		if list, ok := value.Interface().([]string); ok {
+			text = strings.Join(list, ",")
+		}
+		if field.Tag.Get("secret") == "true" && text != "" {
+			text = "[REDACTED]"
+		}
+		settings[field.Tag.Get("env")] = text
+	})
+	return settings
+}
+
+func (c *Config) String() string {
+	settings := c.Redacted()
+	var b strings.Builder
+	for _, key := range slices.Sorted(maps.Keys(settings)) {
+		fmt.Fprintf(&b, "%s=%s\n", key, settings[key])
+	}
+	return b.String()
+}
+
+func (c *Config) LogValue() slog.Value {
+	settings := c.Redacted()
+	attrs := make([]slog.Attr, 0, len(settings))
+	for _, key := range slices.Sorted(maps.Keys(settings)) {
+		attrs = append(attrs, slog.String(key, settings[key]))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// apply sets every field that lookup finds a value for.
+func (c *Config) apply(lookup func(key string) (string, bool)) error {
+	var errs []error
+	eachField(reflect.ValueOf(c).Elem(), func(field reflect.StructField, value reflect.Value) {
+		key := field.Tag.Get("env")
+		text, ok := lookup(key)
+		if !ok {
+			return
+		}
+		if err := setField(value, text); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	})
+
+	// JWT_EXPIRATION_HOURS predates JWT_EXPIRATION and is still honored when the same source does
+	// not set the latter.
+	if _, ok := lookup("JWT_EXPIRATION"); !ok {
+		if hours, ok := lookup("JWT_EXPIRATION_HOURS"); ok {
+			n, err := strconv.Atoi(hours)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("JWT_EXPIRATION_HOURS: %w", err))
+			} else {
+				c.JWT.Expiration = time.Duration(n) * time.Hour
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// eachField calls fn for every field with an env tag, descending into the untagged structs that
+// group them.
+func eachField(v reflect.Value, fn func(field reflect.StructField, value reflect.Value)) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		value := v.Field(i)
+		if field.Tag.Get("env") != "" {
+			fn(field, value)
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct {
+			eachField(value, fn)
+		}
+	}
+}
+
+func setField(value reflect.Value, text string) error {
+	if unmarshaler, ok := value.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(text))
+	}
+
+	switch value.Interface().(type) {
+	case time.Duration:
+		d, err := time.ParseDuration(text)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(d))
+	case int64:
+		n, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return err
+		}
+		value.SetInt(n)
+	case bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		value.SetBool(b)
+	case string:
+		value.SetString(text)
+	case []string:
+		var list []string
+		for _, item := range strings.Split(text, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		value.Set(reflect.ValueOf(list))
+	default:
+		return fmt.Errorf("unsupported type %s", value.Type())
+	}
+	return nil
+}
+
+// readFile reads a flat YAML or TOML file whose keys are the environment variable names, in
+// upper or lower case.
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make(map[string]any)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return nil, fmt.Errorf("%s: unsupported config file format, use .yaml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	values := make(map[string]string, len(raw))
+	for key, value := range raw {
+		// Lists are read like the comma-separated values of the environment.
+		if list, ok := value.([]any); ok {
+			items := make([]string, len(list))
+			for i, item := range list {
+				items[i] = fmt.Sprint(item)
+			}
+			value = strings.Join(items, ",")
+		}
+		values[strings.ToUpper(key)] = fmt.Sprint(value)
+	}
+	return values, nil
+}
@@ -0,0 +1,47 @@
+package config_test
+
+import (
+	"home-monitor-backend/config"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadLegacyJWTExpiration(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		env  map[string]string
+		want time.Duration
+	}{
+		{name: "environment", env: map[string]string{"JWT_EXPIRATION_HOURS": "2"}, want: 2 * time.Hour},
+		{name: "file", file: "jwt_expiration_hours: 2\n", want: 2 * time.Hour},
+		{name: "new key in the same source", file: "jwt_expiration: 30m\njwt_expiration_hours: 2\n", want: 30 * time.Minute},
+		{name: "environment over file", file: "jwt_expiration: 30m\n", env: map[string]string{"JWT_EXPIRATION_HOURS": "3"}, want: 3 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("JWT_SECRET", "secret")
+			t.Setenv("DB_DRIVER", config.DriverSQLite)
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			if tt.file != "" {
+				path := filepath.Join(t.TempDir(), "config.yaml")
+				if err := os.WriteFile(path, []byte(tt.file), 0o600); err != nil {
+					t.Fatal(err)
+				}
+				t.Setenv("CONFIG_FILE", path)
+			}
+
+			cfg, err := config.Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.JWT.Expiration != tt.want {
+				t.Errorf("JWT expiration = %s, want %s", cfg.JWT.Expiration, tt.want)
+			}
+		})
+	}
+}
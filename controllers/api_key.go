@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"home-monitor-backend/models"
+	"home-monitor-backend/services"
+	"home-monitor-backend/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type APIKeyController struct {
+	apiKeyService services.APIKeyService
+}
+
+func NewAPIKeyController(apiKeyService services.APIKeyService) *APIKeyController {
+	return &APIKeyController{apiKeyService: apiKeyService}
+}
+
+// APIKeyCreate godoc
+// @Summary Create API key
+// @Description Create a read-only API key for scraping telemetry. Set home to limit the key to the devices of one home. The key is only returned once.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param request body models.APIKeyCreateRequest true "API key create request"
+// @Success 201 {object} models.APIKeyCreateResponse
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 404 {object} models.ProblemDetails
+// @Failure 500 {object} models.ProblemDetails
+// @Security BearerAuth
+// @Router /api-keys [post]
+func (ctrl *APIKeyController) APIKeyCreate(c *gin.Context) {
+	userUUID, exists := c.Get("userUUID")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	var input models.APIKeyCreateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(utils.ValidationError(err))
+		return
+	}
+
+	apiKey, key, err := ctrl.apiKeyService.APIKeyCreate(c.Request.Context(), input, userUUID.(uuid.UUID))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIKeyCreateResponse{
+		UUID:      apiKey.UUID,
+		Name:      apiKey.Name,
+		Home:      apiKey.Home,
+		Key:       key,
+		CreatedAt: apiKey.CreatedAt,
+	})
+}
+
+// APIKeyList godoc
+// @Summary List API keys
+// @Description List the API keys of the authenticated user
+// @Tags api-keys
+// @Produce json
+// @Success 200 {array} models.APIKeyResponse
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 404 {object} models.ProblemDetails
+// @Failure 500 {object} models.ProblemDetails
+// @Security BearerAuth
+// @Router /api-keys [get]
+func (ctrl *APIKeyController) APIKeyList(c *gin.Context) {
+	userUUID, exists := c.Get("userUUID")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	keys, err := ctrl.apiKeyService.APIKeyList(c.Request.Context(), userUUID.(uuid.UUID))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response := make([]models.APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		response = append(response, models.APIKeyResponse{
+			UUID:       key.UUID,
+			Name:       key.Name,
+			Home:       key.Home,
+			LastUsedAt: key.LastUsedAt,
+			CreatedAt:  key.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// APIKeyDelete godoc
+// @Summary Revoke API key
+// @Description Delete an API key of the authenticated user
+// @Tags api-keys
+// @Param uuid path string true "API key UUID"
+// @Success 204
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 404 {object} models.ProblemDetails
+// @Failure 500 {object} models.ProblemDetails
+// @Security BearerAuth
+// @Router /api-keys/{uuid} [delete]
+func (ctrl *APIKeyController) APIKeyDelete(c *gin.Context) {
+	userUUID, exists := c.Get("userUUID")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	keyUUID, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		c.Error(errInvalidUUID(c.Param("uuid")))
+		return
+	}
+
+	if err := ctrl.apiKeyService.APIKeyDelete(c.Request.Context(), userUUID.(uuid.UUID), keyUUID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
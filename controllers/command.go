@@ -0,0 +1,243 @@
+package controllers
+
+import (
+	"home-monitor-backend/apperrors"
+	"home-monitor-backend/models"
+	"home-monitor-backend/services"
+	"home-monitor-backend/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CommandController struct {
+	commandService services.CommandService
+}
+
+func NewCommandController(commandService services.CommandService) *CommandController {
+	return &CommandController{commandService: commandService}
+}
+
+// CommandCreate godoc
+// @Summary Queue device command
+// @Description Enqueue a command for a device. It is delivered on the next pull or pushed immediately when a push transport is configured.
+// @Tags commands
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Device UUID"
+// @Param request body models.CommandCreateRequest true "Command create request"
+// @Success 201 {object} models.CommandResponse
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 404 {object} models.ProblemDetails
+// @Failure 500 {object} models.ProblemDetails
+// @Security BearerAuth
+// @Router /devices/{uuid}/commands [post]
+func (ctrl *CommandController) CommandCreate(c *gin.Context) {
+	userUUID, exists := c.Get("userUUID")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	deviceUUID, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		c.Error(errInvalidUUID(c.Param("uuid")))
+		return
+	}
+
+	var input models.CommandCreateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(utils.ValidationError(err))
+		return
+	}
+
+	command, err := ctrl.commandService.CommandCreate(c.Request.Context(), input, userUUID.(uuid.UUID), deviceUUID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, commandResponse(command))
+}
+
+// CommandList godoc
+// @Summary List device commands
+// @Description Retrieve the most recent commands of a device, newest first
+// @Tags commands
+// @Produce json
+// @Param uuid path string true "Device UUID"
+// @Param status query string false "Filter by status" Enums(pending, sent, acked, failed, expired)
+// @Success 200 {array} models.CommandResponse
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 404 {object} models.ProblemDetails
+// @Failure 500 {object} models.ProblemDetails
+// @Security BearerAuth
+// @Router /devices/{uuid}/commands [get]
+func (ctrl *CommandController) CommandList(c *gin.Context) {
+	userUUID, exists := c.Get("userUUID")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	deviceUUID, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		c.Error(errInvalidUUID(c.Param("uuid")))
+		return
+	}
+
+	status := models.CommandStatus(c.Query("status"))
+	switch status {
+	case "", models.CommandStatusPending, models.CommandStatusSent, models.CommandStatusAcked,
+		models.CommandStatusFailed, models.CommandStatusExpired:
+	default:
+		c.Error(apperrors.Validation("invalid_command_status", "Invalid command status"))
+		return
+	}
+
+	commands, err := ctrl.commandService.CommandList(c.Request.Context(), userUUID.(uuid.UUID), deviceUUID, status)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, commandResponses(commands))
+}
+
+// CommandDetail godoc
+// @Summary Get device command
+// @Description Retrieve a single command of a device including its result
+// @Tags commands
+// @Produce json
+// @Param uuid path string true "Device UUID"
+// @Param command_uuid path string true "Command UUID"
+// @Success 200 {object} models.CommandResponse
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 404 {object} models.ProblemDetails
+// @Security BearerAuth
+// @Router /devices/{uuid}/commands/{command_uuid} [get]
+func (ctrl *CommandController) CommandDetail(c *gin.Context) {
+	userUUID, exists := c.Get("userUUID")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	deviceUUID, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		c.Error(errInvalidUUID(c.Param("uuid")))
+		return
+	}
+
+	commandUUID, err := uuid.Parse(c.Param("command_uuid"))
+	if err != nil {
+		c.Error(errInvalidUUID(c.Param("command_uuid")))
+		return
+	}
+
+	command, err := ctrl.commandService.CommandDetail(c.Request.Context(), userUUID.(uuid.UUID), deviceUUID, commandUUID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, commandResponse(command))
+}
+
+// CommandPull godoc
+// @Summary Pull pending commands
+// @Description Device endpoint returning every unexpired command that has not been acknowledged yet. Returned commands are marked as sent.
+// @Tags device
+// @Produce json
+// @Param X-Device-Token header string true "Device token"
+// @Success 200 {array} models.CommandResponse
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 500 {object} models.ProblemDetails
+// @Router /device/commands [get]
+func (ctrl *CommandController) CommandPull(c *gin.Context) {
+	device, exists := c.Get("device")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	commands, err := ctrl.commandService.CommandPull(c.Request.Context(), device.(*models.Device))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, commandResponses(commands))
+}
+
+// CommandAck godoc
+// @Summary Acknowledge command
+// @Description Device endpoint reporting the outcome of a command as acked or failed, with an optional result
+// @Tags device
+// @Accept json
+// @Produce json
+// @Param X-Device-Token header string true "Device token"
+// @Param command_uuid path string true "Command UUID"
+// @Param request body models.CommandAckRequest true "Command acknowledgement"
+// @Success 200 {object} models.CommandResponse
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 404 {object} models.ProblemDetails
+// @Failure 409 {object} models.ProblemDetails
+// @Failure 500 {object} models.ProblemDetails
+// @Router /device/commands/{command_uuid}/ack [post]
+func (ctrl *CommandController) CommandAck(c *gin.Context) {
+	device, exists := c.Get("device")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	commandUUID, err := uuid.Parse(c.Param("command_uuid"))
+	if err != nil {
+		c.Error(errInvalidUUID(c.Param("command_uuid")))
+		return
+	}
+
+	var input models.CommandAckRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(utils.ValidationError(err))
+		return
+	}
+
+	command, err := ctrl.commandService.CommandAck(c.Request.Context(), input, device.(*models.Device), commandUUID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, commandResponse(command))
+}
+
+func commandResponse(command *models.Command) models.CommandResponse {
+	return models.CommandResponse{
+		UUID:        command.UUID,
+		Name:        command.Name,
+		Params:      command.Params,
+		Status:      command.Status,
+		Result:      command.Result,
+		Error:       command.Error,
+		ExpiresAt:   command.ExpiresAt,
+		SentAt:      command.SentAt,
+		CompletedAt: command.CompletedAt,
+		CreatedAt:   command.CreatedAt,
+		UpdatedAt:   command.UpdatedAt,
+	}
+}
+
+func commandResponses(commands []models.Command) []models.CommandResponse {
+	response := make([]models.CommandResponse, 0, len(commands))
+	for i := range commands {
+		response = append(response, commandResponse(&commands[i]))
+	}
+	return response
+}
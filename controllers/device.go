@@ -0,0 +1,185 @@
+package controllers
+
+import (
+	"home-monitor-backend/models"
+	"home-monitor-backend/services"
+	"home-monitor-backend/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type DeviceController struct {
+	deviceService services.DeviceService
+}
+
+func NewDeviceController(deviceService services.DeviceService) *DeviceController {
+	return &DeviceController{deviceService: deviceService}
+}
+
+// DeviceCreate godoc
+// @Summary Create device
+// @Description Register a new device owned by the authenticated user. The device token is only returned once.
+// @Tags devices
+// @Accept json
+// @Produce json
+// @Param request body models.DeviceCreateRequest true "Device create request"
+// @Success 201 {object} models.DeviceCreateResponse
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 404 {object} models.ProblemDetails
+// @Failure 500 {object} models.ProblemDetails
+// @Security BearerAuth
+// @Router /devices [post]
+func (ctrl *DeviceController) DeviceCreate(c *gin.Context) {
+	userUUID, exists := c.Get("userUUID")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	var input models.DeviceCreateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(utils.ValidationError(err))
+		return
+	}
+
+	device, token, err := ctrl.deviceService.DeviceCreate(c.Request.Context(), input, userUUID.(uuid.UUID))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.DeviceCreateResponse{
+		UUID:           device.UUID,
+		Name:           device.Name,
+		DeviceTypeUUID: device.DeviceTypeUUID(),
+		Home:           device.Home,
+		Room:           device.Room,
+		Token:          token,
+		CreatedAt:      device.CreatedAt,
+		UpdatedAt:      device.UpdatedAt,
+	})
+}
+
+// DeviceList godoc
+// @Summary List devices
+// @Description List the devices of the authenticated user, or every device for admins
+// @Tags devices
+// @Produce json
+// @Success 200 {array} models.DeviceResponse
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 404 {object} models.ProblemDetails
+// @Failure 500 {object} models.ProblemDetails
+// @Security BearerAuth
+// @Router /devices [get]
+func (ctrl *DeviceController) DeviceList(c *gin.Context) {
+	userUUID, exists := c.Get("userUUID")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	devices, err := ctrl.deviceService.DeviceList(c.Request.Context(), userUUID.(uuid.UUID))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response := make([]models.DeviceResponse, 0, len(devices))
+	for i := range devices {
+		response = append(response, deviceResponse(&devices[i]))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// DeviceDetail godoc
+// @Summary Get device
+// @Description Retrieve a single device
+// @Tags devices
+// @Produce json
+// @Param uuid path string true "Device UUID"
+// @Success 200 {object} models.DeviceResponse
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 404 {object} models.ProblemDetails
+// @Security BearerAuth
+// @Router /devices/{uuid} [get]
+func (ctrl *DeviceController) DeviceDetail(c *gin.Context) {
+	userUUID, exists := c.Get("userUUID")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	deviceUUID, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		c.Error(errInvalidUUID(c.Param("uuid")))
+		return
+	}
+
+	device, err := ctrl.deviceService.DeviceDetail(c.Request.Context(), userUUID.(uuid.UUID), deviceUUID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deviceResponse(device))
+}
+
+// DeviceUpdate godoc
+// @Summary Update device
+// @Description Rename a device or change its home and room labels. Only the fields that are set are changed.
+// @Tags devices
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Device UUID"
+// @Param request body models.DeviceUpdateRequest true "Device update request"
+// @Success 200 {object} models.DeviceResponse
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 404 {object} models.ProblemDetails
+// @Failure 500 {object} models.ProblemDetails
+// @Security BearerAuth
+// @Router /devices/{uuid} [put]
+func (ctrl *DeviceController) DeviceUpdate(c *gin.Context) {
+	userUUID, exists := c.Get("userUUID")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	deviceUUID, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		c.Error(errInvalidUUID(c.Param("uuid")))
+		return
+	}
+
+	var input models.DeviceUpdateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(utils.ValidationError(err))
+		return
+	}
+
+	device, err := ctrl.deviceService.DeviceUpdate(c.Request.Context(), input, userUUID.(uuid.UUID), deviceUUID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deviceResponse(device))
+}
+
+func deviceResponse(device *models.Device) models.DeviceResponse {
+	return models.DeviceResponse{
+		UUID:           device.UUID,
+		Name:           device.Name,
+		DeviceTypeUUID: device.DeviceTypeUUID(),
+		Home:           device.Home,
+		Room:           device.Room,
+		LastSeenAt:     device.LastSeenAt,
+		CreatedAt:      device.CreatedAt,
+		UpdatedAt:      device.UpdatedAt,
+	}
+}
@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"home-monitor-backend/models"
+	"home-monitor-backend/services"
+	"home-monitor-backend/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type DeviceTypeController struct {
+	deviceTypeService services.DeviceTypeService
+}
+
+func NewDeviceTypeController(deviceTypeService services.DeviceTypeService) *DeviceTypeController {
+	return &DeviceTypeController{deviceTypeService: deviceTypeService}
+}
+
+// DeviceTypeCreate godoc
+// @Summary Create device type
+// @Description Declare a device type with the metrics it reports and the commands it accepts
+// @Tags device-types
+// @Accept json
+// @Produce json
+// @Param request body models.DeviceTypeCreateRequest true "Device type create request"
+// @Success 201 {object} models.DeviceTypeResponse
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 403 {object} models.ProblemDetails
+// @Failure 409 {object} models.ProblemDetails
+// @Failure 500 {object} models.ProblemDetails
+// @Security BearerAuth
+// @Router /device-types [post]
+func (ctrl *DeviceTypeController) DeviceTypeCreate(c *gin.Context) {
+	userUUID, exists := c.Get("userUUID")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	var input models.DeviceTypeCreateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(utils.ValidationError(err))
+		return
+	}
+
+	deviceType, err := ctrl.deviceTypeService.DeviceTypeCreate(c.Request.Context(), input, userUUID.(uuid.UUID))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, deviceTypeResponse(deviceType))
+}
+
+// DeviceTypeList godoc
+// @Summary List device types
+// @Description List every declared device type with its capabilities
+// @Tags device-types
+// @Produce json
+// @Success 200 {array} models.DeviceTypeResponse
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 500 {object} models.ProblemDetails
+// @Security BearerAuth
+// @Router /device-types [get]
+func (ctrl *DeviceTypeController) DeviceTypeList(c *gin.Context) {
+	deviceTypes, err := ctrl.deviceTypeService.DeviceTypeList(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response := make([]models.DeviceTypeResponse, 0, len(deviceTypes))
+	for i := range deviceTypes {
+		response = append(response, deviceTypeResponse(&deviceTypes[i]))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// DeviceTypeDetail godoc
+// @Summary Get device type
+// @Description Retrieve a single device type with its capabilities
+// @Tags device-types
+// @Produce json
+// @Param uuid path string true "Device type UUID"
+// @Success 200 {object} models.DeviceTypeResponse
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 404 {object} models.ProblemDetails
+// @Security BearerAuth
+// @Router /device-types/{uuid} [get]
+func (ctrl *DeviceTypeController) DeviceTypeDetail(c *gin.Context) {
+	deviceTypeUUID, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		c.Error(errInvalidUUID(c.Param("uuid")))
+		return
+	}
+
+	deviceType, err := ctrl.deviceTypeService.DeviceTypeDetail(c.Request.Context(), deviceTypeUUID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deviceTypeResponse(deviceType))
+}
+
+func deviceTypeResponse(deviceType *models.DeviceType) models.DeviceTypeResponse {
+	return models.DeviceTypeResponse{
+		UUID:         deviceType.UUID,
+		Name:         deviceType.Name,
+		Description:  deviceType.Description,
+		Capabilities: deviceType.Capabilities,
+		CreatedAt:    deviceType.CreatedAt,
+		UpdatedAt:    deviceType.UpdatedAt,
+	}
+}
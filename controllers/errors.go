@@ -0,0 +1,10 @@
+package controllers
+
+import "home-monitor-backend/apperrors"
+
+// errUnauthorized is reported when a handler runs without the identity its auth middleware sets.
+var errUnauthorized = apperrors.Unauthorized("unauthorized", "Unauthorized")
+
+func errInvalidUUID(value string) error {
+	return apperrors.Validation("invalid_uuid", "invalid UUID {0}", value)
+}
@@ -0,0 +1,230 @@
+package controllers
+
+import (
+	"fmt"
+	"home-monitor-backend/models"
+	"home-monitor-backend/services"
+	"home-monitor-backend/utils"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ExportController struct {
+	exportService services.ExportService
+}
+
+func NewExportController(exportService services.ExportService) *ExportController {
+	return &ExportController{exportService: exportService}
+}
+
+// ExportReadings godoc
+// @Summary Export readings
+// @Description Stream the readings of the given devices and time range as CSV or Parquet, ordered by device, metric and time. Use an export job for large ranges.
+// @Tags export
+// @Produce text/csv
+// @Produce application/vnd.apache.parquet
+// @Param device_uuid query []string true "Device UUIDs" collectionFormat(multi)
+// @Param metric query []string false "Metric names, all metrics when omitted" collectionFormat(multi)
+// @Param from query string true "Start of the time range (RFC 3339, inclusive)"
+// @Param to query string true "End of the time range (RFC 3339, exclusive)"
+// @Param format query string false "File format (default csv)" Enums(csv, parquet)
+// @Param units query string false "Convert values and times to the user's preferred units and timezone" Enums(preferred)
+// @Success 200 {file} file
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 404 {object} models.ProblemDetails
+// @Security BearerAuth
+// @Router /export/readings [get]
+func (ctrl *ExportController) ExportReadings(c *gin.Context) {
+	userUUID, exists := c.Get("userUUID")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	var request models.ReadingExportRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		c.Error(utils.ValidationError(err))
+		return
+	}
+
+	write, err := ctrl.exportService.ExportReadings(c.Request.Context(), userUUID.(uuid.UUID), request)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if request.Format == "" {
+		request.Format = models.ExportFormatCSV
+	}
+
+	c.Header("Content-Type", request.Format.ContentType())
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="readings-%s.%s"`, request.From.UTC().Format("20060102T150405Z"), request.Format))
+	c.Status(http.StatusOK)
+
+	// The status line is already sent, so a failure halfway can only cut the download short.
+	if _, err := write(c.Writer); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Export stream failed", "error", err)
+		c.Abort()
+	}
+}
+
+// ExportJobCreate godoc
+// @Summary Create export job
+// @Description Queue an export that is written to storage in the background. Poll the job and download the file once it is completed.
+// @Tags export
+// @Accept json
+// @Produce json
+// @Param request body models.ReadingExportRequest true "Export request"
+// @Success 202 {object} models.ExportJobResponse
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 404 {object} models.ProblemDetails
+// @Failure 500 {object} models.ProblemDetails
+// @Security BearerAuth
+// @Router /export/jobs [post]
+func (ctrl *ExportController) ExportJobCreate(c *gin.Context) {
+	userUUID, exists := c.Get("userUUID")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	var request models.ReadingExportRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.Error(utils.ValidationError(err))
+		return
+	}
+
+	job, err := ctrl.exportService.ExportJobCreate(c.Request.Context(), userUUID.(uuid.UUID), request)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, exportJobResponse(job))
+}
+
+// ExportJobList godoc
+// @Summary List export jobs
+// @Description Retrieve the most recent export jobs of the authenticated user, newest first
+// @Tags export
+// @Produce json
+// @Success 200 {array} models.ExportJobResponse
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 404 {object} models.ProblemDetails
+// @Failure 500 {object} models.ProblemDetails
+// @Security BearerAuth
+// @Router /export/jobs [get]
+func (ctrl *ExportController) ExportJobList(c *gin.Context) {
+	userUUID, exists := c.Get("userUUID")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	jobs, err := ctrl.exportService.ExportJobList(c.Request.Context(), userUUID.(uuid.UUID))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response := make([]models.ExportJobResponse, 0, len(jobs))
+	for i := range jobs {
+		response = append(response, exportJobResponse(&jobs[i]))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ExportJobDetail godoc
+// @Summary Get export job
+// @Description Retrieve the status of an export job. Completed jobs include a download URL.
+// @Tags export
+// @Produce json
+// @Param uuid path string true "Export job UUID"
+// @Success 200 {object} models.ExportJobResponse
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 404 {object} models.ProblemDetails
+// @Security BearerAuth
+// @Router /export/jobs/{uuid} [get]
+func (ctrl *ExportController) ExportJobDetail(c *gin.Context) {
+	userUUID, exists := c.Get("userUUID")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	jobUUID, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		c.Error(errInvalidUUID(c.Param("uuid")))
+		return
+	}
+
+	job, err := ctrl.exportService.ExportJobDetail(c.Request.Context(), userUUID.(uuid.UUID), jobUUID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, exportJobResponse(job))
+}
+
+// ExportJobDownload godoc
+// @Summary Download export
+// @Description Download the file of a completed export job
+// @Tags export
+// @Produce text/csv
+// @Produce application/vnd.apache.parquet
+// @Param uuid path string true "Export job UUID"
+// @Success 200 {file} file
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 404 {object} models.ProblemDetails
+// @Failure 409 {object} models.ProblemDetails
+// @Security BearerAuth
+// @Router /export/jobs/{uuid}/download [get]
+func (ctrl *ExportController) ExportJobDownload(c *gin.Context) {
+	userUUID, exists := c.Get("userUUID")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	jobUUID, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		c.Error(errInvalidUUID(c.Param("uuid")))
+		return
+	}
+
+	job, err := ctrl.exportService.ExportJobFile(c.Request.Context(), userUUID.(uuid.UUID), jobUUID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Content-Type", job.Request.Format.ContentType())
+	c.FileAttachment(job.FilePath, "readings-"+filepath.Base(job.FilePath))
+}
+
+func exportJobResponse(job *models.ExportJob) models.ExportJobResponse {
+	response := models.ExportJobResponse{
+		UUID:        job.UUID,
+		Status:      job.Status,
+		Request:     job.Request,
+		RowCount:    job.RowCount,
+		Error:       job.Error,
+		StartedAt:   job.StartedAt,
+		CompletedAt: job.CompletedAt,
+		ExpiresAt:   job.ExpiresAt,
+		CreatedAt:   job.CreatedAt,
+	}
+	if job.Status == models.ExportStatusCompleted {
+		response.DownloadURL = "/api/export/jobs/" + job.UUID.String() + "/download"
+	}
+	return response
+}
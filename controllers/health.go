@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"home-monitor-backend/models"
+	"home-monitor-backend/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthController struct {
+	healthService services.HealthService
+}
+
+func NewHealthController(healthService services.HealthService) *HealthController {
+	return &HealthController{healthService: healthService}
+}
+
+// HealthLive reports that the process is up and serving requests. It checks no dependencies, so
+// that a database outage does not get the container restarted.
+func (ctrl *HealthController) HealthLive(c *gin.Context) {
+	c.JSON(http.StatusOK, ctrl.healthService.HealthLive(c.Request.Context()))
+}
+
+// HealthReady reports whether the instance can take traffic: 200 when every critical check
+// passes, even if optional subsystems are degraded, and 503 otherwise.
+func (ctrl *HealthController) HealthReady(c *gin.Context) {
+	readiness := ctrl.healthService.HealthReady(c.Request.Context())
+	if readiness.Status == models.HealthStatusUnavailable {
+		c.JSON(http.StatusServiceUnavailable, readiness)
+		return
+	}
+	c.JSON(http.StatusOK, readiness)
+}
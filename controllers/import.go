@@ -0,0 +1,203 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"home-monitor-backend/apperrors"
+	"home-monitor-backend/models"
+	"home-monitor-backend/services"
+	"home-monitor-backend/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+)
+
+// importFormMemory is how much of an upload is held in memory; the rest is spooled to disk.
+const importFormMemory = 32 << 20
+
+var errImportTooLarge = apperrors.TooLarge("file_too_large", "File is too large")
+
+type ImportController struct {
+	importService services.ImportService
+	maxBytes      int64
+}
+
+func NewImportController(importService services.ImportService, maxBytes int64) *ImportController {
+	return &ImportController{importService: importService, maxBytes: maxBytes}
+}
+
+// ImportCreate godoc
+// @Summary Import readings from CSV
+// @Description Upload a CSV file with a header row and queue it for import. The mapping names the timestamp column and the column of each metric. Rows are validated against the metric schema, readings that already exist for the same metric and time are skipped, and errors are reported per row.
+// @Tags imports
+// @Accept multipart/form-data
+// @Produce json
+// @Param uuid path string true "Device UUID"
+// @Param file formData file true "CSV file"
+// @Param mapping formData string true "Column mapping as JSON, see models.ImportMapping"
+// @Success 202 {object} models.ImportJobResponse
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 404 {object} models.ProblemDetails
+// @Failure 413 {object} models.ProblemDetails
+// @Failure 500 {object} models.ProblemDetails
+// @Security BearerAuth
+// @Router /devices/{uuid}/imports [post]
+func (ctrl *ImportController) ImportCreate(c *gin.Context) {
+	userUUID, exists := c.Get("userUUID")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	deviceUUID, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		c.Error(errInvalidUUID(c.Param("uuid")))
+		return
+	}
+
+	// Gin ignores form parsing errors, so the form is parsed here to tell an oversized upload apart.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, ctrl.maxBytes)
+	var maxBytesErr *http.MaxBytesError
+	if err := c.Request.ParseMultipartForm(importFormMemory); errors.As(err, &maxBytesErr) {
+		c.Error(errImportTooLarge)
+		return
+	}
+
+	var mapping models.ImportMapping
+	if err := json.Unmarshal([]byte(c.PostForm("mapping")), &mapping); err != nil {
+		c.Error(apperrors.Validation("invalid_mapping", "invalid mapping: {0}", "must be a JSON object"))
+		return
+	}
+	if err := binding.Validator.ValidateStruct(&mapping); err != nil {
+		c.Error(utils.ValidationError(err))
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.Error(apperrors.Validation("file_required", "File is required"))
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.Error(apperrors.Validation("invalid_file", "Invalid file"))
+		return
+	}
+	defer file.Close()
+
+	job, err := ctrl.importService.ImportCreate(c.Request.Context(), userUUID.(uuid.UUID), deviceUUID, mapping, file)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, importJobResponse(job))
+}
+
+// ImportList godoc
+// @Summary List imports
+// @Description Retrieve the most recent import jobs of a device, newest first
+// @Tags imports
+// @Produce json
+// @Param uuid path string true "Device UUID"
+// @Success 200 {array} models.ImportJobResponse
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 404 {object} models.ProblemDetails
+// @Failure 500 {object} models.ProblemDetails
+// @Security BearerAuth
+// @Router /devices/{uuid}/imports [get]
+func (ctrl *ImportController) ImportList(c *gin.Context) {
+	userUUID, exists := c.Get("userUUID")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	deviceUUID, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		c.Error(errInvalidUUID(c.Param("uuid")))
+		return
+	}
+
+	jobs, err := ctrl.importService.ImportList(c.Request.Context(), userUUID.(uuid.UUID), deviceUUID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response := make([]models.ImportJobResponse, 0, len(jobs))
+	for i := range jobs {
+		response = append(response, importJobResponse(&jobs[i]))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ImportDetail godoc
+// @Summary Get import
+// @Description Retrieve the progress of an import job and its row errors
+// @Tags imports
+// @Produce json
+// @Param uuid path string true "Device UUID"
+// @Param import_uuid path string true "Import job UUID"
+// @Success 200 {object} models.ImportJobResponse
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 404 {object} models.ProblemDetails
+// @Security BearerAuth
+// @Router /devices/{uuid}/imports/{import_uuid} [get]
+func (ctrl *ImportController) ImportDetail(c *gin.Context) {
+	userUUID, exists := c.Get("userUUID")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	deviceUUID, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		c.Error(errInvalidUUID(c.Param("uuid")))
+		return
+	}
+
+	importUUID, err := uuid.Parse(c.Param("import_uuid"))
+	if err != nil {
+		c.Error(errInvalidUUID(c.Param("import_uuid")))
+		return
+	}
+
+	job, err := ctrl.importService.ImportDetail(c.Request.Context(), userUUID.(uuid.UUID), deviceUUID, importUUID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, importJobResponse(job))
+}
+
+func importJobResponse(job *models.ImportJob) models.ImportJobResponse {
+	response := models.ImportJobResponse{
+		UUID:           job.UUID,
+		Status:         job.Status,
+		Mapping:        job.Mapping,
+		RowsProcessed:  job.RowsProcessed,
+		ReadingsAdded:  job.ReadingsAdded,
+		DuplicateCount: job.DuplicateCount,
+		ErrorCount:     job.ErrorCount,
+		RowErrors:      job.RowErrors,
+		Error:          job.Error,
+		StartedAt:      job.StartedAt,
+		CompletedAt:    job.CompletedAt,
+		CreatedAt:      job.CreatedAt,
+	}
+	if job.Device != nil {
+		response.DeviceUUID = job.Device.UUID
+	}
+	if response.RowErrors == nil {
+		response.RowErrors = []models.ImportRowError{}
+	}
+	return response
+}
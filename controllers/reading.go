@@ -0,0 +1,210 @@
+package controllers
+
+import (
+	"home-monitor-backend/models"
+	"home-monitor-backend/services"
+	"home-monitor-backend/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var readingLabels = []string{"home", "room", "device", "device_uuid", "metric", "unit"}
+
+type ReadingController struct {
+	readingService services.ReadingService
+}
+
+func NewReadingController(readingService services.ReadingService) *ReadingController {
+	return &ReadingController{readingService: readingService}
+}
+
+// ReadingIngest godoc
+// @Summary Ingest readings
+// @Description Device endpoint storing a batch of readings. Readings of typed devices are validated against the metric schema; invalid ones are reported by index and out-of-range values are rejected or flagged depending on the metric.
+// @Tags device
+// @Accept json
+// @Produce json
+// @Param X-Device-Token header string true "Device token"
+// @Param request body models.ReadingIngestRequest true "Reading batch"
+// @Success 200 {object} models.ReadingIngestResponse
+// @Failure 400 {object} models.ReadingIngestResponse
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 429 {object} models.ProblemDetails
+// @Failure 500 {object} models.ProblemDetails
+// @Router /device/readings [post]
+func (ctrl *ReadingController) ReadingIngest(c *gin.Context) {
+	device, exists := c.Get("device")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	var input models.ReadingIngestRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(utils.ValidationError(err))
+		return
+	}
+
+	response, err := ctrl.readingService.ReadingIngest(c.Request.Context(), input, device.(*models.Device))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// A batch of which nothing was stored is a client error, but the body still tells which
+	// readings were rejected and why.
+	if response.Accepted == 0 {
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ReadingList godoc
+// @Summary List device readings
+// @Description Retrieve readings of a device, newest first
+// @Tags readings
+// @Produce json
+// @Param uuid path string true "Device UUID"
+// @Param metric query string false "Metric name"
+// @Param from query string false "Start of the time range (RFC 3339, inclusive)"
+// @Param to query string false "End of the time range (RFC 3339, exclusive)"
+// @Param limit query int false "Maximum number of readings (default 1000)"
+// @Param units query string false "Convert values and times to the user's preferred units and timezone" Enums(preferred)
+// @Success 200 {array} models.ReadingResponse
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 404 {object} models.ProblemDetails
+// @Failure 429 {object} models.ProblemDetails
+// @Failure 500 {object} models.ProblemDetails
+// @Security BearerAuth
+// @Router /devices/{uuid}/readings [get]
+func (ctrl *ReadingController) ReadingList(c *gin.Context) {
+	userUUID, exists := c.Get("userUUID")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	deviceUUID, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		c.Error(errInvalidUUID(c.Param("uuid")))
+		return
+	}
+
+	var query models.ReadingQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(utils.ValidationError(err))
+		return
+	}
+
+	readings, err := ctrl.readingService.ReadingList(c.Request.Context(), userUUID.(uuid.UUID), deviceUUID, query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, readings)
+}
+
+// ReadingAggregate godoc
+// @Summary Aggregate device readings
+// @Description Bucket the readings of one metric into min/max/avg/count/last. The coarsest rollup tier (1m, 1h, 1d) that divides the bucket size is used; smaller buckets are computed from raw readings.
+// @Tags readings
+// @Produce json
+// @Param uuid path string true "Device UUID"
+// @Param metric query string true "Metric name"
+// @Param from query string true "Start of the time range (RFC 3339, inclusive)"
+// @Param to query string true "End of the time range (RFC 3339, exclusive)"
+// @Param bucket query string true "Bucket size, e.g. 30s, 15m, 6h or 7d"
+// @Param units query string false "Convert values and times to the user's preferred units and timezone" Enums(preferred)
+// @Success 200 {object} models.ReadingAggregateResponse
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 404 {object} models.ProblemDetails
+// @Failure 429 {object} models.ProblemDetails
+// @Failure 500 {object} models.ProblemDetails
+// @Security BearerAuth
+// @Router /devices/{uuid}/readings/aggregate [get]
+func (ctrl *ReadingController) ReadingAggregate(c *gin.Context) {
+	userUUID, exists := c.Get("userUUID")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	deviceUUID, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		c.Error(errInvalidUUID(c.Param("uuid")))
+		return
+	}
+
+	var query models.ReadingAggregateQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(utils.ValidationError(err))
+		return
+	}
+
+	response, err := ctrl.readingService.ReadingAggregate(c.Request.Context(), userUUID.(uuid.UUID), deviceUUID, query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ReadingPrometheus godoc
+// @Summary Latest readings for Prometheus
+// @Description Render the latest value of every metric of the devices visible to the API key in the Prometheus exposition format, labelled with home, room, device and unit. Authenticate with the API key as a bearer token.
+// @Tags readings
+// @Produce plain
+// @Param Authorization header string true "Bearer API key"
+// @Success 200 {string} string
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 429 {object} models.ProblemDetails
+// @Failure 500 {object} models.ProblemDetails
+// @Router /metrics/readings [get]
+func (ctrl *ReadingController) ReadingPrometheus(c *gin.Context) {
+	apiKey, exists := c.Get("apiKey")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	readings, err := ctrl.readingService.ReadingLatest(c.Request.Context(), apiKey.(*models.APIKey))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	value := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "home_monitor_sensor_value",
+		Help: "Latest reading of a device metric.",
+	}, readingLabels)
+	recordedAt := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "home_monitor_sensor_timestamp_seconds",
+		Help: "Time the latest reading of a device metric was recorded.",
+	}, readingLabels)
+
+	for _, reading := range readings {
+		labels := prometheus.Labels{
+			"home":        reading.Home,
+			"room":        reading.Room,
+			"device":      reading.DeviceName,
+			"device_uuid": reading.DeviceUUID.String(),
+			"metric":      reading.Metric,
+			"unit":        reading.Unit,
+		}
+		value.With(labels).Set(reading.Value)
+		recordedAt.With(labels).Set(float64(reading.RecordedAt.UnixMilli()) / 1000)
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(value, recordedAt)
+	promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(c.Writer, c.Request)
+}
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"home-monitor-backend/models"
+	"home-monitor-backend/services"
+	"home-monitor-backend/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type RetentionController struct {
+	retentionService services.RetentionService
+}
+
+func NewRetentionController(retentionService services.RetentionService) *RetentionController {
+	return &RetentionController{retentionService: retentionService}
+}
+
+// RetentionList godoc
+// @Summary List retention policies
+// @Description List the default retention policy and the per home and per device type overrides. A home policy takes precedence over a device type policy. Days of 0 keep a tier forever.
+// @Tags retention
+// @Produce json
+// @Success 200 {array} models.RetentionPolicyResponse
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 403 {object} models.ProblemDetails
+// @Failure 500 {object} models.ProblemDetails
+// @Security BearerAuth
+// @Router /retention-policies [get]
+func (ctrl *RetentionController) RetentionList(c *gin.Context) {
+	userUUID, exists := c.Get("userUUID")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	policies, err := ctrl.retentionService.RetentionList(c.Request.Context(), userUUID.(uuid.UUID))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response := make([]models.RetentionPolicyResponse, 0, len(policies))
+	for i := range policies {
+		response = append(response, retentionPolicyResponse(&policies[i]))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// RetentionSave godoc
+// @Summary Save retention policy
+// @Description Create or replace the retention policy of a device type or a home, or the default policy when device_type_uuid and home are omitted
+// @Tags retention
+// @Accept json
+// @Produce json
+// @Param request body models.RetentionPolicyRequest true "Retention policy request"
+// @Success 200 {object} models.RetentionPolicyResponse
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 403 {object} models.ProblemDetails
+// @Failure 404 {object} models.ProblemDetails
+// @Failure 500 {object} models.ProblemDetails
+// @Security BearerAuth
+// @Router /retention-policies [put]
+func (ctrl *RetentionController) RetentionSave(c *gin.Context) {
+	userUUID, exists := c.Get("userUUID")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	var input models.RetentionPolicyRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(utils.ValidationError(err))
+		return
+	}
+
+	policy, err := ctrl.retentionService.RetentionSave(c.Request.Context(), input, userUUID.(uuid.UUID))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, retentionPolicyResponse(policy))
+}
+
+func retentionPolicyResponse(policy *models.RetentionPolicy) models.RetentionPolicyResponse {
+	response := models.RetentionPolicyResponse{
+		Home:       policy.Home,
+		RawDays:    policy.RawDays,
+		MinuteDays: policy.MinuteDays,
+		HourDays:   policy.HourDays,
+		DayDays:    policy.DayDays,
+		UpdatedAt:  policy.UpdatedAt,
+	}
+	if policy.DeviceType != nil {
+		response.DeviceTypeUUID = &policy.DeviceType.UUID
+	}
+	return response
+}
@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"home-monitor-backend/models"
+	"home-monitor-backend/services"
+	"home-monitor-backend/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SetupController struct {
+	setupService services.SetupService
+}
+
+func NewSetupController(setupService services.SetupService) *SetupController {
+	return &SetupController{setupService: setupService}
+}
+
+// SetupStatus godoc
+// @Summary First-run setup status
+// @Description Report whether the installation still needs its first administrator.
+// @Tags setup
+// @Produce json
+// @Success 200 {object} models.SetupStatusResponse
+// @Failure 500 {object} models.ProblemDetails
+// @Router /setup [get]
+func (ctrl *SetupController) SetupStatus(c *gin.Context) {
+	required, err := ctrl.setupService.SetupStatus(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SetupStatusResponse{Required: required})
+}
+
+// SetupComplete godoc
+// @Summary Create the first administrator
+// @Description Create the first administrator with the one-time setup token printed to the server log, and log in as them. Only available while no administrator exists.
+// @Tags setup
+// @Accept json
+// @Produce json
+// @Param request body models.SetupRequest true "Setup request"
+// @Success 201 {object} models.UserLoginResponse
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 409 {object} models.ProblemDetails
+// @Failure 429 {object} models.ProblemDetails
+// @Failure 500 {object} models.ProblemDetails
+// @Router /setup [post]
+func (ctrl *SetupController) SetupComplete(c *gin.Context) {
+	var input models.SetupRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(utils.ValidationError(err))
+		return
+	}
+
+	user, token, err := ctrl.setupService.SetupComplete(c.Request.Context(), input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.UserLoginResponse{
+		UUID:     user.UUID,
+		Username: user.Username,
+		Token:    "Bearer " + token,
+	})
+}
@@ -0,0 +1,238 @@
+package controllers
+
+import (
+	"home-monitor-backend/events"
+	"home-monitor-backend/models"
+	"home-monitor-backend/services"
+	"home-monitor-backend/utils"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ShadowController struct {
+	shadowService services.ShadowService
+}
+
+func NewShadowController(shadowService services.ShadowService) *ShadowController {
+	return &ShadowController{shadowService: shadowService}
+}
+
+// ShadowGet godoc
+// @Summary Get device shadow
+// @Description Retrieve the desired and reported state of a device together with the delta still to converge
+// @Tags shadow
+// @Produce json
+// @Param uuid path string true "Device UUID"
+// @Success 200 {object} models.ShadowResponse
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 404 {object} models.ProblemDetails
+// @Failure 500 {object} models.ProblemDetails
+// @Security BearerAuth
+// @Router /devices/{uuid}/shadow [get]
+func (ctrl *ShadowController) ShadowGet(c *gin.Context) {
+	userUUID, exists := c.Get("userUUID")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	deviceUUID, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		c.Error(errInvalidUUID(c.Param("uuid")))
+		return
+	}
+
+	shadow, err := ctrl.shadowService.ShadowGet(c.Request.Context(), userUUID.(uuid.UUID), deviceUUID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, shadow)
+}
+
+// ShadowUpdate godoc
+// @Summary Update desired state
+// @Description Apply a JSON merge patch to the desired state of a device. Pass the current version to reject stale updates.
+// @Tags shadow
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Device UUID"
+// @Param request body models.ShadowDesiredRequest true "Desired state merge patch"
+// @Success 200 {object} models.ShadowResponse
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 404 {object} models.ProblemDetails
+// @Failure 409 {object} models.ProblemDetails
+// @Failure 500 {object} models.ProblemDetails
+// @Security BearerAuth
+// @Router /devices/{uuid}/shadow [patch]
+func (ctrl *ShadowController) ShadowUpdate(c *gin.Context) {
+	userUUID, exists := c.Get("userUUID")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	deviceUUID, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		c.Error(errInvalidUUID(c.Param("uuid")))
+		return
+	}
+
+	var input models.ShadowDesiredRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(utils.ValidationError(err))
+		return
+	}
+
+	shadow, err := ctrl.shadowService.ShadowUpdateDesired(c.Request.Context(), input, userUUID.(uuid.UUID), deviceUUID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, shadow)
+}
+
+// ShadowEvents godoc
+// @Summary Stream shadow changes
+// @Description Server-sent events stream emitting a "delta" event with the full shadow whenever its delta changes
+// @Tags shadow
+// @Produce text/event-stream
+// @Param uuid path string true "Device UUID"
+// @Success 200 {object} models.ShadowResponse
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 404 {object} models.ProblemDetails
+// @Security BearerAuth
+// @Router /devices/{uuid}/shadow/events [get]
+func (ctrl *ShadowController) ShadowEvents(c *gin.Context) {
+	userUUID, exists := c.Get("userUUID")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	deviceUUID, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		c.Error(errInvalidUUID(c.Param("uuid")))
+		return
+	}
+
+	ch, unsubscribe, err := ctrl.shadowService.ShadowSubscribe(c.Request.Context(), userUUID.(uuid.UUID), deviceUUID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer unsubscribe()
+
+	streamEvents(c, ch)
+}
+
+// ShadowDeviceGet godoc
+// @Summary Get own shadow
+// @Description Device endpoint returning its shadow, typically called after waking up to converge on the delta
+// @Tags device
+// @Produce json
+// @Param X-Device-Token header string true "Device token"
+// @Success 200 {object} models.ShadowResponse
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 500 {object} models.ProblemDetails
+// @Router /device/shadow [get]
+func (ctrl *ShadowController) ShadowDeviceGet(c *gin.Context) {
+	device, exists := c.Get("device")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	shadow, err := ctrl.shadowService.ShadowDeviceGet(c.Request.Context(), device.(*models.Device))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, shadow)
+}
+
+// ShadowDeviceUpdate godoc
+// @Summary Report state
+// @Description Device endpoint applying a JSON merge patch to its reported state
+// @Tags device
+// @Accept json
+// @Produce json
+// @Param X-Device-Token header string true "Device token"
+// @Param request body models.ShadowReportedRequest true "Reported state merge patch"
+// @Success 200 {object} models.ShadowResponse
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 409 {object} models.ProblemDetails
+// @Failure 500 {object} models.ProblemDetails
+// @Router /device/shadow [patch]
+func (ctrl *ShadowController) ShadowDeviceUpdate(c *gin.Context) {
+	device, exists := c.Get("device")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	var input models.ShadowReportedRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(utils.ValidationError(err))
+		return
+	}
+
+	shadow, err := ctrl.shadowService.ShadowUpdateReported(c.Request.Context(), input, device.(*models.Device))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, shadow)
+}
+
+// ShadowDeviceEvents godoc
+// @Summary Stream own shadow changes
+// @Description Device endpoint streaming a "delta" event whenever its shadow delta changes
+// @Tags device
+// @Produce text/event-stream
+// @Param X-Device-Token header string true "Device token"
+// @Success 200 {object} models.ShadowResponse
+// @Failure 401 {object} models.ProblemDetails
+// @Router /device/shadow/events [get]
+func (ctrl *ShadowController) ShadowDeviceEvents(c *gin.Context) {
+	device, exists := c.Get("device")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	ch, unsubscribe := ctrl.shadowService.ShadowDeviceSubscribe(c.Request.Context(), device.(*models.Device))
+	defer unsubscribe()
+
+	streamEvents(c, ch)
+}
+
+// streamEvents writes events to the client as server-sent events until the client goes away.
+func streamEvents(c *gin.Context, ch <-chan events.Event) {
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-ch:
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.Type, event.Data)
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
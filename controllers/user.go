@@ -26,34 +26,33 @@ func NewUserController(userService services.UserService) *UserController {
 // @Produce json
 // @Param request body models.UserRegisterRequest true "User register request"
 // @Success 201 {object} models.UserRegisterResponse
-// @Failure 400 {object} models.ErrorResponse
-// @Failure 401 {object} models.ErrorResponse
-// @Failure 403 {object} models.ErrorResponse
-// @Failure 409 {object} models.ErrorResponse
-// @Failure 500 {object} models.ErrorResponse
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 403 {object} models.ProblemDetails
+// @Failure 409 {object} models.ProblemDetails
+// @Failure 500 {object} models.ProblemDetails
 // @Security BearerAuth
 // @Router /user/register [post]
 func (ctrl *UserController) UserRegister(c *gin.Context) {
 	userUUID, exists := c.Get("userUUID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		c.Error(errUnauthorized)
 		return
 	}
 
 	var input models.UserRegisterRequest
 	if err := c.ShouldBindJSON(&input); err != nil {
-		errors := utils.ValidationError(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": errors})
+		c.Error(utils.ValidationError(err))
 		return
 	}
 
-	user, statusCode, err := ctrl.userService.UserRegister(input, userUUID.(uuid.UUID))
+	user, err := ctrl.userService.UserRegister(c.Request.Context(), input, userUUID.(uuid.UUID))
 	if err != nil {
-		c.JSON(statusCode, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(statusCode, models.UserRegisterResponse{
+	c.JSON(http.StatusCreated, models.UserRegisterResponse{
 		UUID:      user.UUID,
 		Username:  user.Username,
 		CreatedAt: user.CreatedAt,
@@ -69,25 +68,25 @@ func (ctrl *UserController) UserRegister(c *gin.Context) {
 // @Produce json
 // @Param request body models.UserLoginRequest true "User login request"
 // @Success 200 {object} models.UserLoginResponse
-// @Failure 400 {object} models.ErrorResponse
-// @Failure 401 {object} models.ErrorResponse
-// @Failure 500 {object} models.ErrorResponse
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 429 {object} models.ProblemDetails
+// @Failure 500 {object} models.ProblemDetails
 // @Router /user/login [post]
 func (ctrl *UserController) UserLogin(c *gin.Context) {
 	var input models.UserLoginRequest
 	if err := c.ShouldBindJSON(&input); err != nil {
-		errors := utils.ValidationError(err)
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: errors})
+		c.Error(utils.ValidationError(err))
 		return
 	}
 
-	user, token, statusCode, err := ctrl.userService.UserLogin(input)
+	user, token, err := ctrl.userService.UserLogin(c.Request.Context(), input)
 	if err != nil {
-		c.JSON(statusCode, models.ErrorResponse{Error: err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(statusCode, models.UserLoginResponse{
+	c.JSON(http.StatusOK, models.UserLoginResponse{
 		UUID:     user.UUID,
 		Username: user.Username,
 		Token:    "Bearer " + token,
@@ -100,28 +99,29 @@ func (ctrl *UserController) UserLogin(c *gin.Context) {
 // @Tags users
 // @Produce json
 // @Success 200 {object} models.UserProfileResponse
-// @Failure 401 {object} models.ErrorResponse
-// @Failure 404 {object} models.ErrorResponse
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 404 {object} models.ProblemDetails
 // @Security BearerAuth
 // @Router /user/profile [get]
 func (ctrl *UserController) UserProfile(c *gin.Context) {
 	userUUID, exists := c.Get("userUUID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		c.Error(errUnauthorized)
 		return
 	}
 
-	user, statusCode, err := ctrl.userService.UserProfile(userUUID.(uuid.UUID))
+	user, err := ctrl.userService.UserProfile(c.Request.Context(), userUUID.(uuid.UUID))
 	if err != nil {
-		c.JSON(statusCode, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(statusCode, models.UserProfileResponse{
-		UUID:      user.UUID,
-		Username:  user.Username,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+	c.JSON(http.StatusOK, models.UserProfileResponse{
+		UUID:        user.UUID,
+		Username:    user.Username,
+		Preferences: user.PreferencesResponse(),
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
 	})
 }
 
@@ -133,37 +133,72 @@ func (ctrl *UserController) UserProfile(c *gin.Context) {
 // @Produce json
 // @Param request body models.UserUpdateRequest true "User update request"
 // @Success 200 {object} models.UserRegisterResponse
-// @Failure 400 {object} models.ErrorResponse
-// @Failure 401 {object} models.ErrorResponse
-// @Failure 403 {object} models.ErrorResponse
-// @Failure 404 {object} models.ErrorResponse
-// @Failure 500 {object} models.ErrorResponse
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 403 {object} models.ProblemDetails
+// @Failure 404 {object} models.ProblemDetails
+// @Failure 500 {object} models.ProblemDetails
 // @Security BearerAuth
 // @Router /user/update [put]
 func (ctrl *UserController) UserUpdate(c *gin.Context) {
 	userUUID, exists := c.Get("userUUID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		c.Error(errUnauthorized)
 		return
 	}
 
 	var input models.UserUpdateRequest
 	if err := c.ShouldBindJSON(&input); err != nil {
-		errors := utils.ValidationError(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": errors})
+		c.Error(utils.ValidationError(err))
 		return
 	}
 
-	user, statusCode, err := ctrl.userService.UserUpdate(userUUID.(uuid.UUID), &input)
+	user, err := ctrl.userService.UserUpdate(c.Request.Context(), userUUID.(uuid.UUID), &input)
 	if err != nil {
-		c.JSON(statusCode, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(statusCode, models.UserRegisterResponse{
+	c.JSON(http.StatusOK, models.UserRegisterResponse{
 		UUID:      user.UUID,
 		Username:  user.Username,
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
 	})
 }
+
+// UserPreferences godoc
+// @Summary Update display preferences
+// @Description Set the preferred unit per measurement type (temperature, pressure, energy, volume, speed) and the timezone of the authenticated user. An empty unit removes the preference.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body models.UserPreferencesRequest true "User preferences request"
+// @Success 200 {object} models.UserPreferencesResponse
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 401 {object} models.ProblemDetails
+// @Failure 404 {object} models.ProblemDetails
+// @Failure 500 {object} models.ProblemDetails
+// @Security BearerAuth
+// @Router /user/preferences [put]
+func (ctrl *UserController) UserPreferences(c *gin.Context) {
+	userUUID, exists := c.Get("userUUID")
+	if !exists {
+		c.Error(errUnauthorized)
+		return
+	}
+
+	var input models.UserPreferencesRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(utils.ValidationError(err))
+		return
+	}
+
+	user, err := ctrl.userService.UserPreferencesUpdate(c.Request.Context(), userUUID.(uuid.UUID), input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user.PreferencesResponse())
+}
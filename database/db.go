@@ -2,31 +2,61 @@ package database
 
 import (
 	"fmt"
-	"log"
-	"os"
+	"home-monitor-backend/config"
+	"home-monitor-backend/logging"
+	"log/slog"
+	"net"
+	"net/url"
 
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
-var DB *gorm.DB
-
-func ConnectDB() {
-	user := os.Getenv("DB_USER")
-	pass := os.Getenv("DB_PASSWORD")
-	host := os.Getenv("DB_HOST")
-	port := os.Getenv("DB_PORT")
-	name := os.Getenv("DB_NAME")
-
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		user, pass, host, port, name)
+// ConnectDB opens the configured database. The handle is passed on to the repositories; there is
+// no package-level connection, so several instances can run side by side, e.g. in tests.
+func ConnectDB(cfg config.Database) (*gorm.DB, error) {
+	if err := createSQLiteDir(cfg); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
 
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(dialector(cfg), &gorm.Config{Logger: logging.NewGormLogger()})
 	if err != nil {
-		log.Fatal("Failed to connect to database: ", err)
+		return nil, err
+	}
+
+	if err := db.Use(&QueryTimeout{Timeout: cfg.QueryTimeout}); err != nil {
+		return nil, fmt.Errorf("failed to register query timeout: %w", err)
 	}
 
-	DB = db
+	slog.Info("Database connected", "driver", cfg.Driver)
+	return db, nil
+}
 
-	fmt.Println("Database connected")
+func dialector(cfg config.Database) gorm.Dialector {
+	switch cfg.Driver {
+	case config.DriverPostgres:
+		return postgres.Open(postgresURL(cfg, "postgres"))
+	case config.DriverSQLite:
+		// Foreign keys are off by default in SQLite. Transactions take the write lock up front so
+		// that concurrent writers wait for the busy timeout instead of failing to upgrade.
+		dsn := cfg.Path + "?_foreign_keys=1&_busy_timeout=5000&_journal_mode=WAL&_txlock=immediate&_loc=auto"
+		return sqlite.Dialector{DriverName: sqliteDriverName, DSN: dsn}
+	default:
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+			cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.Name)
+		return mysql.Open(dsn)
+	}
+}
+
+func postgresURL(cfg config.Database, scheme string) string {
+	u := url.URL{
+		Scheme:   scheme,
+		User:     url.UserPassword(cfg.User, cfg.Password),
+		Host:     net.JoinHostPort(cfg.Host, cfg.Port),
+		Path:     cfg.Name,
+		RawQuery: url.Values{"sslmode": {cfg.SSLMode}}.Encode(),
+	}
+	return u.String()
 }
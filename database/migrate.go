@@ -1,36 +1,120 @@
 package database
 
 import (
+	"context"
+	"embed"
 	"fmt"
-	"log"
-	"os"
+	"home-monitor-backend/config"
+	"home-monitor-backend/logging"
+	"io/fs"
+	"log/slog"
+	"path"
+	"strconv"
+	"strings"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/mysql"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	_ "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"gorm.io/gorm"
 )
 
-func Migrations() {
-	user := os.Getenv("DB_USER")
-	pass := os.Getenv("DB_PASSWORD")
-	host := os.Getenv("DB_HOST")
-	port := os.Getenv("DB_PORT")
-	name := os.Getenv("DB_NAME")
+// migrations holds one directory of migrations per driver, named after it. They are compiled in,
+// so the binary and tests do not depend on the working directory.
+//
+//go:embed migrations
+var migrations embed.FS
 
-	dsn := fmt.Sprintf("mysql://%s:%s@tcp(%s:%s)/%s?multiStatements=true",
-		user, pass, host, port, name)
-
-	m, err := migrate.New(
-		"file://database/migrations",
-		dsn,
-	)
+func Migrations(cfg config.Database) {
+	m, err := Migrator(cfg)
 	if err != nil {
-		log.Fatal("Migration setup failed:", err)
+		logging.Fatal("Migration setup failed", "error", err)
 	}
+	defer m.Close()
 
 	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-		log.Fatal("Migration failed:", err)
+		logging.Fatal("Migration failed", "error", err)
+	}
+
+	slog.Info("Database migrated")
+}
+
+// Migrator opens the migrations of the configured driver, for running them step by step.
+func Migrator(cfg config.Database) (*migrate.Migrate, error) {
+	if err := createSQLiteDir(cfg); err != nil {
+		return nil, err
+	}
+	source, err := iofs.New(migrations, path.Join("migrations", cfg.Driver))
+	if err != nil {
+		return nil, err
+	}
+	return migrate.NewWithSourceInstance("iofs", source, migrationURL(cfg))
+}
+
+// migrationURL opens a connection of its own for the migrations. MySQL needs multiStatements for
+// files with several statements, which is better left off on the connection serving requests.
+func migrationURL(cfg config.Database) string {
+	switch cfg.Driver {
+	case config.DriverPostgres:
+		return postgresURL(cfg, "pgx5")
+	case config.DriverSQLite:
+		return "sqlite3://" + cfg.Path + "?_busy_timeout=5000"
+	default:
+		return fmt.Sprintf("mysql://%s:%s@tcp(%s:%s)/%s?multiStatements=true",
+			cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.Name)
+	}
+}
+
+// MigrationStatus is the schema version recorded in the database next to the newest migration
+// shipped with the binary.
+type MigrationStatus struct {
+	Version uint `json:"version"`
+	Latest  uint `json:"latest"`
+	Dirty   bool `json:"dirty"`
+}
+
+// CheckMigrations reports an error when the schema is behind the migrations or a migration
+// failed halfway.
+func CheckMigrations(ctx context.Context, db *gorm.DB) (*MigrationStatus, error) {
+	latest, err := LatestMigration(db.Dialector.Name())
+	if err != nil {
+		return nil, err
 	}
 
-	fmt.Println("Database migrated")
+	var status MigrationStatus
+	if err := db.WithContext(ctx).Raw("SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&status).Error; err != nil {
+		return nil, err
+	}
+	status.Latest = latest
+
+	if status.Dirty {
+		return &status, fmt.Errorf("migration %d is dirty", status.Version)
+	}
+	if status.Version < status.Latest {
+		return &status, fmt.Errorf("schema is at version %d, expected %d", status.Version, status.Latest)
+	}
+	return &status, nil
+}
+
+// LatestMigration returns the version of the newest migration shipped for the driver.
+func LatestMigration(driver string) (uint, error) {
+	entries, err := fs.ReadDir(migrations, path.Join("migrations", driver))
+	if err != nil {
+		return 0, err
+	}
+
+	var latest uint
+	for _, entry := range entries {
+		prefix, _, found := strings.Cut(entry.Name(), "_")
+		if !found || !strings.HasSuffix(entry.Name(), ".up.sql") {
+			continue
+		}
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			continue
+		}
+		latest = max(latest, uint(version))
+	}
+	return latest, nil
 }
@@ -0,0 +1,20 @@
+CREATE TABLE commands (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    uuid CHAR(36) NOT NULL UNIQUE,
+    device_id BIGINT UNSIGNED NOT NULL,
+    user_id BIGINT UNSIGNED NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    params JSON NULL,
+    status VARCHAR(16) NOT NULL COMMENT 'pending,sent,acked,failed,expired',
+    result JSON NULL,
+    error VARCHAR(1000) NOT NULL DEFAULT '',
+    expires_at TIMESTAMP NOT NULL,
+    sent_at TIMESTAMP NULL DEFAULT NULL,
+    completed_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_commands_device_status (device_id, status),
+    INDEX idx_commands_status_expires (status, expires_at),
+    CONSTRAINT fk_commands_device FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE,
+    CONSTRAINT fk_commands_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,10 @@
+CREATE TABLE device_shadows (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    device_id BIGINT UNSIGNED NOT NULL UNIQUE,
+    desired JSON NULL,
+    reported JSON NULL,
+    version INT UNSIGNED NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    CONSTRAINT fk_device_shadows_device FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE
+);
@@ -0,0 +1,5 @@
+ALTER TABLE devices
+    DROP FOREIGN KEY fk_devices_device_type,
+    DROP COLUMN device_type_id;
+
+DROP TABLE IF EXISTS device_types;
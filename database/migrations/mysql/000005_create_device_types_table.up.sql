@@ -0,0 +1,13 @@
+CREATE TABLE device_types (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    uuid CHAR(36) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description VARCHAR(1000) NOT NULL DEFAULT '',
+    capabilities JSON NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+ALTER TABLE devices
+    ADD COLUMN device_type_id BIGINT UNSIGNED NULL AFTER user_id,
+    ADD CONSTRAINT fk_devices_device_type FOREIGN KEY (device_type_id) REFERENCES device_types(id) ON DELETE SET NULL;
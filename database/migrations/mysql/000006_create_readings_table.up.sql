@@ -0,0 +1,12 @@
+CREATE TABLE readings (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    device_id BIGINT UNSIGNED NOT NULL,
+    metric VARCHAR(100) NOT NULL,
+    value DOUBLE NOT NULL,
+    flagged BOOLEAN NOT NULL DEFAULT FALSE,
+    recorded_at TIMESTAMP(3) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_readings_device_metric_recorded (device_id, metric, recorded_at),
+    INDEX idx_readings_device_recorded (device_id, recorded_at),
+    CONSTRAINT fk_readings_device FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE
+);
@@ -0,0 +1,3 @@
+ALTER TABLE users
+    ADD COLUMN preferred_units JSON NULL AFTER role,
+    ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC' AFTER preferred_units;
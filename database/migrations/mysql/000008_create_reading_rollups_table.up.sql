@@ -0,0 +1,22 @@
+CREATE TABLE reading_rollups (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    device_id BIGINT UNSIGNED NOT NULL,
+    metric VARCHAR(100) NOT NULL,
+    tier VARCHAR(4) NOT NULL COMMENT '1m,1h,1d',
+    bucket_start TIMESTAMP NOT NULL,
+    min DOUBLE NOT NULL,
+    max DOUBLE NOT NULL,
+    sum DOUBLE NOT NULL,
+    count BIGINT NOT NULL,
+    last DOUBLE NOT NULL,
+    last_at TIMESTAMP(3) NOT NULL,
+    UNIQUE INDEX idx_reading_rollups_bucket (device_id, metric, tier, bucket_start),
+    INDEX idx_reading_rollups_tier_bucket (tier, bucket_start),
+    CONSTRAINT fk_reading_rollups_device FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE
+);
+
+CREATE TABLE rollup_states (
+    tier VARCHAR(4) NOT NULL PRIMARY KEY,
+    rolled_up_to TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
@@ -0,0 +1,18 @@
+CREATE TABLE export_jobs (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    uuid CHAR(36) NOT NULL UNIQUE,
+    user_id BIGINT UNSIGNED NOT NULL,
+    request JSON NOT NULL,
+    status VARCHAR(16) NOT NULL COMMENT 'pending,running,completed,failed',
+    row_count BIGINT NOT NULL DEFAULT 0,
+    file_path VARCHAR(255) NOT NULL DEFAULT '',
+    error VARCHAR(1000) NOT NULL DEFAULT '',
+    started_at TIMESTAMP NULL DEFAULT NULL,
+    completed_at TIMESTAMP NULL DEFAULT NULL,
+    expires_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_export_jobs_user (user_id),
+    INDEX idx_export_jobs_status (status),
+    CONSTRAINT fk_export_jobs_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
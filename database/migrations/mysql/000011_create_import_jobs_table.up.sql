@@ -0,0 +1,26 @@
+CREATE TABLE import_jobs (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    uuid CHAR(36) NOT NULL UNIQUE,
+    user_id BIGINT UNSIGNED NOT NULL,
+    device_id BIGINT UNSIGNED NOT NULL,
+    mapping JSON NOT NULL,
+    status VARCHAR(16) NOT NULL COMMENT 'pending,running,completed,failed',
+    file_path VARCHAR(255) NOT NULL,
+    byte_offset BIGINT NOT NULL DEFAULT 0 COMMENT 'byte offset of the next unprocessed row',
+    rows_processed BIGINT NOT NULL DEFAULT 0,
+    readings_added BIGINT NOT NULL DEFAULT 0,
+    duplicate_count BIGINT NOT NULL DEFAULT 0,
+    error_count BIGINT NOT NULL DEFAULT 0,
+    row_errors JSON NULL,
+    first_at TIMESTAMP(3) NULL DEFAULT NULL,
+    last_at TIMESTAMP(3) NULL DEFAULT NULL,
+    error VARCHAR(1000) NOT NULL DEFAULT '',
+    started_at TIMESTAMP NULL DEFAULT NULL,
+    completed_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_import_jobs_device (device_id),
+    INDEX idx_import_jobs_status (status),
+    CONSTRAINT fk_import_jobs_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_import_jobs_device FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE
+);
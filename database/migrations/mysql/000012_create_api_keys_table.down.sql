@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS api_keys;
+
+ALTER TABLE devices
+    DROP INDEX idx_devices_home,
+    DROP COLUMN room,
+    DROP COLUMN home;
@@ -0,0 +1,18 @@
+ALTER TABLE devices
+    ADD COLUMN home VARCHAR(100) NOT NULL DEFAULT '' AFTER name,
+    ADD COLUMN room VARCHAR(100) NOT NULL DEFAULT '' AFTER home,
+    ADD INDEX idx_devices_home (home);
+
+CREATE TABLE api_keys (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    uuid CHAR(36) NOT NULL UNIQUE,
+    user_id BIGINT UNSIGNED NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    home VARCHAR(100) NOT NULL DEFAULT '',
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    last_used_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_api_keys_user (user_id),
+    CONSTRAINT fk_api_keys_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,8 @@
+CREATE TABLE rate_limit_buckets (
+    bucket_key VARCHAR(191) NOT NULL PRIMARY KEY,
+    tokens DOUBLE NOT NULL,
+    refilled_at TIMESTAMP(3) NOT NULL,
+    expires_at TIMESTAMP(3) NOT NULL,
+    version BIGINT UNSIGNED NOT NULL,
+    INDEX idx_rate_limit_buckets_expires_at (expires_at)
+);
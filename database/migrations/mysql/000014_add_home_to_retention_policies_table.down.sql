@@ -0,0 +1,5 @@
+DELETE FROM retention_policies WHERE home IS NOT NULL;
+
+ALTER TABLE retention_policies
+    DROP INDEX idx_retention_policies_home,
+    DROP COLUMN home;
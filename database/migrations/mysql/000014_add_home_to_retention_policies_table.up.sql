@@ -0,0 +1,3 @@
+ALTER TABLE retention_policies
+    ADD COLUMN home VARCHAR(100) NULL COMMENT 'NULL=not a home policy' AFTER device_type_id,
+    ADD UNIQUE INDEX idx_retention_policies_home (home);
@@ -0,0 +1,3 @@
+ALTER TABLE import_jobs
+    DROP COLUMN heartbeat_at,
+    DROP COLUMN claimed_by;
@@ -0,0 +1,3 @@
+ALTER TABLE import_jobs
+    ADD COLUMN claimed_by VARCHAR(100) NOT NULL DEFAULT '' COMMENT 'process running the job' AFTER status,
+    ADD COLUMN heartbeat_at TIMESTAMP NULL DEFAULT NULL COMMENT 'last checkpoint of the running process' AFTER claimed_by;
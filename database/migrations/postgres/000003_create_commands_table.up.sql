@@ -0,0 +1,21 @@
+CREATE TABLE commands (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    uuid UUID NOT NULL UNIQUE,
+    device_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    params JSONB NULL,
+    status VARCHAR(16) NOT NULL, -- pending,sent,acked,failed,expired
+    result JSONB NULL,
+    error VARCHAR(1000) NOT NULL DEFAULT '',
+    expires_at TIMESTAMPTZ NOT NULL,
+    sent_at TIMESTAMPTZ NULL DEFAULT NULL,
+    completed_at TIMESTAMPTZ NULL DEFAULT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_commands_device FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE,
+    CONSTRAINT fk_commands_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_commands_device_status ON commands (device_id, status);
+CREATE INDEX idx_commands_status_expires ON commands (status, expires_at);
@@ -0,0 +1,10 @@
+CREATE TABLE device_shadows (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    device_id BIGINT NOT NULL UNIQUE,
+    desired JSONB NULL,
+    reported JSONB NULL,
+    version INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_device_shadows_device FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE
+);
@@ -0,0 +1,13 @@
+CREATE TABLE readings (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    device_id BIGINT NOT NULL,
+    metric VARCHAR(100) NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    flagged BOOLEAN NOT NULL DEFAULT FALSE,
+    recorded_at TIMESTAMPTZ(3) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_readings_device FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_readings_device_metric_recorded ON readings (device_id, metric, recorded_at);
+CREATE INDEX idx_readings_device_recorded ON readings (device_id, recorded_at);
@@ -0,0 +1,3 @@
+ALTER TABLE users
+    ADD COLUMN preferred_units JSONB NULL,
+    ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
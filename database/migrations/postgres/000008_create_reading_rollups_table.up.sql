@@ -0,0 +1,23 @@
+CREATE TABLE reading_rollups (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    device_id BIGINT NOT NULL,
+    metric VARCHAR(100) NOT NULL,
+    tier VARCHAR(4) NOT NULL, -- 1m,1h,1d
+    bucket_start TIMESTAMPTZ NOT NULL,
+    min DOUBLE PRECISION NOT NULL,
+    max DOUBLE PRECISION NOT NULL,
+    sum DOUBLE PRECISION NOT NULL,
+    count BIGINT NOT NULL,
+    last DOUBLE PRECISION NOT NULL,
+    last_at TIMESTAMPTZ(3) NOT NULL,
+    CONSTRAINT fk_reading_rollups_device FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_reading_rollups_bucket ON reading_rollups (device_id, metric, tier, bucket_start);
+CREATE INDEX idx_reading_rollups_tier_bucket ON reading_rollups (tier, bucket_start);
+
+CREATE TABLE rollup_states (
+    tier VARCHAR(4) NOT NULL PRIMARY KEY,
+    rolled_up_to TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
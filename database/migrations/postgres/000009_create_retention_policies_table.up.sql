@@ -0,0 +1,14 @@
+CREATE TABLE retention_policies (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    device_type_id BIGINT NULL UNIQUE, -- NULL=default policy
+    raw_days INT NOT NULL, -- 0=keep forever
+    minute_days INT NOT NULL,
+    hour_days INT NOT NULL,
+    day_days INT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_retention_policies_device_type FOREIGN KEY (device_type_id) REFERENCES device_types(id) ON DELETE CASCADE
+);
+
+INSERT INTO retention_policies (device_type_id, raw_days, minute_days, hour_days, day_days)
+VALUES (NULL, 7, 30, 365, 0);
@@ -0,0 +1,27 @@
+CREATE TABLE import_jobs (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    uuid UUID NOT NULL UNIQUE,
+    user_id BIGINT NOT NULL,
+    device_id BIGINT NOT NULL,
+    mapping JSONB NOT NULL,
+    status VARCHAR(16) NOT NULL, -- pending,running,completed,failed
+    file_path VARCHAR(255) NOT NULL,
+    byte_offset BIGINT NOT NULL DEFAULT 0, -- byte offset of the next unprocessed row
+    rows_processed BIGINT NOT NULL DEFAULT 0,
+    readings_added BIGINT NOT NULL DEFAULT 0,
+    duplicate_count BIGINT NOT NULL DEFAULT 0,
+    error_count BIGINT NOT NULL DEFAULT 0,
+    row_errors JSONB NULL,
+    first_at TIMESTAMPTZ(3) NULL DEFAULT NULL,
+    last_at TIMESTAMPTZ(3) NULL DEFAULT NULL,
+    error VARCHAR(1000) NOT NULL DEFAULT '',
+    started_at TIMESTAMPTZ NULL DEFAULT NULL,
+    completed_at TIMESTAMPTZ NULL DEFAULT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_import_jobs_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_import_jobs_device FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_import_jobs_device ON import_jobs (device_id);
+CREATE INDEX idx_import_jobs_status ON import_jobs (status);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS api_keys;
+
+DROP INDEX IF EXISTS idx_devices_home;
+
+ALTER TABLE devices
+    DROP COLUMN room,
+    DROP COLUMN home;
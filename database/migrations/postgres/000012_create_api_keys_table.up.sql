@@ -0,0 +1,20 @@
+ALTER TABLE devices
+    ADD COLUMN home VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN room VARCHAR(100) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_devices_home ON devices (home);
+
+CREATE TABLE api_keys (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    uuid UUID NOT NULL UNIQUE,
+    user_id BIGINT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    home VARCHAR(100) NOT NULL DEFAULT '',
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    last_used_at TIMESTAMPTZ NULL DEFAULT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_api_keys_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_api_keys_user ON api_keys (user_id);
@@ -0,0 +1,9 @@
+CREATE TABLE rate_limit_buckets (
+    bucket_key VARCHAR(191) NOT NULL PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    refilled_at TIMESTAMPTZ(3) NOT NULL,
+    expires_at TIMESTAMPTZ(3) NOT NULL,
+    version BIGINT NOT NULL
+);
+
+CREATE INDEX idx_rate_limit_buckets_expires_at ON rate_limit_buckets (expires_at);
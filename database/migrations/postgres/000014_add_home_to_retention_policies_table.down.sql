@@ -0,0 +1,4 @@
+DELETE FROM retention_policies WHERE home IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_retention_policies_home;
+ALTER TABLE retention_policies DROP COLUMN home;
@@ -0,0 +1,3 @@
+ALTER TABLE retention_policies ADD COLUMN home VARCHAR(100) NULL; -- NULL=not a home policy
+
+CREATE UNIQUE INDEX idx_retention_policies_home ON retention_policies (home);
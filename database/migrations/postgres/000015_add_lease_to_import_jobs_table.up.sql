@@ -0,0 +1,2 @@
+ALTER TABLE import_jobs ADD COLUMN claimed_by VARCHAR(100) NOT NULL DEFAULT ''; -- process running the job
+ALTER TABLE import_jobs ADD COLUMN heartbeat_at TIMESTAMPTZ NULL DEFAULT NULL; -- last checkpoint of the running process
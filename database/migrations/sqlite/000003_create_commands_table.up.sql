@@ -0,0 +1,21 @@
+CREATE TABLE commands (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    uuid CHAR(36) NOT NULL UNIQUE,
+    device_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    params TEXT NULL,
+    status VARCHAR(16) NOT NULL, -- pending,sent,acked,failed,expired
+    result TEXT NULL,
+    error VARCHAR(1000) NOT NULL DEFAULT '',
+    expires_at TIMESTAMP NOT NULL,
+    sent_at TIMESTAMP NULL DEFAULT NULL,
+    completed_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_commands_device FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE,
+    CONSTRAINT fk_commands_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_commands_device_status ON commands (device_id, status);
+CREATE INDEX idx_commands_status_expires ON commands (status, expires_at);
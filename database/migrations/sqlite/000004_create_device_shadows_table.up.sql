@@ -0,0 +1,10 @@
+CREATE TABLE device_shadows (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    device_id INTEGER NOT NULL UNIQUE,
+    desired TEXT NULL,
+    reported TEXT NULL,
+    version INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_device_shadows_device FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE
+);
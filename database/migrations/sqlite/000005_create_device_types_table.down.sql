@@ -0,0 +1,21 @@
+-- SQLite cannot drop a column that has a foreign key, so devices is rebuilt without it. The
+-- migration connection leaves foreign keys off, which keeps the rows referencing devices.
+CREATE TABLE devices_rebuild (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    uuid CHAR(36) NOT NULL UNIQUE,
+    user_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    last_seen_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_devices_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+INSERT INTO devices_rebuild (id, uuid, user_id, name, token_hash, last_seen_at, created_at, updated_at)
+SELECT id, uuid, user_id, name, token_hash, last_seen_at, created_at, updated_at FROM devices;
+
+DROP TABLE devices;
+ALTER TABLE devices_rebuild RENAME TO devices;
+
+DROP TABLE IF EXISTS device_types;
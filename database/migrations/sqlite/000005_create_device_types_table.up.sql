@@ -0,0 +1,13 @@
+CREATE TABLE device_types (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    uuid CHAR(36) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description VARCHAR(1000) NOT NULL DEFAULT '',
+    capabilities TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE devices
+    ADD COLUMN device_type_id INTEGER NULL
+    CONSTRAINT fk_devices_device_type REFERENCES device_types(id) ON DELETE SET NULL;
@@ -0,0 +1,13 @@
+CREATE TABLE readings (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    device_id INTEGER NOT NULL,
+    metric VARCHAR(100) NOT NULL,
+    value DOUBLE NOT NULL,
+    flagged BOOLEAN NOT NULL DEFAULT FALSE,
+    recorded_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_readings_device FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_readings_device_metric_recorded ON readings (device_id, metric, recorded_at);
+CREATE INDEX idx_readings_device_recorded ON readings (device_id, recorded_at);
@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN preferred_units TEXT NULL;
+ALTER TABLE users ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
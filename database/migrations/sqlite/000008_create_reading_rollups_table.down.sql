@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS rollup_states;
+DROP TABLE IF EXISTS reading_rollups;
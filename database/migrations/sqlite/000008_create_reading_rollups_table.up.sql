@@ -0,0 +1,23 @@
+CREATE TABLE reading_rollups (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    device_id INTEGER NOT NULL,
+    metric VARCHAR(100) NOT NULL,
+    tier VARCHAR(4) NOT NULL, -- 1m,1h,1d
+    bucket_start TIMESTAMP NOT NULL,
+    min DOUBLE NOT NULL,
+    max DOUBLE NOT NULL,
+    sum DOUBLE NOT NULL,
+    count INTEGER NOT NULL,
+    last DOUBLE NOT NULL,
+    last_at TIMESTAMP NOT NULL,
+    CONSTRAINT fk_reading_rollups_device FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_reading_rollups_bucket ON reading_rollups (device_id, metric, tier, bucket_start);
+CREATE INDEX idx_reading_rollups_tier_bucket ON reading_rollups (tier, bucket_start);
+
+CREATE TABLE rollup_states (
+    tier VARCHAR(4) NOT NULL PRIMARY KEY,
+    rolled_up_to TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,14 @@
+CREATE TABLE retention_policies (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    device_type_id INTEGER NULL UNIQUE, -- NULL=default policy
+    raw_days INTEGER NOT NULL, -- 0=keep forever
+    minute_days INTEGER NOT NULL,
+    hour_days INTEGER NOT NULL,
+    day_days INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_retention_policies_device_type FOREIGN KEY (device_type_id) REFERENCES device_types(id) ON DELETE CASCADE
+);
+
+INSERT INTO retention_policies (device_type_id, raw_days, minute_days, hour_days, day_days)
+VALUES (NULL, 7, 30, 365, 0);
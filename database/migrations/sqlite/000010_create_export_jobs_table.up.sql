@@ -0,0 +1,19 @@
+CREATE TABLE export_jobs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    uuid CHAR(36) NOT NULL UNIQUE,
+    user_id INTEGER NOT NULL,
+    request TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL, -- pending,running,completed,failed
+    row_count INTEGER NOT NULL DEFAULT 0,
+    file_path VARCHAR(255) NOT NULL DEFAULT '',
+    error VARCHAR(1000) NOT NULL DEFAULT '',
+    started_at TIMESTAMP NULL DEFAULT NULL,
+    completed_at TIMESTAMP NULL DEFAULT NULL,
+    expires_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_export_jobs_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_export_jobs_user ON export_jobs (user_id);
+CREATE INDEX idx_export_jobs_status ON export_jobs (status);
@@ -0,0 +1,27 @@
+CREATE TABLE import_jobs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    uuid CHAR(36) NOT NULL UNIQUE,
+    user_id INTEGER NOT NULL,
+    device_id INTEGER NOT NULL,
+    mapping TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL, -- pending,running,completed,failed
+    file_path VARCHAR(255) NOT NULL,
+    byte_offset INTEGER NOT NULL DEFAULT 0, -- byte offset of the next unprocessed row
+    rows_processed INTEGER NOT NULL DEFAULT 0,
+    readings_added INTEGER NOT NULL DEFAULT 0,
+    duplicate_count INTEGER NOT NULL DEFAULT 0,
+    error_count INTEGER NOT NULL DEFAULT 0,
+    row_errors TEXT NULL,
+    first_at TIMESTAMP NULL DEFAULT NULL,
+    last_at TIMESTAMP NULL DEFAULT NULL,
+    error VARCHAR(1000) NOT NULL DEFAULT '',
+    started_at TIMESTAMP NULL DEFAULT NULL,
+    completed_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_import_jobs_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_import_jobs_device FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_import_jobs_device ON import_jobs (device_id);
+CREATE INDEX idx_import_jobs_status ON import_jobs (status);
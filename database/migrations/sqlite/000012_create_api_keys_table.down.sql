@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS api_keys;
+
+DROP INDEX IF EXISTS idx_devices_home;
+ALTER TABLE devices DROP COLUMN room;
+ALTER TABLE devices DROP COLUMN home;
@@ -0,0 +1,19 @@
+ALTER TABLE devices ADD COLUMN home VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE devices ADD COLUMN room VARCHAR(100) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_devices_home ON devices (home);
+
+CREATE TABLE api_keys (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    uuid CHAR(36) NOT NULL UNIQUE,
+    user_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    home VARCHAR(100) NOT NULL DEFAULT '',
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    last_used_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_api_keys_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_api_keys_user ON api_keys (user_id);
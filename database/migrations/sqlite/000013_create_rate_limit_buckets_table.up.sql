@@ -0,0 +1,9 @@
+CREATE TABLE rate_limit_buckets (
+    bucket_key VARCHAR(191) NOT NULL PRIMARY KEY,
+    tokens DOUBLE NOT NULL,
+    refilled_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    version INTEGER NOT NULL
+);
+
+CREATE INDEX idx_rate_limit_buckets_expires_at ON rate_limit_buckets (expires_at);
@@ -2,27 +2,50 @@ package database
 
 import (
 	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
 
 	"home-monitor-backend/database/seeders"
 
 	"gorm.io/gorm"
 )
 
-func Run(db *gorm.DB) error {
+// Profiles are the data sets the seeder can insert, selected with SEED_PROFILE or seed -profile.
+var Profiles = map[string]func(db *gorm.DB, options seeders.Options) error{
+	"dev": func(db *gorm.DB, options seeders.Options) error {
+		return seeders.UserRun(db, seeders.DevUsers())
+	},
+	"demo": func(db *gorm.DB, options seeders.Options) error {
+		if err := seeders.UserRun(db, seeders.DemoUsers()); err != nil {
+			return err
+		}
+		return seeders.DemoRun(db, options)
+	},
+	"test": func(db *gorm.DB, options seeders.Options) error {
+		return seeders.UserRun(db, seeders.TestUsers())
+	},
+}
+
+// ProfileNames lists the seed profiles for messages and usage text.
+func ProfileNames() string {
+	return strings.Join(slices.Sorted(maps.Keys(Profiles)), ", ")
+}
+
+// Seed inserts a profile into the database.
+func Seed(db *gorm.DB, profile string, options seeders.Options) error {
 	if db == nil {
 		return errors.New("db is nil")
 	}
 
-	if err := seeders.UserRun(db); err != nil {
-		return errors.New("failed to run user seeder: " + err.Error())
+	run, ok := Profiles[profile]
+	if !ok {
+		return fmt.Errorf("unknown seed profile %q, expected one of %s", profile, ProfileNames())
+	}
+	if err := run(db, options); err != nil {
+		return fmt.Errorf("failed to run %s seeder: %w", profile, err)
 	}
 
 	return nil
 }
-
-func Seed() error {
-	if DB == nil {
-		return errors.New("database not initialized")
-	}
-	return Run(DB)
-}
@@ -0,0 +1,336 @@
+package seeders
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"home-monitor-backend/models"
+	"home-monitor-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+const demoInterval = 5 * time.Minute
+
+// Options tune the generated data. The same RandomSeed always produces the same values; the
+// timeline ends at the current hour.
+type Options struct {
+	Weeks      int
+	RandomSeed uint64
+}
+
+type demoDevice struct {
+	owner string
+	name  string
+	kind  string
+	home  string
+	room  string
+	// base is the mean temperature of the room in °C.
+	base float64
+}
+
+var demoDevices = []demoDevice{
+	{owner: "demo", name: "Living Room Climate", kind: "climate-sensor", home: "Main House", room: "Living Room", base: 21.5},
+	{owner: "demo", name: "Kitchen Climate", kind: "climate-sensor", home: "Main House", room: "Kitchen", base: 22},
+	{owner: "demo", name: "Bedroom Climate", kind: "climate-sensor", home: "Main House", room: "Bedroom", base: 19.5},
+	{owner: "demo", name: "Hallway Thermostat", kind: "thermostat", home: "Main House", room: "Hallway", base: 20.5},
+	{owner: "demo", name: "TV Plug", kind: "smart-plug", home: "Main House", room: "Living Room"},
+	{owner: "demo", name: "Kettle Plug", kind: "smart-plug", home: "Main House", room: "Kitchen"},
+	{owner: "demo", name: "Front Door", kind: "door-sensor", home: "Main House", room: "Hallway"},
+	{owner: "viewer", name: "Cabin Climate", kind: "climate-sensor", home: "Cabin", room: "Living Room", base: 16},
+	{owner: "viewer", name: "Cabin Door", kind: "door-sensor", home: "Cabin", room: "Entrance"},
+}
+
+func floatPtr(v float64) *float64 { return &v }
+func intPtr(v int) *int           { return &v }
+
+// DemoDeviceTypes are the device types the demo devices are built on.
+func DemoDeviceTypes() []models.DeviceType {
+	temperature := models.MetricSchema{Name: "temperature", Unit: "°C", DataType: models.DataTypeNumber, Min: floatPtr(-40), Max: floatPtr(85), Precision: intPtr(1), OutOfRange: models.OutOfRangeFlag}
+
+	return []models.DeviceType{
+		{
+			Name:        "climate-sensor",
+			Description: "Temperature and humidity sensor",
+			Capabilities: models.DeviceCapabilities{Metrics: []models.MetricSchema{
+				temperature,
+				{Name: "humidity", Unit: "%", DataType: models.DataTypeNumber, Min: floatPtr(0), Max: floatPtr(100), Precision: intPtr(0)},
+			}},
+		},
+		{
+			Name:        "thermostat",
+			Description: "Heating thermostat with a setpoint",
+			Capabilities: models.DeviceCapabilities{
+				Metrics: []models.MetricSchema{
+					temperature,
+					{Name: "setpoint", Unit: "°C", DataType: models.DataTypeNumber, Min: floatPtr(5), Max: floatPtr(30), Precision: intPtr(1)},
+				},
+				Commands: []models.CommandSchema{
+					{Name: "set_setpoint", Params: []models.ParamSchema{
+						{Name: "value", DataType: models.DataTypeNumber, Required: true, Min: floatPtr(5), Max: floatPtr(30)},
+					}},
+				},
+			},
+		},
+		{
+			Name:        "smart-plug",
+			Description: "Switchable plug with power metering",
+			Capabilities: models.DeviceCapabilities{
+				Metrics: []models.MetricSchema{
+					{Name: "power", Unit: "W", DataType: models.DataTypeNumber, Min: floatPtr(0), Max: floatPtr(3680), Precision: intPtr(1)},
+					{Name: "energy", Unit: "kWh", DataType: models.DataTypeNumber, Min: floatPtr(0), Precision: intPtr(3)},
+				},
+				Commands: []models.CommandSchema{
+					{Name: "switch", Params: []models.ParamSchema{{Name: "on", DataType: models.DataTypeBoolean, Required: true}}},
+				},
+			},
+		},
+		{
+			Name:        "door-sensor",
+			Description: "Contact sensor reporting when a door opens and closes",
+			Capabilities: models.DeviceCapabilities{Metrics: []models.MetricSchema{
+				{Name: "door_open", DataType: models.DataTypeBoolean},
+			}},
+		},
+	}
+}
+
+// DemoRun creates the demo homes: device types, devices owned by the demo users, weeks of readings
+// and the command history of the thermostat. It does nothing when the demo users already own devices.
+func DemoRun(db *gorm.DB, options Options) error {
+	if db == nil {
+		return errors.New("db is nil")
+	}
+	if options.Weeks < 1 {
+		return errors.New("weeks must be at least 1")
+	}
+
+	owners := map[string]*models.User{}
+	for _, device := range demoDevices {
+		if owners[device.owner] != nil {
+			continue
+		}
+		var user models.User
+		if err := db.Where("username = ?", device.owner).First(&user).Error; err != nil {
+			return errors.New("failed to find demo user " + device.owner + ": " + err.Error())
+		}
+		owners[device.owner] = &user
+	}
+
+	var existing int64
+	if err := db.Model(&models.Device{}).Where("user_id = ?", owners["demo"].ID).Count(&existing).Error; err != nil {
+		return err
+	}
+	if existing > 0 {
+		return nil
+	}
+
+	deviceTypes := map[string]*models.DeviceType{}
+	for _, deviceType := range DemoDeviceTypes() {
+		if err := db.Where("name = ?", deviceType.Name).FirstOrCreate(&deviceType).Error; err != nil {
+			return errors.New("failed to seed device types: " + err.Error())
+		}
+		deviceTypes[deviceType.Name] = &deviceType
+	}
+
+	end := time.Now().UTC().Truncate(time.Hour)
+	start := end.Add(-time.Duration(options.Weeks) * 7 * 24 * time.Hour)
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for i, spec := range demoDevices {
+			_, tokenHash, err := utils.GenerateDeviceToken()
+			if err != nil {
+				return err
+			}
+
+			device := models.Device{
+				UserID:       owners[spec.owner].ID,
+				DeviceTypeID: &deviceTypes[spec.kind].ID,
+				Name:         spec.name,
+				Home:         spec.home,
+				Room:         spec.room,
+				TokenHash:    tokenHash,
+				LastSeenAt:   &end,
+			}
+			if err := tx.Create(&device).Error; err != nil {
+				return errors.New("failed to seed devices: " + err.Error())
+			}
+
+			// Each device draws from its own stream, so its data does not change when devices are added.
+			rng := rand.New(rand.NewPCG(options.RandomSeed, uint64(i)))
+			readings, commands := demoSeries(spec, rng, start, end)
+			for j := range readings {
+				readings[j].DeviceID = device.ID
+			}
+			for j := range commands {
+				commands[j].DeviceID = device.ID
+				commands[j].UserID = device.UserID
+			}
+
+			if err := tx.CreateInBatches(readings, 500).Error; err != nil {
+				return errors.New("failed to seed readings: " + err.Error())
+			}
+			if len(commands) > 0 {
+				if err := tx.CreateInBatches(commands, 500).Error; err != nil {
+					return errors.New("failed to seed commands: " + err.Error())
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func demoSeries(spec demoDevice, rng *rand.Rand, start time.Time, end time.Time) ([]models.Reading, []models.Command) {
+	switch spec.kind {
+	case "climate-sensor":
+		return demoClimate(spec, rng, start, end), nil
+	case "thermostat":
+		return demoThermostat(spec, rng, start, end)
+	case "smart-plug":
+		return demoPlug(spec, rng, start, end), nil
+	case "door-sensor":
+		return demoDoor(rng, start, end), nil
+	}
+	return nil, nil
+}
+
+// diurnal is a daily wave between -1 at 03:00 and 1 at 15:00.
+func diurnal(t time.Time) float64 {
+	hours := float64(t.Hour()) + float64(t.Minute())/60
+	return math.Sin(2 * math.Pi * (hours - 9) / 24)
+}
+
+func round(v float64, digits int) float64 {
+	scale := math.Pow(10, float64(digits))
+	return math.Round(v*scale) / scale
+}
+
+func demoClimate(spec demoDevice, rng *rand.Rand, start time.Time, end time.Time) []models.Reading {
+	var readings []models.Reading
+	// drift is a slow random walk standing in for the weather.
+	drift := 0.0
+	for t := start; t.Before(end); t = t.Add(demoInterval) {
+		drift = 0.995*drift + rng.NormFloat64()*0.05
+		wave := diurnal(t)
+
+		temperature := spec.base + 2.5*wave + drift + rng.NormFloat64()*0.15
+		humidity := 55 - 8*wave - 2*drift + rng.NormFloat64()*1.5
+		if spec.room == "Kitchen" && (t.Hour() == 7 || t.Hour() == 19) {
+			// Cooking.
+			temperature += 1.5
+			humidity += 12
+		}
+		humidity = math.Max(20, math.Min(95, humidity))
+
+		readings = append(readings,
+			models.Reading{Metric: "temperature", Value: round(temperature, 1), RecordedAt: t},
+			models.Reading{Metric: "humidity", Value: round(humidity, 0), RecordedAt: t},
+		)
+	}
+	return readings
+}
+
+// demoThermostat follows a day and night schedule. Every switch is an acknowledged set_setpoint
+// command, and the room temperature chases the setpoint.
+func demoThermostat(spec demoDevice, rng *rand.Rand, start time.Time, end time.Time) ([]models.Reading, []models.Command) {
+	var readings []models.Reading
+	var commands []models.Command
+
+	setpoint := 18.0
+	temperature := spec.base
+	for t := start; t.Before(end); t = t.Add(demoInterval) {
+		if t.Minute() == 30 && (t.Hour() == 6 || t.Hour() == 22) {
+			setpoint = 21
+			if t.Hour() == 22 {
+				setpoint = 18
+			}
+
+			params, _ := json.Marshal(map[string]float64{"value": setpoint})
+			sentAt := t.Add(time.Duration(1+rng.IntN(20)) * time.Second)
+			completedAt := sentAt.Add(time.Duration(200+rng.IntN(800)) * time.Millisecond)
+			command := models.Command{
+				Name:        "set_setpoint",
+				Params:      params,
+				Status:      models.CommandStatusAcked,
+				Result:      json.RawMessage(`{}`),
+				ExpiresAt:   t.Add(models.CommandDefaultTimeout),
+				SentAt:      &sentAt,
+				CompletedAt: &completedAt,
+			}
+			// One in twenty attempts fails, as if the device was briefly offline.
+			if rng.IntN(20) == 0 {
+				command.Status = models.CommandStatusFailed
+				command.Result = nil
+				command.Error = "device busy"
+			}
+			commands = append(commands, command)
+		}
+
+		temperature += 0.08*(setpoint-temperature) + 0.03*diurnal(t) + rng.NormFloat64()*0.05
+		readings = append(readings,
+			models.Reading{Metric: "temperature", Value: round(temperature, 1), RecordedAt: t},
+			models.Reading{Metric: "setpoint", Value: setpoint, RecordedAt: t},
+		)
+	}
+	return readings, commands
+}
+
+// demoPlug models a TV on in the evening, or a kettle with short spikes around meals.
+func demoPlug(spec demoDevice, rng *rand.Rand, start time.Time, end time.Time) []models.Reading {
+	var readings []models.Reading
+	energy := 0.0
+	for t := start; t.Before(end); t = t.Add(demoInterval) {
+		power := 0.5 + rng.Float64()*0.3
+		hour := t.Hour()
+		if spec.room == "Kitchen" {
+			if (hour == 7 || hour == 12 || hour == 18) && rng.IntN(6) == 0 {
+				power = 1900 + rng.Float64()*250
+			}
+		} else if hour >= 19 && hour < 23 && rng.IntN(10) != 0 {
+			power = 85 + rng.Float64()*35
+		}
+
+		energy += power * demoInterval.Hours() / 1000
+		readings = append(readings,
+			models.Reading{Metric: "power", Value: round(power, 1), RecordedAt: t},
+			models.Reading{Metric: "energy", Value: round(energy, 3), RecordedAt: t},
+		)
+	}
+	return readings
+}
+
+// demoDoor reports an open and a close event for each use of the door: leaving in the morning,
+// coming back in the evening and a few random trips.
+func demoDoor(rng *rand.Rand, start time.Time, end time.Time) []models.Reading {
+	var readings []models.Reading
+	for day := start.Truncate(24 * time.Hour); day.Before(end); day = day.Add(24 * time.Hour) {
+		var opens []time.Duration
+		if day.Weekday() != time.Saturday && day.Weekday() != time.Sunday {
+			opens = append(opens,
+				7*time.Hour+time.Duration(30+rng.IntN(60))*time.Minute,
+				17*time.Hour+time.Duration(rng.IntN(120))*time.Minute,
+			)
+		}
+		for range rng.IntN(4) {
+			opens = append(opens, 9*time.Hour+time.Duration(rng.IntN(12*60))*time.Minute)
+		}
+
+		slices.Sort(opens)
+		for _, offset := range opens {
+			openedAt := day.Add(offset)
+			closedAt := openedAt.Add(time.Duration(20+rng.IntN(160)) * time.Second)
+			if openedAt.Before(start) || !closedAt.Before(end) {
+				continue
+			}
+			readings = append(readings,
+				models.Reading{Metric: "door_open", Value: 1, RecordedAt: openedAt},
+				models.Reading{Metric: "door_open", Value: 0, RecordedAt: closedAt},
+			)
+		}
+	}
+	return readings
+}
@@ -10,16 +10,37 @@ import (
 	"gorm.io/gorm/clause"
 )
 
-func UserRun(db *gorm.DB) error {
-	if db == nil {
-		return errors.New("db is nil")
+// DevUsers are the accounts of the dev profile. Their passwords are public, which is why seeding
+// is refused in release mode.
+func DevUsers() []models.User {
+	return []models.User{
+		{Username: "admin", Password: "password", Role: models.UserRoleAdmin},
+		{Username: "admin2", Password: "password2", Role: models.UserRoleAdmin},
+		{Username: "user", Password: "password", Role: models.UserRoleUser},
+		{Username: "user2", Password: "password2", Role: models.UserRoleUser},
+	}
+}
+
+// DemoUsers are the accounts of the demo profile.
+func DemoUsers() []models.User {
+	return []models.User{
+		{Username: "demo", Password: "demo-password", Role: models.UserRoleAdmin},
+		{Username: "viewer", Password: "viewer-password", Role: models.UserRoleUser},
 	}
+}
 
-	users := []models.User{
-		{UUID: uuid.New(), Username: "admin", Password: "password", Role: models.UserRoleAdmin},
-		{UUID: uuid.New(), Username: "admin2", Password: "password2", Role: models.UserRoleAdmin},
-		{UUID: uuid.New(), Username: "user", Password: "password", Role: models.UserRoleUser},
-		{UUID: uuid.New(), Username: "user2", Password: "password2", Role: models.UserRoleUser},
+// TestUsers are the accounts of the test profile, one of each role.
+func TestUsers() []models.User {
+	return []models.User{
+		{Username: "test-admin", Password: "test-password", Role: models.UserRoleAdmin},
+		{Username: "test-user", Password: "test-password", Role: models.UserRoleUser},
+	}
+}
+
+// UserRun creates the users that do not exist yet; existing usernames are left untouched.
+func UserRun(db *gorm.DB, users []models.User) error {
+	if db == nil {
+		return errors.New("db is nil")
 	}
 
 	for i := range users {
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"home-monitor-backend/config"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+const sqliteDriverName = "sqlite3_utc"
+
+func init() {
+	sql.Register(sqliteDriverName, &sqliteDriver{})
+}
+
+// createSQLiteDir creates the directory of the SQLite database file, which SQLite does not do.
+func createSQLiteDir(cfg config.Database) error {
+	if cfg.Driver != config.DriverSQLite {
+		return nil
+	}
+	return os.MkdirAll(filepath.Dir(cfg.Path), 0o755)
+}
+
+// sqliteDriver is the SQLite driver with every bound time converted to UTC. SQLite stores times as
+// text and compares them as strings, which only orders them correctly when they share an offset.
+type sqliteDriver struct {
+	sqlite3.SQLiteDriver
+}
+
+func (d *sqliteDriver) Open(dsn string) (driver.Conn, error) {
+	conn, err := d.SQLiteDriver.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteConn{SQLiteConn: conn.(*sqlite3.SQLiteConn)}, nil
+}
+
+type sqliteConn struct {
+	*sqlite3.SQLiteConn
+}
+
+// CheckNamedValue converts times to UTC and leaves every other value to the default conversion.
+func (c *sqliteConn) CheckNamedValue(value *driver.NamedValue) error {
+	switch v := value.Value.(type) {
+	case time.Time:
+		value.Value = v.UTC()
+		return nil
+	case *time.Time:
+		if v != nil {
+			value.Value = v.UTC()
+			return nil
+		}
+	}
+	return driver.ErrSkip
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	queryTimeoutCancelKey  = "query_timeout:cancel"
+	queryTimeoutContextKey = "query_timeout:context"
+)
+
+// QueryTimeout bounds every statement with a deadline on top of the caller's context, so a stuck
+// query cannot hold a request or a connection forever. Streaming reads through Rows are left
+// alone because the rows outlive the statement.
+type QueryTimeout struct {
+	Timeout time.Duration
+}
+
+func (p *QueryTimeout) Name() string {
+	return "query_timeout"
+}
+
+func (p *QueryTimeout) Initialize(db *gorm.DB) error {
+	if p.Timeout <= 0 {
+		return nil
+	}
+
+	callbacks := db.Callback()
+	if err := callbacks.Create().Before("*").Register("query_timeout:start_create", p.start); err != nil {
+		return err
+	}
+	if err := callbacks.Create().After("*").Register("query_timeout:stop_create", p.stop); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("*").Register("query_timeout:start_query", p.start); err != nil {
+		return err
+	}
+	if err := callbacks.Query().After("*").Register("query_timeout:stop_query", p.stop); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("*").Register("query_timeout:start_update", p.start); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("*").Register("query_timeout:stop_update", p.stop); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("*").Register("query_timeout:start_delete", p.start); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().After("*").Register("query_timeout:stop_delete", p.stop); err != nil {
+		return err
+	}
+	if err := callbacks.Raw().Before("*").Register("query_timeout:start_raw", p.start); err != nil {
+		return err
+	}
+	return callbacks.Raw().After("*").Register("query_timeout:stop_raw", p.stop)
+}
+
+func (p *QueryTimeout) start(db *gorm.DB) {
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	db.InstanceSet(queryTimeoutContextKey, ctx)
+	ctx, cancel := context.WithTimeout(ctx, p.Timeout)
+	db.Statement.Context = ctx
+	db.InstanceSet(queryTimeoutCancelKey, cancel)
+}
+
+func (p *QueryTimeout) stop(db *gorm.DB) {
+	if cancel, ok := db.InstanceGet(queryTimeoutCancelKey); ok {
+		cancel.(context.CancelFunc)()
+	}
+	// CreateInBatches runs every batch on the same statement; the next batch must not inherit the
+	// cancelled deadline.
+	if ctx, ok := db.InstanceGet(queryTimeoutContextKey); ok {
+		db.Statement.Context = ctx.(context.Context)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"home-monitor-backend/models"
+	"home-monitor-backend/services"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+)
+
+// runDevice implements the device command, which registers devices for a user, e.g. while
+// provisioning hardware.
+func runDevice(ctx context.Context, userService services.UserService, deviceTypeService services.DeviceTypeService, deviceService services.DeviceService, args []string) error {
+	action, args, err := subcommand("device", "create", args)
+	if err != nil {
+		return err
+	}
+	if action != "create" {
+		return fmt.Errorf("unknown device action %q, expected create", action)
+	}
+
+	flags := flag.NewFlagSet("device create", flag.ExitOnError)
+	ownerFlag := flags.String("owner", "", "username of the owner")
+	nameFlag := flags.String("name", "", "device name")
+	typeFlag := flags.String("type", "", "device type, by UUID or name")
+	homeFlag := flags.String("home", "", "home label")
+	roomFlag := flags.String("room", "", "room label")
+	flags.Parse(args)
+
+	if *ownerFlag == "" {
+		flags.Usage()
+		return errors.New("-owner is required")
+	}
+	owner, err := userService.UserDetail(ctx, *ownerFlag)
+	if err != nil {
+		return err
+	}
+
+	input := models.DeviceCreateRequest{Name: *nameFlag, Home: *homeFlag, Room: *roomFlag}
+	if *typeFlag != "" {
+		deviceType, err := findDeviceType(ctx, deviceTypeService, *typeFlag)
+		if err != nil {
+			return err
+		}
+		input.DeviceTypeUUID = &deviceType.UUID
+	}
+	if err := binding.Validator.ValidateStruct(&input); err != nil {
+		return fmt.Errorf("invalid device: %w", err)
+	}
+
+	device, token, err := deviceService.DeviceCreate(ctx, input, owner.UUID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created device %s (%s) for %s\n", device.Name, device.UUID, owner.Username)
+	fmt.Printf("Token: %s\n", token)
+	fmt.Println("The token is not stored and cannot be shown again; the device sends it in the X-Device-Token header.")
+	return nil
+}
+
+func findDeviceType(ctx context.Context, deviceTypeService services.DeviceTypeService, nameOrUUID string) (*models.DeviceType, error) {
+	deviceTypeUUID, _ := uuid.Parse(nameOrUUID)
+
+	deviceTypes, err := deviceTypeService.DeviceTypeList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range deviceTypes {
+		if deviceTypes[i].Name == nameOrUUID || (deviceTypeUUID != uuid.Nil && deviceTypes[i].UUID == deviceTypeUUID) {
+			return &deviceTypes[i], nil
+		}
+	}
+	return nil, fmt.Errorf("device type %s not found", nameOrUUID)
+}
@@ -0,0 +1,81 @@
+package events
+
+import "sync"
+
+const subscriberBuffer = 16
+
+type Event struct {
+	Type string `json:"type"`
+	Data any    `json:"data"`
+}
+
+// Broker fans out events to in-process subscribers grouped by topic.
+// Publishing never blocks; a subscriber that falls behind misses events.
+type Broker struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[chan Event]struct{}
+	closed      bool
+}
+
+func NewBroker() *Broker {
+	return &Broker{subscribers: make(map[string]map[chan Event]struct{})}
+}
+
+// Subscribe returns a channel receiving every event published to topic and a function that
+// cancels the subscription and closes the channel. After Close the channel is closed at once.
+func (b *Broker) Subscribe(topic string) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+
+	if b.subscribers[topic] == nil {
+		b.subscribers[topic] = make(map[chan Event]struct{})
+	}
+	b.subscribers[topic][ch] = struct{}{}
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if _, ok := b.subscribers[topic][ch]; !ok {
+			return
+		}
+		delete(b.subscribers[topic], ch)
+		if len(b.subscribers[topic]) == 0 {
+			delete(b.subscribers, topic)
+		}
+		close(ch)
+	}
+}
+
+func (b *Broker) Publish(topic string, event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.subscribers[topic] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Close ends every subscription, so that streaming clients are disconnected during shutdown.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for topic, subscribers := range b.subscribers {
+		for ch := range subscribers {
+			close(ch)
+		}
+		delete(b.subscribers, topic)
+	}
+	b.closed = true
+}
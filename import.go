@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"home-monitor-backend/models"
+	"home-monitor-backend/services"
+	"os"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+)
+
+// runImport implements the import command, which loads a local CSV file for a device without
+// going through the API. An interrupted import is continued with -resume.
+func runImport(ctx context.Context, importService services.ImportService, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	deviceFlag := flags.String("device", "", "UUID of the device the readings belong to")
+	fileFlag := flags.String("file", "", "CSV file to import")
+	mappingFlag := flags.String("mapping", "", "JSON file with the column mapping")
+	resumeFlag := flags.String("resume", "", "UUID of an interrupted import job to continue")
+	flags.Parse(args)
+
+	var job *models.ImportJob
+	var err error
+	if *resumeFlag != "" {
+		importUUID, parseErr := uuid.Parse(*resumeFlag)
+		if parseErr != nil {
+			return errors.New("invalid import job UUID")
+		}
+		job, err = importService.ImportResume(ctx, importUUID)
+	} else {
+		if *deviceFlag == "" || *fileFlag == "" || *mappingFlag == "" {
+			flags.Usage()
+			return errors.New("-device, -file and -mapping are required")
+		}
+
+		deviceUUID, parseErr := uuid.Parse(*deviceFlag)
+		if parseErr != nil {
+			return errors.New("invalid device UUID")
+		}
+
+		data, readErr := os.ReadFile(*mappingFlag)
+		if readErr != nil {
+			return readErr
+		}
+
+		var mapping models.ImportMapping
+		if err := json.Unmarshal(data, &mapping); err != nil {
+			return fmt.Errorf("invalid mapping: %w", err)
+		}
+		if err := binding.Validator.ValidateStruct(&mapping); err != nil {
+			return fmt.Errorf("invalid mapping: %w", err)
+		}
+
+		job, err = importService.ImportFile(ctx, deviceUUID, mapping, *fileFlag)
+	}
+	if job == nil {
+		return err
+	}
+
+	fmt.Printf("Import %s %s: %d rows, %d readings added, %d duplicates, %d errors\n",
+		job.UUID, job.Status, job.RowsProcessed, job.ReadingsAdded, job.DuplicateCount, job.ErrorCount)
+	for _, rowError := range job.RowErrors {
+		if rowError.Column != "" {
+			fmt.Printf("  row %d, column %s: %s\n", rowError.Row, rowError.Column, rowError.Error)
+		} else {
+			fmt.Printf("  row %d: %s\n", rowError.Row, rowError.Error)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%w (continue with -resume %s)", err, job.UUID)
+	}
+	return nil
+}
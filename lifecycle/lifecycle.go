@@ -0,0 +1,161 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// workerGrace is how long cancelled workers get to return even when draining the requests used
+// up the deadline, so that a slow drain does not cut off a worker's last write.
+const workerGrace = 5 * time.Second
+
+// ErrWorkersRunning is returned by Run when workers did not stop in time. The resources registered
+// with OnClose are left open, as the workers may still be using them.
+var ErrWorkersRunning = errors.New("workers still running after shutdown")
+
+type worker struct {
+	name    string
+	run     func(ctx context.Context)
+	running atomic.Bool
+}
+
+type closer struct {
+	name string
+	fn   func() error
+}
+
+// Lifecycle runs the HTTP server next to the background workers and stops them in order:
+// the server stops accepting connections and drains in-flight requests, the workers are
+// cancelled and waited for, and finally the registered resources are closed. The whole
+// shutdown shares one drain deadline, extended by a short grace period for the workers.
+type Lifecycle struct {
+	server       *http.Server
+	drainTimeout time.Duration
+	workers      []*worker
+	closers      []closer
+	draining     atomic.Bool
+}
+
+func New(server *http.Server, drainTimeout time.Duration) *Lifecycle {
+	return &Lifecycle{server: server, drainTimeout: drainTimeout}
+}
+
+// Go registers a background worker. It is started by Run and must return once its context is
+// cancelled.
+func (l *Lifecycle) Go(name string, run func(ctx context.Context)) {
+	l.workers = append(l.workers, &worker{name: name, run: run})
+}
+
+// Draining reports whether shutdown has started, so that readiness can fail while requests drain.
+func (l *Lifecycle) Draining() bool {
+	return l.draining.Load()
+}
+
+// Workers reports for every registered worker whether it is running.
+func (l *Lifecycle) Workers() map[string]bool {
+	workers := make(map[string]bool, len(l.workers))
+	for _, worker := range l.workers {
+		workers[worker.name] = worker.running.Load()
+	}
+	return workers
+}
+
+// OnShutdown registers a function called when the server starts shutting down, for streams and
+// other long-lived connections that would otherwise hold up the drain.
+func (l *Lifecycle) OnShutdown(fn func()) {
+	l.server.RegisterOnShutdown(fn)
+}
+
+// OnClose registers a resource closed after the server and the workers have stopped, in the
+// order of registration.
+func (l *Lifecycle) OnClose(name string, fn func() error) {
+	l.closers = append(l.closers, closer{name: name, fn: fn})
+}
+
+// Run serves until ctx is cancelled or the server fails, then shuts everything down. It
+// returns the server error, if any, and ErrWorkersRunning when workers did not stop in time.
+func (l *Lifecycle) Run(ctx context.Context) error {
+	workerCtx, cancelWorkers := context.WithCancel(context.Background())
+	defer cancelWorkers()
+
+	var workers sync.WaitGroup
+	for _, worker := range l.workers {
+		workers.Add(1)
+		worker.running.Store(true)
+		go func() {
+			defer workers.Done()
+			defer worker.running.Store(false)
+			worker.run(workerCtx)
+			slog.Info("Worker stopped", "worker", worker.name)
+		}()
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("Server listening", "addr", l.server.Addr)
+		if err := l.server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+		}
+		close(serveErr)
+	}()
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		slog.Info("Shutting down", "drain_timeout", l.drainTimeout.String())
+	case runErr = <-serveErr:
+		slog.Error("Server failed, shutting down", "error", runErr)
+	}
+
+	l.draining.Store(true)
+	drainCtx, cancel := context.WithTimeout(context.Background(), l.drainTimeout)
+	defer cancel()
+
+	if err := l.server.Shutdown(drainCtx); err != nil {
+		slog.Warn("Requests still running at the drain deadline, closing their connections", "error", err)
+		l.server.Close()
+	} else {
+		slog.Info("Server stopped")
+	}
+
+	cancelWorkers()
+	stopped := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(stopped)
+	}()
+
+	deadline := time.Now().Add(workerGrace)
+	if drainDeadline, _ := drainCtx.Deadline(); drainDeadline.After(deadline) {
+		deadline = drainDeadline
+	}
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	select {
+	case <-stopped:
+	case <-timer.C:
+		var running []string
+		for name, isRunning := range l.Workers() {
+			if isRunning {
+				running = append(running, name)
+			}
+		}
+		// Closing the database under a worker would fail its write halfway, so leave it to the exit.
+		slog.Error("Workers still running after the grace period, leaving resources open", "workers", running)
+		return errors.Join(runErr, ErrWorkersRunning)
+	}
+
+	for _, closer := range l.closers {
+		if err := closer.fn(); err != nil {
+			slog.Error("Closing failed", "resource", closer.name, "error", err)
+			continue
+		}
+		slog.Info("Closed", "resource", closer.name)
+	}
+	return runErr
+}
@@ -0,0 +1,73 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+type attrsKey struct{}
+
+// Setup makes a JSON logger writing to stdout the default slog logger. The level is debug,
+// info, warn or error; anything else means info.
+func Setup(levelName string) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(levelName)); err != nil {
+		level = slog.LevelInfo
+	}
+
+	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: level})
+	slog.SetDefault(slog.New(&contextHandler{Handler: handler}))
+}
+
+// With returns a context whose log records carry the given attributes in addition to those
+// already attached, such as the request ID and the authenticated user.
+func With(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	merged := make([]slog.Attr, 0, len(existing)+len(attrs))
+	merged = append(merged, existing...)
+	merged = append(merged, attrs...)
+	return context.WithValue(ctx, attrsKey{}, merged)
+}
+
+// Fatal logs at error level and exits, for failures during startup.
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// Redact hides values whose key looks like a credential.
+func Redact(key string, value string) string {
+	if value == "" {
+		return value
+	}
+
+	key = strings.ToLower(key)
+	for _, secret := range []string{"token", "password", "secret", "key", "authorization"} {
+		if strings.Contains(key, secret) {
+			return "[REDACTED]"
+		}
+	}
+	return value
+}
+
+// contextHandler adds the attributes attached to the context with With to every record.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if attrs, ok := ctx.Value(attrsKey{}).([]slog.Attr); ok {
+		record.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -1,17 +1,34 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"home-monitor-backend/config"
 	"home-monitor-backend/controllers"
 	"home-monitor-backend/database"
 	"home-monitor-backend/docs"
+	"home-monitor-backend/events"
+	"home-monitor-backend/lifecycle"
+	"home-monitor-backend/logging"
+	"home-monitor-backend/metrics"
+	"home-monitor-backend/middlewares"
+	"home-monitor-backend/models"
+	"home-monitor-backend/ratelimit"
 	"home-monitor-backend/repositories"
 	"home-monitor-backend/routes"
 	"home-monitor-backend/services"
-	"log"
+	"home-monitor-backend/utils"
+	"home-monitor-backend/workers"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	_ "time/tzdata"
 
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
@@ -26,39 +43,209 @@ import (
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token.
 func main() {
-	err := godotenv.Load()
+	command, args, err := parseCommand(os.Args[1:])
 	if err != nil {
-		log.Fatal("Error loading .env file")
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
 	}
 
-	database.ConnectDB()
-	database.Migrations()
+	cfg, err := config.Load()
+	if err != nil {
+		logging.Setup("info")
+		logging.Fatal("Invalid configuration", "error", err)
+	}
+	logging.Setup(cfg.LogLevel)
+	slog.Debug("Configuration loaded", "config", cfg)
+
+	if err := utils.SetupValidator(); err != nil {
+		logging.Fatal("Validator setup failed", "error", err)
+	}
+
+	if command == "migrate" {
+		if err := runMigrate(cfg.Database, args); err != nil {
+			logging.Fatal("Migration failed", "error", err)
+		}
+		return
+	}
+
+	db, err := database.ConnectDB(cfg.Database)
+	if err != nil {
+		logging.Fatal("Failed to connect to database", "driver", cfg.Database.Driver, "error", err)
+	}
+	database.Migrations(cfg.Database)
 
-	if os.Getenv("SEED") == "true" {
-		if err := database.Seed(); err != nil {
-			log.Fatal("Seeding failed: ", err)
+	// SEED=true predates the seed command and is still honored in place of serve.
+	if command == "seed" || (command == "serve" && cfg.Seed) {
+		if err := runSeed(db, cfg, args); err != nil {
+			logging.Fatal("Seeding failed", "error", err)
 		}
-		log.Println("Seeding completed. Exiting as requested by SEED=true.")
 		return
 	}
 
-	userRepo := repositories.NewUserRepository()
-	userService := services.NewUserService(userRepo)
+	signer := utils.NewJWTSigner(cfg.JWT.Secret, cfg.JWT.Expiration)
+
+	userRepo := repositories.NewUserRepository(db)
+	userService := services.NewUserService(userRepo, signer)
 	userController := controllers.NewUserController(userService)
 
-	if os.Getenv("GIN_MODE") != "release" {
-		gin.SetMode(gin.DebugMode)
-	} else {
-		gin.SetMode(gin.ReleaseMode)
+	deviceTypeRepo := repositories.NewDeviceTypeRepository(db)
+	deviceTypeService := services.NewDeviceTypeService(userRepo, deviceTypeRepo)
+	deviceTypeController := controllers.NewDeviceTypeController(deviceTypeService)
+
+	deviceRepo := repositories.NewDeviceRepository(db)
+	deviceService := services.NewDeviceService(userRepo, deviceRepo, deviceTypeRepo)
+	deviceController := controllers.NewDeviceController(deviceService)
+
+	commandRepo := repositories.NewCommandRepository(db)
+	commandService := services.NewCommandService(userRepo, deviceRepo, commandRepo, nil)
+	commandController := controllers.NewCommandController(commandService)
+
+	apiKeyRepo := repositories.NewAPIKeyRepository(db)
+	apiKeyService := services.NewAPIKeyService(userRepo, apiKeyRepo)
+	apiKeyController := controllers.NewAPIKeyController(apiKeyService)
+
+	readingRepo := repositories.NewReadingRepository(db)
+	rollupRepo := repositories.NewRollupRepository(db)
+	rollupService := services.NewRollupService(readingRepo, rollupRepo)
+	readingService := services.NewReadingService(userRepo, deviceRepo, readingRepo, rollupRepo, rollupService)
+	readingController := controllers.NewReadingController(readingService)
+
+	exportRepo := repositories.NewExportRepository(db)
+	exportService := services.NewExportService(userRepo, deviceRepo, readingRepo, exportRepo, cfg.ExportDir)
+	exportController := controllers.NewExportController(exportService)
+
+	importRepo := repositories.NewImportRepository(db)
+	importService := services.NewImportService(userRepo, deviceRepo, readingRepo, importRepo, rollupService, cfg.ImportDir)
+	importController := controllers.NewImportController(importService, cfg.ImportMaxBytes)
+
+	if command != "serve" {
+		// Interrupting an import leaves it at its last checkpoint, ready for -resume.
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := runCommand(ctx, command, args, userService, deviceTypeService, deviceService, importService); err != nil {
+			logging.Fatal("Command failed", "command", command, "error", err)
+		}
+		return
+	}
+
+	retentionRepo := repositories.NewRetentionRepository(db)
+	retentionService := services.NewRetentionService(userRepo, deviceTypeRepo, readingRepo, rollupRepo, retentionRepo)
+	retentionController := controllers.NewRetentionController(retentionService)
+
+	broker := events.NewBroker()
+
+	setupService := services.NewSetupService(userRepo, signer)
+	setupController := controllers.NewSetupController(setupService)
+	setupToken, err := setupService.SetupStart(context.Background())
+	if err != nil {
+		logging.Fatal("Failed to check for an administrator", "error", err)
+	}
+	if setupToken != "" {
+		slog.Warn("No administrator exists. Create one with POST /api/setup and this token, or with the user create command",
+			"setup_token", setupToken)
 	}
 
-	r := gin.Default()
+	shadowRepo := repositories.NewShadowRepository(db)
+	shadowService := services.NewShadowService(userRepo, deviceRepo, shadowRepo, broker)
+	shadowController := controllers.NewShadowController(shadowService)
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		logging.Fatal("Failed to get database handle", "error", err)
+	}
+	metrics.Register(sqlDB, map[string]metrics.QueueDepth{
+		"commands": func(ctx context.Context) (int64, error) {
+			return commandRepo.CommandCountByStatus(ctx, models.CommandStatusPending)
+		},
+		"exports": func(ctx context.Context) (int64, error) {
+			return exportRepo.ExportCountByStatus(ctx, models.ExportStatusPending)
+		},
+		"imports": func(ctx context.Context) (int64, error) {
+			return importRepo.ImportCountByStatus(ctx, models.ImportStatusPending)
+		},
+	})
+
+	limiter := &ratelimit.Limiter{
+		Store: ratelimit.NewMemoryStore(),
+		Policies: map[string]ratelimit.Policy{
+			ratelimit.PolicyLogin:  cfg.RateLimit.Login,
+			ratelimit.PolicyIngest: cfg.RateLimit.Ingest,
+			ratelimit.PolicyQuery:  cfg.RateLimit.Query,
+		},
+	}
+	if cfg.RateLimit.Store == config.RateLimitStoreDatabase {
+		limiter.Store = ratelimit.NewDatabaseStore(repositories.NewRateLimitRepository(db))
+	}
+
+	gin.SetMode(cfg.GinMode)
+
+	r := gin.New()
+	// ClientIP, which logs and rate limits rely on, only believes forwarding headers set by these.
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		logging.Fatal("Invalid trusted proxies", "error", err)
+	}
+	r.Use(middlewares.RequestID(), middlewares.Logger(), middlewares.Recovery(), middlewares.Metrics(), middlewares.Errors(),
+		middlewares.SecurityHeaders(cfg.Server.HSTSMaxAge), middlewares.CORS(cfg.CORS))
 
 	routes.RootRoute(r)
-	routes.UserRoutes(r, userController)
+	routes.MetricsRoute(r, cfg.MetricsToken)
+	routes.SetupRoutes(r, setupController, limiter)
+	routes.UserRoutes(r, userController, signer, limiter)
+	routes.DeviceTypeRoutes(r, deviceTypeController, signer)
+	routes.DeviceRoutes(r, deviceController, signer)
+	routes.APIKeyRoutes(r, apiKeyController, signer)
+	routes.CommandRoutes(r, commandController, signer, deviceService)
+	routes.ShadowRoutes(r, shadowController, signer, deviceService)
+	routes.ReadingRoutes(r, readingController, signer, deviceService, apiKeyService, limiter)
+	routes.RetentionRoutes(r, retentionController, signer)
+	routes.ExportRoutes(r, exportController, signer, limiter)
+	routes.ImportRoutes(r, importController, signer, limiter)
 
 	docs.SwaggerInfo.BasePath = "/api"
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 
-	r.Run(":8080")
+	app := lifecycle.New(&http.Server{Addr: cfg.Server.Addr, Handler: r}, cfg.Server.ShutdownTimeout)
+	app.Go("command_expiry", func(ctx context.Context) { workers.CommandExpiry(ctx, commandService, 30*time.Second) })
+	app.Go("rollup", func(ctx context.Context) { workers.Rollup(ctx, rollupService, time.Minute) })
+	app.Go("retention", func(ctx context.Context) { workers.Retention(ctx, retentionService, time.Hour) })
+	app.Go("export", func(ctx context.Context) { workers.Export(ctx, exportService, 10*time.Second) })
+	app.Go("import", func(ctx context.Context) { workers.Import(ctx, importService, 10*time.Second) })
+	app.OnShutdown(broker.Close)
+
+	healthService := services.NewHealthService(
+		services.HealthCheck{Name: "database", Critical: true, Check: func(ctx context.Context) (any, error) {
+			stats := sqlDB.Stats()
+			return map[string]int{"open_connections": stats.OpenConnections, "in_use": stats.InUse}, sqlDB.PingContext(ctx)
+		}},
+		services.HealthCheck{Name: "migrations", Critical: true, Check: func(ctx context.Context) (any, error) {
+			status, err := database.CheckMigrations(ctx, db)
+			if status == nil {
+				return nil, err
+			}
+			return status, err
+		}},
+		services.HealthCheck{Name: "server", Critical: true, Check: func(ctx context.Context) (any, error) {
+			if app.Draining() {
+				return nil, errors.New("shutting down")
+			}
+			return nil, nil
+		}},
+		services.HealthCheck{Name: "workers", Check: func(ctx context.Context) (any, error) {
+			workers := app.Workers()
+			for name, running := range workers {
+				if !running {
+					return workers, fmt.Errorf("worker %s is not running", name)
+				}
+			}
+			return workers, nil
+		}},
+	)
+	routes.HealthRoutes(r, controllers.NewHealthController(healthService))
+	app.OnClose("database", sqlDB.Close)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := app.Run(ctx); err != nil {
+		logging.Fatal("Server stopped with an error", "error", err)
+	}
 }
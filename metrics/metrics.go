@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "home_monitor"
+
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	ReadingsIngested = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "readings_ingested_total",
+		Help:      "Readings received by source (device, import) and result (accepted, rejected, duplicate).",
+	}, []string{"source", "result"})
+
+	LoginFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "login_failures_total",
+		Help:      "Failed user logins.",
+	})
+
+	RateLimited = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_requests_total",
+		Help:      "Requests denied by a rate limit, by policy.",
+	}, []string{"policy"})
+
+	queueDepthDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "queue_depth"),
+		"Items waiting in a queue.",
+		[]string{"queue"}, nil,
+	)
+)
+
+// QueueDepth counts the items waiting in one queue. It is called on every scrape.
+type QueueDepth func(ctx context.Context) (int64, error)
+
+type queueCollector struct {
+	queues map[string]QueueDepth
+}
+
+func (c *queueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- queueDepthDesc
+}
+
+func (c *queueCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx := context.Background()
+	for name, depth := range c.queues {
+		count, err := depth(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "Counting queue failed", "queue", name, "error", err)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(queueDepthDesc, prometheus.GaugeValue, float64(count), name)
+	}
+}
+
+// Register adds the connection pool statistics of db and the depth of the given queues to the
+// default registry, next to the request and pipeline metrics declared above.
+func Register(db *sql.DB, queues map[string]QueueDepth) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, namespace))
+	prometheus.MustRegister(&queueCollector{queues: queues})
+}
@@ -0,0 +1,35 @@
+package middlewares
+
+import (
+	"home-monitor-backend/apperrors"
+	"home-monitor-backend/logging"
+	"home-monitor-backend/services"
+	"log/slog"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyAuth authenticates scrapers with an API key sent as a bearer token.
+func APIKeyAuth(apiKeyService services.APIKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if !strings.HasPrefix(authHeader, "Bearer ") {
+			c.Error(apperrors.Unauthorized("api_key_required", "API key is required"))
+			c.Abort()
+			return
+		}
+
+		apiKey, err := apiKeyService.APIKeyAuthenticate(c.Request.Context(), strings.TrimPrefix(authHeader, "Bearer "))
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Set("apiKey", apiKey)
+		c.Request = c.Request.WithContext(logging.With(c.Request.Context(), slog.String("api_key_uuid", apiKey.UUID.String())))
+
+		c.Next()
+	}
+}
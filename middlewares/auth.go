@@ -1,43 +1,52 @@
 package middlewares
 
 import (
+	"home-monitor-backend/apperrors"
+	"home-monitor-backend/logging"
 	"home-monitor-backend/utils"
-	"net/http"
+	"log/slog"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
-func Auth() gin.HandlerFunc {
+var (
+	errTokenRequired       = apperrors.Unauthorized("token_required", "Authorization header is required")
+	errAuthorizationFormat = apperrors.Unauthorized("invalid_authorization", "Invalid authorization format")
+	errInvalidToken        = apperrors.Unauthorized("invalid_token", "Invalid token")
+)
+
+func Auth(signer *utils.JWTSigner) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+			c.Error(errTokenRequired)
 			c.Abort()
 			return
 		}
 
 		if !strings.HasPrefix(authHeader, "Bearer ") {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization format"})
+			c.Error(errAuthorizationFormat)
 			c.Abort()
 			return
 		}
 
 		token := strings.TrimPrefix(authHeader, "Bearer ")
 		if token == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization format"})
+			c.Error(errAuthorizationFormat)
 			c.Abort()
 			return
 		}
 
-		userUUID, err := utils.ValidateJWT(token)
+		userUUID, err := signer.Validate(token)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			c.Error(errInvalidToken)
 			c.Abort()
 			return
 		}
 
 		c.Set("userUUID", userUUID)
+		c.Request = c.Request.WithContext(logging.With(c.Request.Context(), slog.String("user_uuid", userUUID.String())))
 
 		c.Next()
 	}
@@ -0,0 +1,80 @@
+package middlewares
+
+import (
+	"home-monitor-backend/apperrors"
+	"home-monitor-backend/config"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Headers that browser applications may send and read across origins.
+const (
+	corsAllowMethods  = "GET, POST, PUT, PATCH, DELETE"
+	corsAllowHeaders  = "Authorization, Content-Type, Accept-Language, " + RequestIDHeader
+	corsExposeHeaders = RequestIDHeader + ", Content-Disposition, Retry-After, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy"
+)
+
+var errOriginNotAllowed = apperrors.Forbidden("origin_not_allowed", "Origin is not allowed")
+
+// CORS answers preflight requests and adds the CORS headers to the responses to the allowed
+// origins. Requests of other origins are served without them, so browsers do not expose the
+// response; their preflight requests are refused.
+func CORS(cfg config.CORS) gin.HandlerFunc {
+	anyOrigin := slices.Contains(cfg.AllowedOrigins, "*")
+	maxAge := strconv.FormatInt(int64(cfg.MaxAge.Seconds()), 10)
+
+	// Responses differ by origin unless no or every origin is allowed, so caches must not share them.
+	varyByOrigin := len(cfg.AllowedOrigins) > 0 && !anyOrigin
+
+	return func(c *gin.Context) {
+		if varyByOrigin {
+			c.Writer.Header().Add("Vary", "Origin")
+		}
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		allowed := anyOrigin || slices.ContainsFunc(cfg.AllowedOrigins, func(allowedOrigin string) bool {
+			return strings.EqualFold(allowedOrigin, origin)
+		})
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		if !allowed {
+			if preflight {
+				c.Error(errOriginNotAllowed)
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
+		// Configuration validation rules out * with credentials, which browsers refuse.
+		if anyOrigin {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+		if cfg.AllowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+
+		if preflight {
+			c.Header("Access-Control-Allow-Methods", corsAllowMethods)
+			c.Header("Access-Control-Allow-Headers", corsAllowHeaders)
+			if cfg.MaxAge > 0 {
+				c.Header("Access-Control-Max-Age", maxAge)
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Header("Access-Control-Expose-Headers", corsExposeHeaders)
+		c.Next()
+	}
+}
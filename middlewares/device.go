@@ -0,0 +1,33 @@
+package middlewares
+
+import (
+	"home-monitor-backend/apperrors"
+	"home-monitor-backend/logging"
+	"home-monitor-backend/services"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+)
+
+func DeviceAuth(deviceService services.DeviceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader("X-Device-Token")
+		if token == "" {
+			c.Error(apperrors.Unauthorized("device_token_required", "X-Device-Token header is required"))
+			c.Abort()
+			return
+		}
+
+		device, err := deviceService.DeviceAuthenticate(c.Request.Context(), token)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Set("device", device)
+		c.Request = c.Request.WithContext(logging.With(c.Request.Context(), slog.String("device_uuid", device.UUID.String())))
+
+		c.Next()
+	}
+}
@@ -0,0 +1,58 @@
+package middlewares
+
+import (
+	"home-monitor-backend/apperrors"
+	"home-monitor-backend/models"
+	"home-monitor-backend/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const ProblemContentType = "application/problem+json"
+
+var problemStatus = map[apperrors.Kind]int{
+	apperrors.KindValidation:   http.StatusBadRequest,
+	apperrors.KindUnauthorized: http.StatusUnauthorized,
+	apperrors.KindForbidden:    http.StatusForbidden,
+	apperrors.KindNotFound:     http.StatusNotFound,
+	apperrors.KindConflict:     http.StatusConflict,
+	apperrors.KindRateLimited:  http.StatusTooManyRequests,
+	apperrors.KindTooLarge:     http.StatusRequestEntityTooLarge,
+}
+
+// Errors renders the last error a handler attached with c.Error as an RFC 7807 problem document,
+// unless the handler already wrote a response. Any other error is answered with a generic 500 that
+// does not reveal it; the access log still records it in full.
+func Errors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		writeProblem(c, apperrors.From(c.Errors.Last().Err))
+	}
+}
+
+func writeProblem(c *gin.Context, err *apperrors.Error) {
+	status, ok := problemStatus[err.Kind]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	acceptLanguage := c.GetHeader("Accept-Language")
+	title, detail := utils.ProblemText(status, err, acceptLanguage)
+
+	c.Header("Content-Type", ProblemContentType)
+	c.JSON(status, models.ProblemDetails{
+		Type:      "about:blank",
+		Title:     title,
+		Status:    status,
+		Detail:    detail,
+		Instance:  c.Request.URL.Path,
+		Code:      err.Code,
+		Errors:    utils.ValidationMessages(err, acceptLanguage),
+		RequestID: c.GetString("requestID"),
+	})
+}
@@ -0,0 +1,80 @@
+package middlewares
+
+import (
+	"fmt"
+	"home-monitor-backend/apperrors"
+	"home-monitor-backend/logging"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Logger writes one access log line per request with the route, status, latency and user.
+// Credentials in the query string are redacted; headers and bodies are never logged.
+func Logger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", c.Writer.Status()),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client_ip", c.ClientIP()),
+			slog.Int("bytes", c.Writer.Size()),
+		}
+		if query := redactQuery(c.Request.URL.Query()); query != "" {
+			attrs = append(attrs, slog.String("query", query))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", c.Errors.String()))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case c.Writer.Status() >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case route == "/healthz" || route == "/readyz":
+			// Probes hit these every few seconds; only failures are worth seeing by default.
+			if c.Writer.Status() == http.StatusOK {
+				level = slog.LevelDebug
+			}
+		}
+		slog.LogAttrs(c.Request.Context(), level, "Request", attrs...)
+	}
+}
+
+// Recovery turns a panic into a 500 response and logs it with the request context.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, err any) {
+		slog.ErrorContext(c.Request.Context(), "Panic recovered", "error", err, "stack", string(debug.Stack()))
+		c.Abort()
+		writeProblem(c, apperrors.Internal(fmt.Errorf("panic: %v", err)))
+	})
+}
+
+func redactQuery(values url.Values) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	redacted := make(url.Values, len(values))
+	for key, list := range values {
+		for _, value := range list {
+			redacted.Add(key, logging.Redact(key, value))
+		}
+	}
+	return redacted.Encode()
+}
@@ -0,0 +1,47 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"home-monitor-backend/apperrors"
+	"home-monitor-backend/metrics"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics records the count and latency of every request under its route pattern, so that
+// /api/devices/:uuid is one series rather than one per device.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		metrics.HTTPRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// MetricsAuth requires the given bearer token on the metrics endpoint. An empty token leaves it open.
+func MetricsAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		expected := "Bearer " + token
+		if subtle.ConstantTimeCompare([]byte(c.GetHeader("Authorization")), []byte(expected)) != 1 {
+			c.Error(apperrors.Unauthorized("invalid_metrics_token", "Invalid metrics token"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
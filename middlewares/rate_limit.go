@@ -0,0 +1,90 @@
+package middlewares
+
+import (
+	"home-monitor-backend/apperrors"
+	"home-monitor-backend/metrics"
+	"home-monitor-backend/models"
+	"home-monitor-backend/ratelimit"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var errRateLimited = apperrors.RateLimited("rate_limited", "Too many requests")
+
+// RateLimitKey returns whose bucket a request takes a token from.
+type RateLimitKey func(c *gin.Context) string
+
+// RateLimitByIP keys requests by client address. It is the only choice before authentication.
+func RateLimitByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// RateLimitByUser keys requests by the user that Auth authenticated.
+func RateLimitByUser(c *gin.Context) string {
+	if userUUID, ok := c.Get("userUUID"); ok {
+		return "user:" + userUUID.(uuid.UUID).String()
+	}
+	return RateLimitByIP(c)
+}
+
+// RateLimitByAPIKey keys requests by the API key that APIKeyAuth authenticated.
+func RateLimitByAPIKey(c *gin.Context) string {
+	if apiKey, ok := c.Get("apiKey"); ok {
+		return "api_key:" + apiKey.(*models.APIKey).UUID.String()
+	}
+	return RateLimitByIP(c)
+}
+
+// RateLimitByDevice keys requests by the device that DeviceAuth authenticated.
+func RateLimitByDevice(c *gin.Context) string {
+	if device, ok := c.Get("device"); ok {
+		return "device:" + device.(*models.Device).UUID.String()
+	}
+	return RateLimitByIP(c)
+}
+
+// RateLimit applies the named policy of the limiter, with a bucket per key. Every response states
+// the limit in the RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy
+// headers; a denied request is answered with 429 and Retry-After. If the store fails, the request
+// is let through rather than failing with it.
+func RateLimit(limiter *ratelimit.Limiter, name string, key RateLimitKey) gin.HandlerFunc {
+	policy := limiter.Policies[name]
+	if policy.Unlimited() {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	return func(c *gin.Context) {
+		result, err := limiter.Store.Take(c.Request.Context(), name+":"+key(c), policy, time.Now())
+		if err != nil {
+			slog.WarnContext(c.Request.Context(), "Rate limit check failed", "policy", name, "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", headerSeconds(result.Reset))
+		c.Header("RateLimit-Policy", strconv.Itoa(policy.Limit)+";w="+headerSeconds(policy.Period))
+		if !result.Allowed {
+			metrics.RateLimited.WithLabelValues(name).Inc()
+			c.Header("Retry-After", headerSeconds(result.RetryAfter))
+			c.Error(errRateLimited)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// headerSeconds rounds up to whole seconds, so that a client waiting that long is not too early.
+func headerSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
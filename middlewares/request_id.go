@@ -0,0 +1,41 @@
+package middlewares
+
+import (
+	"home-monitor-backend/logging"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID keeps the X-Request-ID sent by the client or a proxy, or generates one, echoes it in
+// the response and attaches it to the request context so every log line of the request carries it.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		c.Set("requestID", requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(logging.With(c.Request.Context(), slog.String("request_id", requestID)))
+
+		c.Next()
+	}
+}
+
+// validRequestID accepts short IDs of printable ASCII, so that a client cannot inject log lines.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > 128 {
+		return false
+	}
+	for _, r := range requestID {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
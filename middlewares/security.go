@@ -0,0 +1,42 @@
+package middlewares
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// The API only serves data, so its responses may load nothing. The Swagger UI is a page of its
+// own scripts and styles, including the inline script that configures it.
+const (
+	apiContentSecurityPolicy     = "default-src 'none'; frame-ancestors 'none'"
+	swaggerContentSecurityPolicy = "default-src 'self'; script-src 'self' 'unsafe-inline'; style-src 'self' 'unsafe-inline'; img-src 'self' data:; frame-ancestors 'none'"
+)
+
+// SecurityHeaders sets the headers that keep browsers from sniffing content types, framing
+// responses and sending referrers, a Content-Security-Policy, and Strict-Transport-Security
+// unless hstsMaxAge is 0. Browsers ignore Strict-Transport-Security over plain HTTP.
+func SecurityHeaders(hstsMaxAge time.Duration) gin.HandlerFunc {
+	hsts := ""
+	if hstsMaxAge > 0 {
+		hsts = "max-age=" + strconv.FormatInt(int64(hstsMaxAge.Seconds()), 10)
+	}
+
+	return func(c *gin.Context) {
+		c.Header("X-Content-Type-Options", "nosniff")
+		c.Header("X-Frame-Options", "DENY")
+		c.Header("Referrer-Policy", "no-referrer")
+		if strings.HasPrefix(c.Request.URL.Path, "/swagger/") {
+			c.Header("Content-Security-Policy", swaggerContentSecurityPolicy)
+		} else {
+			c.Header("Content-Security-Policy", apiContentSecurityPolicy)
+		}
+		if hsts != "" {
+			c.Header("Strict-Transport-Security", hsts)
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"home-monitor-backend/config"
+	"home-monitor-backend/database"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/golang-migrate/migrate/v4"
+)
+
+// runMigrate implements the migrate command. The server applies pending migrations on start;
+// this command is for rolling back, inspecting and repairing the schema.
+func runMigrate(cfg config.Database, args []string) error {
+	action, args, err := subcommand("migrate", "up|down|status|force", args)
+	if err != nil {
+		return err
+	}
+
+	flags := flag.NewFlagSet("migrate "+action, flag.ExitOnError)
+	var steps *int
+	var all *bool
+	switch action {
+	case "up":
+		steps = flags.Int("steps", 0, "number of migrations to apply; all pending when 0")
+	case "down":
+		steps = flags.Int("steps", 1, "number of migrations to roll back")
+		all = flags.Bool("all", false, "roll back every migration, dropping all tables")
+	case "status":
+	case "force":
+		flags.Usage = func() {
+			fmt.Fprintln(flags.Output(), "usage: migrate force VERSION")
+			fmt.Fprintln(flags.Output(), "Records VERSION as applied and clears the dirty flag without running anything.")
+		}
+	default:
+		return fmt.Errorf("unknown migrate action %q, expected up, down, status or force", action)
+	}
+	flags.Parse(args)
+
+	m, err := database.Migrator(cfg)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	switch action {
+	case "up":
+		if *steps > 0 {
+			err = m.Steps(*steps)
+		} else {
+			err = m.Up()
+		}
+	case "down":
+		if *all {
+			err = m.Down()
+		} else {
+			err = m.Steps(-*steps)
+		}
+	case "force":
+		if flags.NArg() != 1 {
+			flags.Usage()
+			return errors.New("VERSION is required")
+		}
+		version, parseErr := strconv.Atoi(flags.Arg(0))
+		if parseErr != nil {
+			return fmt.Errorf("invalid version %q", flags.Arg(0))
+		}
+		err = m.Force(version)
+	}
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return printMigrationStatus(m, cfg.Driver)
+}
+
+func printMigrationStatus(m *migrate.Migrate, driver string) error {
+	version, dirty, err := m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return err
+	}
+	latest, err := database.LatestMigration(driver)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "driver\t%s\n", driver)
+	fmt.Fprintf(w, "version\t%d\n", version)
+	fmt.Fprintf(w, "latest\t%d\n", latest)
+	fmt.Fprintf(w, "dirty\t%t\n", dirty)
+	return w.Flush()
+}
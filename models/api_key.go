@@ -0,0 +1,59 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// APIKey gives read-only access to telemetry for scrapers such as Prometheus. Only the hash of
+// the key is stored. A key with a home only sees the owner's devices in that home.
+type APIKey struct {
+	ID         uint       `gorm:"primaryKey" json:"id" validate:"required"`
+	UUID       uuid.UUID  `gorm:"unique" json:"uuid" validate:"required,uuid"`
+	UserID     uint       `gorm:"not null;index" json:"user_id" validate:"required"`
+	User       *User      `gorm:"foreignKey:UserID" json:"-"`
+	Name       string     `gorm:"not null" json:"name" validate:"required,lte=100"`
+	Home       string     `gorm:"not null" json:"home" validate:"lte=100"`
+	KeyHash    string     `gorm:"unique;not null" json:"-"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+type APIKeyCreateRequest struct {
+	Name string `json:"name" binding:"required,min=1,max=100"`
+	Home string `json:"home" binding:"max=100"`
+}
+
+type APIKeyResponse struct {
+	UUID       uuid.UUID  `json:"uuid" validate:"required,uuid"`
+	Name       string     `json:"name" validate:"required,lte=100"`
+	Home       string     `json:"home"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at" validate:"required"`
+}
+
+type APIKeyCreateResponse struct {
+	UUID      uuid.UUID `json:"uuid" validate:"required,uuid"`
+	Name      string    `json:"name" validate:"required,lte=100"`
+	Home      string    `json:"home"`
+	Key       string    `json:"key" validate:"required"`
+	CreatedAt time.Time `json:"created_at" validate:"required"`
+}
+
+func (k *APIKey) BeforeCreate(tx *gorm.DB) (err error) {
+	if k.UUID == uuid.Nil {
+		k.UUID = uuid.New()
+	}
+
+	if k.KeyHash == "" {
+		return errors.New("api key hash cannot be empty")
+	}
+
+	k.CreatedAt = time.Now()
+	k.UpdatedAt = time.Now()
+	return nil
+}
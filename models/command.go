@@ -0,0 +1,95 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CommandStatus string
+
+const (
+	CommandStatusPending CommandStatus = "pending"
+	CommandStatusSent    CommandStatus = "sent"
+	CommandStatusAcked   CommandStatus = "acked"
+	CommandStatusFailed  CommandStatus = "failed"
+	CommandStatusExpired CommandStatus = "expired"
+)
+
+const (
+	CommandDefaultTimeout = 5 * time.Minute
+	CommandMaxTimeout     = 24 * time.Hour
+)
+
+type Command struct {
+	ID          uint            `gorm:"primaryKey" json:"id" validate:"required"`
+	UUID        uuid.UUID       `gorm:"unique" json:"uuid" validate:"required,uuid"`
+	DeviceID    uint            `gorm:"not null;index" json:"device_id" validate:"required"`
+	UserID      uint            `gorm:"not null" json:"user_id" validate:"required"`
+	Name        string          `gorm:"not null" json:"name" validate:"required,lte=100"`
+	Params      json.RawMessage `gorm:"type:json" json:"params"`
+	Status      CommandStatus   `gorm:"type:VARCHAR(16);not null" json:"status"`
+	Result      json.RawMessage `gorm:"type:json" json:"result"`
+	Error       string          `json:"error"`
+	ExpiresAt   time.Time       `gorm:"not null" json:"expires_at"`
+	SentAt      *time.Time      `json:"sent_at"`
+	CompletedAt *time.Time      `json:"completed_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+type CommandCreateRequest struct {
+	Name           string          `json:"name" binding:"required,min=1,max=100"`
+	Params         json.RawMessage `json:"params" swaggertype:"object"`
+	TimeoutSeconds int             `json:"timeout_seconds" binding:"omitempty,min=1,max=86400"`
+}
+
+type CommandAckRequest struct {
+	Status CommandStatus   `json:"status" binding:"required,oneof=acked failed"`
+	Result json.RawMessage `json:"result" swaggertype:"object"`
+	Error  string          `json:"error" binding:"omitempty,max=1000"`
+}
+
+type CommandResponse struct {
+	UUID        uuid.UUID       `json:"uuid" validate:"required,uuid"`
+	Name        string          `json:"name" validate:"required,lte=100"`
+	Params      json.RawMessage `json:"params" swaggertype:"object"`
+	Status      CommandStatus   `json:"status" validate:"required"`
+	Result      json.RawMessage `json:"result" swaggertype:"object"`
+	Error       string          `json:"error,omitempty"`
+	ExpiresAt   time.Time       `json:"expires_at" validate:"required"`
+	SentAt      *time.Time      `json:"sent_at"`
+	CompletedAt *time.Time      `json:"completed_at"`
+	CreatedAt   time.Time       `json:"created_at" validate:"required"`
+	UpdatedAt   time.Time       `json:"updated_at" validate:"required"`
+}
+
+func (c *Command) BeforeCreate(tx *gorm.DB) (err error) {
+	if c.UUID == uuid.Nil {
+		c.UUID = uuid.New()
+	}
+
+	if c.Name == "" {
+		return errors.New("command name cannot be empty")
+	}
+
+	if c.Status == "" {
+		c.Status = CommandStatusPending
+	}
+
+	c.CreatedAt = time.Now()
+	c.UpdatedAt = time.Now()
+
+	if c.ExpiresAt.IsZero() {
+		c.ExpiresAt = c.CreatedAt.Add(CommandDefaultTimeout)
+	}
+	return nil
+}
+
+// IsFinal reports whether the command has reached a state it can no longer leave.
+func (c *Command) IsFinal() bool {
+	return c.Status == CommandStatusAcked || c.Status == CommandStatusFailed || c.Status == CommandStatusExpired
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Device struct {
+	ID           uint        `gorm:"primaryKey" json:"id" validate:"required"`
+	UUID         uuid.UUID   `gorm:"unique" json:"uuid" validate:"required,uuid"`
+	UserID       uint        `gorm:"not null" json:"user_id" validate:"required"`
+	DeviceTypeID *uint       `json:"device_type_id"`
+	DeviceType   *DeviceType `gorm:"foreignKey:DeviceTypeID" json:"device_type,omitempty"`
+	Name         string      `gorm:"not null" json:"name" validate:"required,lte=255"`
+	Home         string      `gorm:"not null" json:"home" validate:"lte=100"`
+	Room         string      `gorm:"not null" json:"room" validate:"lte=100"`
+	TokenHash    string      `gorm:"unique;not null" json:"-"`
+	LastSeenAt   *time.Time  `json:"last_seen_at"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+}
+
+type DeviceCreateRequest struct {
+	Name           string     `json:"name" binding:"required,min=1,max=255"`
+	DeviceTypeUUID *uuid.UUID `json:"device_type_uuid"`
+	Home           string     `json:"home" binding:"max=100"`
+	Room           string     `json:"room" binding:"max=100"`
+}
+
+// DeviceUpdateRequest changes the fields that are set. Home and room are free-form labels used to
+// group devices, e.g. in Prometheus exports.
+type DeviceUpdateRequest struct {
+	Name *string `json:"name" binding:"omitempty,min=1,max=255"`
+	Home *string `json:"home" binding:"omitempty,max=100"`
+	Room *string `json:"room" binding:"omitempty,max=100"`
+}
+
+type DeviceResponse struct {
+	UUID           uuid.UUID  `json:"uuid" validate:"required,uuid"`
+	Name           string     `json:"name" validate:"required,lte=255"`
+	DeviceTypeUUID *uuid.UUID `json:"device_type_uuid"`
+	Home           string     `json:"home"`
+	Room           string     `json:"room"`
+	LastSeenAt     *time.Time `json:"last_seen_at"`
+	CreatedAt      time.Time  `json:"created_at" validate:"required"`
+	UpdatedAt      time.Time  `json:"updated_at" validate:"required"`
+}
+
+type DeviceCreateResponse struct {
+	UUID           uuid.UUID  `json:"uuid" validate:"required,uuid"`
+	Name           string     `json:"name" validate:"required,lte=255"`
+	DeviceTypeUUID *uuid.UUID `json:"device_type_uuid"`
+	Home           string     `json:"home"`
+	Room           string     `json:"room"`
+	Token          string     `json:"token" validate:"required"`
+	CreatedAt      time.Time  `json:"created_at" validate:"required"`
+	UpdatedAt      time.Time  `json:"updated_at" validate:"required"`
+}
+
+func (d *Device) BeforeCreate(tx *gorm.DB) (err error) {
+	if d.UUID == uuid.Nil {
+		d.UUID = uuid.New()
+	}
+
+	if d.Name == "" {
+		return errors.New("device name cannot be empty")
+	}
+
+	if d.TokenHash == "" {
+		return errors.New("device token cannot be empty")
+	}
+
+	d.CreatedAt = time.Now()
+	d.UpdatedAt = time.Now()
+	return nil
+}
+
+// Capabilities returns the declared capabilities of the device, or nil for untyped devices
+// whose readings and commands are not validated.
+func (d *Device) Capabilities() *DeviceCapabilities {
+	if d.DeviceType == nil {
+		return nil
+	}
+	return &d.DeviceType.Capabilities
+}
+
+func (d *Device) DeviceTypeUUID() *uuid.UUID {
+	if d.DeviceType == nil {
+		return nil
+	}
+	return &d.DeviceType.UUID
+}
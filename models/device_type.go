@@ -0,0 +1,245 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DataType string
+
+const (
+	DataTypeNumber  DataType = "number"
+	DataTypeInteger DataType = "integer"
+	DataTypeBoolean DataType = "boolean"
+	DataTypeString  DataType = "string"
+)
+
+type OutOfRangePolicy string
+
+const (
+	OutOfRangeReject OutOfRangePolicy = "reject"
+	OutOfRangeFlag   OutOfRangePolicy = "flag"
+)
+
+type DeviceType struct {
+	ID           uint               `gorm:"primaryKey" json:"id" validate:"required"`
+	UUID         uuid.UUID          `gorm:"unique" json:"uuid" validate:"required,uuid"`
+	Name         string             `gorm:"unique;not null" json:"name" validate:"required,lte=100"`
+	Description  string             `json:"description" validate:"lte=1000"`
+	Capabilities DeviceCapabilities `gorm:"type:json;serializer:json" json:"capabilities"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+}
+
+type DeviceCapabilities struct {
+	Metrics  []MetricSchema  `json:"metrics" binding:"omitempty,dive"`
+	Commands []CommandSchema `json:"commands" binding:"omitempty,dive"`
+}
+
+type MetricSchema struct {
+	Name       string           `json:"name" binding:"required,min=1,max=100"`
+	Unit       string           `json:"unit,omitempty" binding:"max=32"`
+	DataType   DataType         `json:"data_type" binding:"required,oneof=number integer boolean"`
+	Min        *float64         `json:"min,omitempty"`
+	Max        *float64         `json:"max,omitempty"`
+	Precision  *int             `json:"precision,omitempty" binding:"omitempty,min=0,max=10"`
+	OutOfRange OutOfRangePolicy `json:"out_of_range,omitempty" binding:"omitempty,oneof=reject flag"`
+}
+
+type CommandSchema struct {
+	Name   string        `json:"name" binding:"required,min=1,max=100"`
+	Params []ParamSchema `json:"params,omitempty" binding:"omitempty,dive"`
+}
+
+type ParamSchema struct {
+	Name     string   `json:"name" binding:"required,min=1,max=100"`
+	DataType DataType `json:"data_type" binding:"required,oneof=number integer boolean string"`
+	Required bool     `json:"required"`
+	Min      *float64 `json:"min,omitempty"`
+	Max      *float64 `json:"max,omitempty"`
+	Enum     []string `json:"enum,omitempty"`
+}
+
+type DeviceTypeCreateRequest struct {
+	Name         string             `json:"name" binding:"required,min=1,max=100"`
+	Description  string             `json:"description" binding:"max=1000"`
+	Capabilities DeviceCapabilities `json:"capabilities"`
+}
+
+type DeviceTypeResponse struct {
+	UUID         uuid.UUID          `json:"uuid" validate:"required,uuid"`
+	Name         string             `json:"name" validate:"required,lte=100"`
+	Description  string             `json:"description"`
+	Capabilities DeviceCapabilities `json:"capabilities"`
+	CreatedAt    time.Time          `json:"created_at" validate:"required"`
+	UpdatedAt    time.Time          `json:"updated_at" validate:"required"`
+}
+
+func (t *DeviceType) BeforeCreate(tx *gorm.DB) (err error) {
+	if t.UUID == uuid.Nil {
+		t.UUID = uuid.New()
+	}
+
+	if t.Name == "" {
+		return errors.New("device type name cannot be empty")
+	}
+
+	t.CreatedAt = time.Now()
+	t.UpdatedAt = time.Now()
+	return nil
+}
+
+// Validate checks the schema itself: names must be unique and ranges must not be inverted.
+func (c *DeviceCapabilities) Validate() error {
+	metrics := make(map[string]bool)
+	for _, metric := range c.Metrics {
+		if metrics[metric.Name] {
+			return fmt.Errorf("duplicate metric %q", metric.Name)
+		}
+		metrics[metric.Name] = true
+
+		if metric.Min != nil && metric.Max != nil && *metric.Min > *metric.Max {
+			return fmt.Errorf("metric %q has min greater than max", metric.Name)
+		}
+	}
+
+	commands := make(map[string]bool)
+	for _, command := range c.Commands {
+		if commands[command.Name] {
+			return fmt.Errorf("duplicate command %q", command.Name)
+		}
+		commands[command.Name] = true
+
+		params := make(map[string]bool)
+		for _, param := range command.Params {
+			if params[param.Name] {
+				return fmt.Errorf("command %q has duplicate param %q", command.Name, param.Name)
+			}
+			params[param.Name] = true
+
+			if param.Min != nil && param.Max != nil && *param.Min > *param.Max {
+				return fmt.Errorf("command %q param %q has min greater than max", command.Name, param.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *DeviceCapabilities) Metric(name string) *MetricSchema {
+	for i := range c.Metrics {
+		if c.Metrics[i].Name == name {
+			return &c.Metrics[i]
+		}
+	}
+	return nil
+}
+
+func (c *DeviceCapabilities) Command(name string) *CommandSchema {
+	for i := range c.Commands {
+		if c.Commands[i].Name == name {
+			return &c.Commands[i]
+		}
+	}
+	return nil
+}
+
+// Validate converts a raw JSON reading value to the stored number. It returns flagged when the
+// value is outside the declared range and the metric flags instead of rejecting.
+func (m *MetricSchema) Validate(value any) (float64, bool, error) {
+	var number float64
+	switch m.DataType {
+	case DataTypeBoolean:
+		b, ok := value.(bool)
+		if !ok {
+			return 0, false, errors.New("value must be a boolean")
+		}
+		if b {
+			number = 1
+		}
+		return number, false, nil
+	case DataTypeInteger:
+		n, ok := value.(float64)
+		if !ok || n != math.Trunc(n) {
+			return 0, false, errors.New("value must be an integer")
+		}
+		number = n
+	default:
+		n, ok := value.(float64)
+		if !ok {
+			return 0, false, errors.New("value must be a number")
+		}
+		number = n
+	}
+
+	if m.Precision != nil {
+		scale := math.Pow(10, float64(*m.Precision))
+		number = math.Round(number*scale) / scale
+	}
+
+	if (m.Min != nil && number < *m.Min) || (m.Max != nil && number > *m.Max) {
+		if m.OutOfRange == OutOfRangeFlag {
+			return number, true, nil
+		}
+		return 0, false, errors.New("value is out of range")
+	}
+	return number, false, nil
+}
+
+// ValidateParams checks command parameters against the schema. Unknown parameters are rejected.
+func (c *CommandSchema) ValidateParams(params map[string]any) error {
+	for name := range params {
+		if !slices.ContainsFunc(c.Params, func(p ParamSchema) bool { return p.Name == name }) {
+			return fmt.Errorf("unknown param %q", name)
+		}
+	}
+
+	for _, param := range c.Params {
+		value, exists := params[param.Name]
+		if !exists || value == nil {
+			if param.Required {
+				return fmt.Errorf("param %q is required", param.Name)
+			}
+			continue
+		}
+
+		if err := param.validate(value); err != nil {
+			return fmt.Errorf("param %q: %w", param.Name, err)
+		}
+	}
+	return nil
+}
+
+func (p *ParamSchema) validate(value any) error {
+	switch p.DataType {
+	case DataTypeBoolean:
+		if _, ok := value.(bool); !ok {
+			return errors.New("must be a boolean")
+		}
+	case DataTypeString:
+		s, ok := value.(string)
+		if !ok {
+			return errors.New("must be a string")
+		}
+		if len(p.Enum) > 0 && !slices.Contains(p.Enum, s) {
+			return fmt.Errorf("must be one of %v", p.Enum)
+		}
+	default:
+		n, ok := value.(float64)
+		if !ok {
+			return errors.New("must be a number")
+		}
+		if p.DataType == DataTypeInteger && n != math.Trunc(n) {
+			return errors.New("must be an integer")
+		}
+		if (p.Min != nil && n < *p.Min) || (p.Max != nil && n > *p.Max) {
+			return errors.New("is out of range")
+		}
+	}
+	return nil
+}
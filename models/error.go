@@ -1,5 +1,15 @@
 package models
 
-type ErrorResponse struct {
-	Error any `json:"error"`
+// ProblemDetails is the body of every error response, an RFC 7807 problem document extended with
+// a stable machine-readable code and, for validation errors, a message per invalid field in the
+// language negotiated from Accept-Language.
+type ProblemDetails struct {
+	Type      string            `json:"type"`
+	Title     string            `json:"title"`
+	Status    int               `json:"status"`
+	Detail    string            `json:"detail,omitempty"`
+	Instance  string            `json:"instance,omitempty"`
+	Code      string            `json:"code"`
+	Errors    map[string]string `json:"errors,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
 }
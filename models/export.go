@@ -0,0 +1,93 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ExportFormat string
+
+const (
+	ExportFormatCSV     ExportFormat = "csv"
+	ExportFormatParquet ExportFormat = "parquet"
+)
+
+type ExportStatus string
+
+const (
+	ExportStatusPending   ExportStatus = "pending"
+	ExportStatusRunning   ExportStatus = "running"
+	ExportStatusCompleted ExportStatus = "completed"
+	ExportStatusFailed    ExportStatus = "failed"
+)
+
+const (
+	ExportMaxDevices = 100
+
+	// ExportJobTTL is how long the file of a finished export job is kept for download.
+	ExportJobTTL = 7 * 24 * time.Hour
+)
+
+func (f ExportFormat) ContentType() string {
+	if f == ExportFormatParquet {
+		return "application/vnd.apache.parquet"
+	}
+	return "text/csv"
+}
+
+type ExportJob struct {
+	ID          uint                 `gorm:"primaryKey" json:"id" validate:"required"`
+	UUID        uuid.UUID            `gorm:"unique" json:"uuid" validate:"required,uuid"`
+	UserID      uint                 `gorm:"not null;index" json:"user_id" validate:"required"`
+	User        *User                `gorm:"foreignKey:UserID" json:"-"`
+	Request     ReadingExportRequest `gorm:"type:json;serializer:json" json:"request"`
+	Status      ExportStatus         `gorm:"type:VARCHAR(16);not null" json:"status"`
+	RowCount    int64                `gorm:"not null" json:"row_count"`
+	FilePath    string               `json:"-"`
+	Error       string               `json:"error"`
+	StartedAt   *time.Time           `json:"started_at"`
+	CompletedAt *time.Time           `json:"completed_at"`
+	ExpiresAt   *time.Time           `json:"expires_at"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+}
+
+// ReadingExportRequest selects the readings to export. It is bound from the query string for
+// streamed exports and from the JSON body for export jobs.
+type ReadingExportRequest struct {
+	DeviceUUIDs []string     `form:"device_uuid" json:"device_uuids" binding:"required,min=1,max=100,dive,uuid"`
+	Metrics     []string     `form:"metric" json:"metrics" binding:"omitempty,max=100,dive,min=1,max=100"`
+	From        time.Time    `form:"from" json:"from" binding:"required"`
+	To          time.Time    `form:"to" json:"to" binding:"required"`
+	Format      ExportFormat `form:"format" json:"format" binding:"omitempty,oneof=csv parquet"`
+	Units       string       `form:"units" json:"units" binding:"omitempty,oneof=preferred"`
+}
+
+type ExportJobResponse struct {
+	UUID        uuid.UUID            `json:"uuid" validate:"required,uuid"`
+	Status      ExportStatus         `json:"status" validate:"required"`
+	Request     ReadingExportRequest `json:"request"`
+	RowCount    int64                `json:"row_count"`
+	Error       string               `json:"error,omitempty"`
+	DownloadURL string               `json:"download_url,omitempty"`
+	StartedAt   *time.Time           `json:"started_at"`
+	CompletedAt *time.Time           `json:"completed_at"`
+	ExpiresAt   *time.Time           `json:"expires_at"`
+	CreatedAt   time.Time            `json:"created_at" validate:"required"`
+}
+
+func (j *ExportJob) BeforeCreate(tx *gorm.DB) (err error) {
+	if j.UUID == uuid.Nil {
+		j.UUID = uuid.New()
+	}
+
+	if j.Status == "" {
+		j.Status = ExportStatusPending
+	}
+
+	j.CreatedAt = time.Now()
+	j.UpdatedAt = time.Now()
+	return nil
+}
@@ -0,0 +1,27 @@
+package models
+
+type HealthStatus string
+
+const (
+	HealthStatusOK          HealthStatus = "ok"
+	HealthStatusDegraded    HealthStatus = "degraded"
+	HealthStatusUnavailable HealthStatus = "unavailable"
+	HealthStatusFail        HealthStatus = "fail"
+)
+
+type HealthResponse struct {
+	Status HealthStatus `json:"status"`
+}
+
+type ReadinessResponse struct {
+	Status HealthStatus                 `json:"status"`
+	Checks map[string]HealthCheckResult `json:"checks"`
+}
+
+type HealthCheckResult struct {
+	Status     HealthStatus `json:"status"`
+	Critical   bool         `json:"critical"`
+	DurationMs float64      `json:"duration_ms"`
+	Details    any          `json:"details,omitempty"`
+	Error      string       `json:"error,omitempty"`
+}
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ImportStatus string
+
+const (
+	ImportStatusPending   ImportStatus = "pending"
+	ImportStatusRunning   ImportStatus = "running"
+	ImportStatusCompleted ImportStatus = "completed"
+	ImportStatusFailed    ImportStatus = "failed"
+)
+
+const (
+	ImportTimestampRFC3339 = "rfc3339"
+	ImportTimestampUnix    = "unix"
+	ImportTimestampUnixMs  = "unix_ms"
+
+	// ImportMaxRowErrors caps the row errors kept on a job; the error count keeps going up.
+	ImportMaxRowErrors = 1000
+)
+
+type ImportJob struct {
+	ID             uint             `gorm:"primaryKey" json:"id" validate:"required"`
+	UUID           uuid.UUID        `gorm:"unique" json:"uuid" validate:"required,uuid"`
+	UserID         uint             `gorm:"not null;index" json:"user_id" validate:"required"`
+	DeviceID       uint             `gorm:"not null;index" json:"device_id" validate:"required"`
+	Device         *Device          `gorm:"foreignKey:DeviceID" json:"-"`
+	Mapping        ImportMapping    `gorm:"type:json;serializer:json" json:"mapping"`
+	Status         ImportStatus     `gorm:"type:VARCHAR(16);not null" json:"status"`
+	ClaimedBy      string           `gorm:"not null" json:"-"`
+	HeartbeatAt    *time.Time       `json:"-"`
+	FilePath       string           `gorm:"not null" json:"-"`
+	ByteOffset     int64            `gorm:"not null" json:"-"`
+	RowsProcessed  int64            `gorm:"not null" json:"rows_processed"`
+	ReadingsAdded  int64            `gorm:"not null" json:"readings_added"`
+	DuplicateCount int64            `gorm:"not null" json:"duplicate_count"`
+	ErrorCount     int64            `gorm:"not null" json:"error_count"`
+	RowErrors      []ImportRowError `gorm:"type:json;serializer:json" json:"row_errors"`
+	FirstAt        *time.Time       `json:"first_at"`
+	LastAt         *time.Time       `json:"last_at"`
+	Error          string           `json:"error"`
+	StartedAt      *time.Time       `json:"started_at"`
+	CompletedAt    *time.Time       `json:"completed_at"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+}
+
+// ImportMapping describes how the columns of a CSV file map to readings. The file must have a
+// header row; columns are referenced by their header name.
+type ImportMapping struct {
+	Delimiter       string                `json:"delimiter,omitempty" binding:"omitempty,len=1"`
+	TimestampColumn string                `json:"timestamp_column" binding:"required,min=1,max=100"`
+	TimestampFormat string                `json:"timestamp_format,omitempty" binding:"max=100"`
+	Timezone        string                `json:"timezone,omitempty" binding:"max=64"`
+	Metrics         []ImportMetricMapping `json:"metrics" binding:"required,min=1,max=100,dive"`
+}
+
+// ImportMetricMapping reads one metric from a column. Unit is the unit of the values in the file
+// when it differs from the unit declared by the device type.
+type ImportMetricMapping struct {
+	Column string `json:"column" binding:"required,min=1,max=100"`
+	Metric string `json:"metric" binding:"required,min=1,max=100"`
+	Unit   string `json:"unit,omitempty" binding:"max=32"`
+}
+
+type ImportRowError struct {
+	Row    int64  `json:"row"`
+	Column string `json:"column,omitempty"`
+	Error  string `json:"error"`
+}
+
+type ImportJobResponse struct {
+	UUID           uuid.UUID        `json:"uuid" validate:"required,uuid"`
+	DeviceUUID     uuid.UUID        `json:"device_uuid" validate:"required,uuid"`
+	Status         ImportStatus     `json:"status" validate:"required"`
+	Mapping        ImportMapping    `json:"mapping"`
+	RowsProcessed  int64            `json:"rows_processed"`
+	ReadingsAdded  int64            `json:"readings_added"`
+	DuplicateCount int64            `json:"duplicate_count"`
+	ErrorCount     int64            `json:"error_count"`
+	RowErrors      []ImportRowError `json:"row_errors"`
+	Error          string           `json:"error,omitempty"`
+	StartedAt      *time.Time       `json:"started_at"`
+	CompletedAt    *time.Time       `json:"completed_at"`
+	CreatedAt      time.Time        `json:"created_at" validate:"required"`
+}
+
+func (j *ImportJob) BeforeCreate(tx *gorm.DB) (err error) {
+	if j.UUID == uuid.Nil {
+		j.UUID = uuid.New()
+	}
+
+	if j.Status == "" {
+		j.Status = ImportStatusPending
+	}
+
+	j.CreatedAt = time.Now()
+	j.UpdatedAt = time.Now()
+	return nil
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// RateLimitBucket is a token bucket of a rate limiter that shares its buckets through the
+// database. Version increases with every update, so that concurrent updates can be detected.
+type RateLimitBucket struct {
+	Key        string    `gorm:"column:bucket_key;primaryKey"`
+	Tokens     float64   `gorm:"not null"`
+	RefilledAt time.Time `gorm:"not null"`
+	ExpiresAt  time.Time `gorm:"not null;index"`
+	Version    uint      `gorm:"not null"`
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ReadingIngestMaxBatch = 1000
+	ReadingDefaultLimit   = 1000
+
+	// ReadingUnitsPreferred converts reading values and times to the preferences of the requesting user.
+	ReadingUnitsPreferred = "preferred"
+)
+
+type Reading struct {
+	ID         uint64    `gorm:"primaryKey" json:"id" validate:"required"`
+	DeviceID   uint      `gorm:"not null" json:"device_id" validate:"required"`
+	Metric     string    `gorm:"not null" json:"metric" validate:"required,lte=100"`
+	Value      float64   `gorm:"not null" json:"value"`
+	Flagged    bool      `gorm:"not null" json:"flagged"`
+	RecordedAt time.Time `gorm:"not null" json:"recorded_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type ReadingInput struct {
+	Metric     string     `json:"metric" binding:"required,min=1,max=100"`
+	Value      any        `json:"value" swaggertype:"number"`
+	RecordedAt *time.Time `json:"recorded_at"`
+}
+
+type ReadingIngestRequest struct {
+	Readings []ReadingInput `json:"readings" binding:"required,min=1,max=1000,dive"`
+}
+
+type ReadingIngestError struct {
+	Index  int    `json:"index"`
+	Metric string `json:"metric"`
+	Error  string `json:"error"`
+}
+
+type ReadingIngestResponse struct {
+	Accepted int                  `json:"accepted"`
+	Flagged  int                  `json:"flagged"`
+	Rejected []ReadingIngestError `json:"rejected"`
+}
+
+type ReadingQuery struct {
+	Metric string    `form:"metric" binding:"omitempty,max=100"`
+	From   time.Time `form:"from"`
+	To     time.Time `form:"to"`
+	Limit  int       `form:"limit" binding:"omitempty,min=1,max=10000"`
+	Units  string    `form:"units" binding:"omitempty,oneof=preferred"`
+}
+
+type ReadingResponse struct {
+	DeviceUUID uuid.UUID `json:"device_uuid" validate:"required,uuid"`
+	Metric     string    `json:"metric" validate:"required"`
+	Value      float64   `json:"value"`
+	Unit       string    `json:"unit,omitempty"`
+	Flagged    bool      `json:"flagged"`
+	RecordedAt time.Time `json:"recorded_at" validate:"required"`
+}
+
+// ReadingLatest is the most recent value of one metric of a device, with the labels it is
+// grouped by on dashboards.
+type ReadingLatest struct {
+	DeviceUUID uuid.UUID `json:"device_uuid"`
+	DeviceName string    `json:"device_name"`
+	Home       string    `json:"home"`
+	Room       string    `json:"room"`
+	Metric     string    `json:"metric"`
+	Value      float64   `json:"value"`
+	Unit       string    `json:"unit,omitempty"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
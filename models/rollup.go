@@ -0,0 +1,165 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RollupTier string
+
+const (
+	RollupTierRaw    RollupTier = "raw"
+	RollupTierMinute RollupTier = "1m"
+	RollupTierHour   RollupTier = "1h"
+	RollupTierDay    RollupTier = "1d"
+)
+
+// RollupTiers lists the materialized tiers from finest to coarsest. Each tier is built from the one before it.
+var RollupTiers = []RollupTier{RollupTierMinute, RollupTierHour, RollupTierDay}
+
+const ReadingAggregateMaxBuckets = 10000
+
+func (t RollupTier) Duration() time.Duration {
+	switch t {
+	case RollupTierMinute:
+		return time.Minute
+	case RollupTierHour:
+		return time.Hour
+	case RollupTierDay:
+		return 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// Finer returns the tier a tier is built from, raw readings for the finest.
+func (t RollupTier) Finer() RollupTier {
+	for i, tier := range RollupTiers {
+		if tier == t && i > 0 {
+			return RollupTiers[i-1]
+		}
+	}
+	return RollupTierRaw
+}
+
+type ReadingRollup struct {
+	ID          uint64     `gorm:"primaryKey" json:"id"`
+	DeviceID    uint       `gorm:"not null;uniqueIndex:idx_reading_rollups_bucket" json:"device_id"`
+	Metric      string     `gorm:"not null;uniqueIndex:idx_reading_rollups_bucket" json:"metric"`
+	Tier        RollupTier `gorm:"type:VARCHAR(4);not null;uniqueIndex:idx_reading_rollups_bucket" json:"tier"`
+	BucketStart time.Time  `gorm:"not null;uniqueIndex:idx_reading_rollups_bucket" json:"bucket_start"`
+	Min         float64    `gorm:"not null" json:"min"`
+	Max         float64    `gorm:"not null" json:"max"`
+	Sum         float64    `gorm:"not null" json:"sum"`
+	Count       int64      `gorm:"not null" json:"count"`
+	Last        float64    `gorm:"not null" json:"last"`
+	LastAt      time.Time  `gorm:"not null" json:"last_at"`
+}
+
+// Merge adds the readings summarized by source to the bucket.
+func (r *ReadingRollup) Merge(source *ReadingRollup) {
+	if r.Count == 0 {
+		r.Min, r.Max, r.Last, r.LastAt = source.Min, source.Max, source.Last, source.LastAt
+	}
+	r.Min = min(r.Min, source.Min)
+	r.Max = max(r.Max, source.Max)
+	r.Sum += source.Sum
+	r.Count += source.Count
+	if !source.LastAt.Before(r.LastAt) {
+		r.Last = source.Last
+		r.LastAt = source.LastAt
+	}
+}
+
+// RollupState records up to which instant a tier has been materialized.
+type RollupState struct {
+	Tier       RollupTier `gorm:"primaryKey;type:VARCHAR(4)" json:"tier"`
+	RolledUpTo time.Time  `gorm:"not null" json:"rolled_up_to"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// RetentionPolicy says how many days each tier is kept for the devices of a home or of a type.
+// A policy with neither Home nor DeviceTypeID is the default; zero days keeps the tier forever.
+type RetentionPolicy struct {
+	ID           uint        `gorm:"primaryKey" json:"id"`
+	DeviceTypeID *uint       `gorm:"unique" json:"device_type_id"`
+	Home         *string     `gorm:"unique" json:"home" validate:"omitempty,lte=100"`
+	DeviceType   *DeviceType `gorm:"foreignKey:DeviceTypeID" json:"device_type,omitempty"`
+	RawDays      int         `gorm:"not null" json:"raw_days"`
+	MinuteDays   int         `gorm:"not null" json:"minute_days"`
+	HourDays     int         `gorm:"not null" json:"hour_days"`
+	DayDays      int         `gorm:"not null" json:"day_days"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+}
+
+func (p *RetentionPolicy) Days(tier RollupTier) int {
+	switch tier {
+	case RollupTierRaw:
+		return p.RawDays
+	case RollupTierMinute:
+		return p.MinuteDays
+	case RollupTierHour:
+		return p.HourDays
+	case RollupTierDay:
+		return p.DayDays
+	default:
+		return 0
+	}
+}
+
+// RetentionScope selects the devices a retention policy applies to: the devices of one home, the
+// devices of one type, or for the default policy every device whose type has no policy of its own.
+// A home policy takes precedence, so devices in the excluded homes are left out of the others.
+type RetentionScope struct {
+	Home                 *string
+	DeviceTypeID         *uint
+	ExcludeHomes         []string
+	ExcludeDeviceTypeIDs []uint
+}
+
+type RetentionPolicyRequest struct {
+	DeviceTypeUUID *uuid.UUID `json:"device_type_uuid"`
+	Home           *string    `json:"home" binding:"omitempty,min=1,max=100"`
+	RawDays        int        `json:"raw_days" binding:"min=0,max=36500"`
+	MinuteDays     int        `json:"minute_days" binding:"min=0,max=36500"`
+	HourDays       int        `json:"hour_days" binding:"min=0,max=36500"`
+	DayDays        int        `json:"day_days" binding:"min=0,max=36500"`
+}
+
+type RetentionPolicyResponse struct {
+	DeviceTypeUUID *uuid.UUID `json:"device_type_uuid"`
+	Home           *string    `json:"home"`
+	RawDays        int        `json:"raw_days"`
+	MinuteDays     int        `json:"minute_days"`
+	HourDays       int        `json:"hour_days"`
+	DayDays        int        `json:"day_days"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+type ReadingAggregateQuery struct {
+	Metric string    `form:"metric" binding:"required,max=100"`
+	From   time.Time `form:"from" binding:"required"`
+	To     time.Time `form:"to" binding:"required"`
+	Bucket string    `form:"bucket" binding:"required"`
+	Units  string    `form:"units" binding:"omitempty,oneof=preferred"`
+}
+
+type ReadingBucket struct {
+	Start time.Time `json:"start"`
+	Min   float64   `json:"min"`
+	Max   float64   `json:"max"`
+	Avg   float64   `json:"avg"`
+	Count int64     `json:"count"`
+	Last  float64   `json:"last"`
+}
+
+type ReadingAggregateResponse struct {
+	DeviceUUID uuid.UUID       `json:"device_uuid"`
+	Metric     string          `json:"metric"`
+	Unit       string          `json:"unit,omitempty"`
+	Bucket     string          `json:"bucket"`
+	Tier       RollupTier      `json:"tier"`
+	Buckets    []ReadingBucket `json:"buckets"`
+}
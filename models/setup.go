@@ -0,0 +1,13 @@
+package models
+
+type SetupStatusResponse struct {
+	Required bool `json:"required"`
+}
+
+// SetupRequest creates the first administrator. Token is the setup token printed to the log when
+// the server started without one.
+type SetupRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Username string `json:"username" binding:"required,min=3,max=255"`
+	Password string `json:"password" binding:"required,min=6,max=255"`
+}
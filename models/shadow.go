@@ -0,0 +1,37 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DeviceShadow struct {
+	ID        uint            `gorm:"primaryKey" json:"id" validate:"required"`
+	DeviceID  uint            `gorm:"unique;not null" json:"device_id" validate:"required"`
+	Desired   json.RawMessage `gorm:"type:json" json:"desired"`
+	Reported  json.RawMessage `gorm:"type:json" json:"reported"`
+	Version   uint            `gorm:"not null" json:"version"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+type ShadowDesiredRequest struct {
+	Desired json.RawMessage `json:"desired" binding:"required" swaggertype:"object"`
+	Version *uint           `json:"version"`
+}
+
+type ShadowReportedRequest struct {
+	Reported json.RawMessage `json:"reported" binding:"required" swaggertype:"object"`
+	Version  *uint           `json:"version"`
+}
+
+type ShadowResponse struct {
+	DeviceUUID uuid.UUID       `json:"device_uuid" validate:"required,uuid"`
+	Desired    json.RawMessage `json:"desired" swaggertype:"object"`
+	Reported   json.RawMessage `json:"reported" swaggertype:"object"`
+	Delta      json.RawMessage `json:"delta" swaggertype:"object"`
+	Version    uint            `json:"version"`
+	UpdatedAt  *time.Time      `json:"updated_at"`
+}
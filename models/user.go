@@ -16,14 +16,43 @@ const (
 	UserRoleUser  UserRole = 2
 )
 
+// ParseUserRole parses a role name, admin or user.
+func ParseUserRole(name string) (UserRole, error) {
+	switch name {
+	case "admin":
+		return UserRoleAdmin, nil
+	case "user":
+		return UserRoleUser, nil
+	}
+	return 0, errors.New("role must be admin or user")
+}
+
+func (r UserRole) String() string {
+	switch r {
+	case UserRoleAdmin:
+		return "admin"
+	case UserRoleUser:
+		return "user"
+	}
+	return "unknown"
+}
+
+// UnitPreferences maps a measurement type such as "temperature" to the unit a user wants to see.
+type UnitPreferences map[string]string
+
 type User struct {
-	ID        uint      `gorm:"primaryKey" json:"id" validate:"required"`
-	UUID      uuid.UUID `gorm:"unique" json:"uuid" validate:"required,uuid"`
-	Username  string    `gorm:"unique" json:"username" validate:"required,lte=255"`
-	Password  string    `json:"password,omitempty" validate:"required,lte=255"`
-	Role      UserRole  `gorm:"type:TINYINT;not null" json:"role"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID             uint            `gorm:"primaryKey" json:"id" validate:"required"`
+	UUID           uuid.UUID       `gorm:"unique" json:"uuid" validate:"required,uuid"`
+	Username       string          `gorm:"unique" json:"username" validate:"required,lte=255"`
+	Password       string          `json:"password,omitempty" validate:"required,lte=255"`
+	Role           UserRole        `gorm:"type:TINYINT;not null" json:"role"`
+	PreferredUnits UnitPreferences `gorm:"type:json;serializer:json" json:"preferred_units"`
+	Timezone       string          `gorm:"not null;default:UTC" json:"timezone" validate:"lte=64"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+
+	// location caches the loaded Timezone, see SetTimezone.
+	location *time.Location
 }
 
 type UserRegisterRequest struct {
@@ -31,6 +60,14 @@ type UserRegisterRequest struct {
 	Password string `json:"password" binding:"required,min=6,max=255"`
 }
 
+// UserCreateRequest creates a user with any role. It is used by the command line, which acts
+// with administrator rights.
+type UserCreateRequest struct {
+	Username string   `json:"username" binding:"required,min=3,max=255"`
+	Password string   `json:"password" binding:"required,min=6,max=255"`
+	Role     UserRole `json:"role" binding:"required,oneof=1 2"`
+}
+
 type UserLoginRequest struct {
 	Username string `json:"username" binding:"required,min=3,max=255"`
 	Password string `json:"password" binding:"required,min=6,max=255"`
@@ -50,10 +87,21 @@ type UserLoginResponse struct {
 }
 
 type UserProfileResponse struct {
-	UUID      uuid.UUID `json:"uuid" validate:"required,uuid"`
-	Username  string    `json:"username" validate:"required,lte=255"`
-	CreatedAt time.Time `json:"created_at" validate:"required"`
-	UpdatedAt time.Time `json:"updated_at" validate:"required"`
+	UUID        uuid.UUID               `json:"uuid" validate:"required,uuid"`
+	Username    string                  `json:"username" validate:"required,lte=255"`
+	Preferences UserPreferencesResponse `json:"preferences"`
+	CreatedAt   time.Time               `json:"created_at" validate:"required"`
+	UpdatedAt   time.Time               `json:"updated_at" validate:"required"`
+}
+
+type UserPreferencesRequest struct {
+	Units    map[string]string `json:"units"`
+	Timezone *string           `json:"timezone" binding:"omitempty,min=1,max=64"`
+}
+
+type UserPreferencesResponse struct {
+	Units    UnitPreferences `json:"units"`
+	Timezone string          `json:"timezone"`
 }
 
 type UserUpdateRequest struct {
@@ -90,6 +138,10 @@ func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
 		u.Role = UserRoleUser
 	}
 
+	if u.Timezone == "" {
+		u.Timezone = "UTC"
+	}
+
 	if err := u.HashPassword(); err != nil {
 		return err
 	}
@@ -103,3 +155,44 @@ func (u *User) CheckPassword(password string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
 	return err == nil
 }
+
+// AfterFind loads the time zone of a user read from the database once, so Location does not.
+func (u *User) AfterFind(tx *gorm.DB) (err error) {
+	if u.SetTimezone(u.Timezone) != nil {
+		u.location = time.UTC
+	}
+	return nil
+}
+
+// SetTimezone sets the time zone the user wants times displayed in. It fails for an unknown name
+// and leaves the user unchanged.
+func (u *User) SetTimezone(name string) error {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return err
+	}
+	u.Timezone = name
+	u.location = location
+	return nil
+}
+
+// Location returns the time zone the user wants times displayed in, falling back to UTC.
+func (u *User) Location() *time.Location {
+	if u.location == nil {
+		return time.UTC
+	}
+	return u.location
+}
+
+func (u *User) PreferencesResponse() UserPreferencesResponse {
+	units := u.PreferredUnits
+	if units == nil {
+		units = UnitPreferences{}
+	}
+
+	timezone := u.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	return UserPreferencesResponse{Units: units, Timezone: timezone}
+}
@@ -0,0 +1,83 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"home-monitor-backend/models"
+	"home-monitor-backend/repositories"
+	"log/slog"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxAttempts bounds how often DatabaseStore retries a bucket that other instances keep updating.
+const maxAttempts = 5
+
+// DatabaseStore keeps the buckets in the database, so that every instance connected to it shares
+// them. Concurrent updates of a bucket are detected by its version and retried.
+type DatabaseStore struct {
+	rateLimitRepo repositories.RateLimitRepository
+
+	mu      sync.Mutex
+	sweptAt time.Time
+}
+
+func NewDatabaseStore(rateLimitRepo repositories.RateLimitRepository) *DatabaseStore {
+	return &DatabaseStore{rateLimitRepo: rateLimitRepo}
+}
+
+func (s *DatabaseStore) Take(ctx context.Context, key string, policy Policy, now time.Time) (Result, error) {
+	s.sweep(ctx, now)
+
+	for range maxAttempts {
+		stored, err := s.rateLimitRepo.RateLimitBucketFind(ctx, key)
+		found := err == nil
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return Result{}, err
+		}
+		if !found {
+			stored = &models.RateLimitBucket{Key: key}
+		}
+
+		bucket := Bucket{Tokens: stored.Tokens, RefilledAt: stored.RefilledAt}
+		result := policy.Take(&bucket, now)
+
+		expectedVersion := stored.Version
+		stored.Tokens = bucket.Tokens
+		stored.RefilledAt = bucket.RefilledAt
+		stored.ExpiresAt = now.Add(result.Reset)
+		stored.Version++
+
+		var ok bool
+		if found {
+			ok, err = s.rateLimitRepo.RateLimitBucketUpdate(ctx, stored, expectedVersion)
+		} else {
+			ok, err = s.rateLimitRepo.RateLimitBucketCreate(ctx, stored)
+		}
+		if err != nil {
+			return Result{}, err
+		}
+		if ok {
+			return result, nil
+		}
+	}
+	return Result{}, errors.New("rate limit bucket is updated too often concurrently")
+}
+
+// sweep deletes the expired buckets at most once per sweepInterval. Every instance sweeps; a
+// failed sweep is retried by the next one.
+func (s *DatabaseStore) sweep(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.sweptAt) < sweepInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.sweptAt = now
+	s.mu.Unlock()
+
+	if _, err := s.rateLimitRepo.RateLimitBucketDeleteExpired(ctx, now); err != nil {
+		slog.WarnContext(ctx, "Deleting expired rate limit buckets failed", "error", err)
+	}
+}
@@ -0,0 +1,128 @@
+// Package ratelimit limits request rates with token buckets. A bucket holds up to Limit tokens and
+// refills at Limit tokens per Period; every request takes one. The buckets are kept in a Store, which
+// can be shared by several instances of the API.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Names of the policies applied to the routes.
+const (
+	PolicyLogin  = "login"
+	PolicyIngest = "ingest"
+	PolicyQuery  = "query"
+)
+
+// Policy allows Limit requests per Period, all of them at once if the bucket is full. The zero
+// Policy allows any number of requests.
+type Policy struct {
+	Limit  int
+	Period time.Duration
+}
+
+// ParsePolicy parses a policy written as limit/period, e.g. 10/1m, or off.
+func ParsePolicy(text string) (Policy, error) {
+	if text == "off" {
+		return Policy{}, nil
+	}
+
+	limitText, periodText, found := strings.Cut(text, "/")
+	if !found {
+		return Policy{}, fmt.Errorf("rate limit %q is not limit/period or off", text)
+	}
+	limit, err := strconv.Atoi(limitText)
+	if err != nil || limit < 1 {
+		return Policy{}, fmt.Errorf("rate limit %q must have a positive limit", text)
+	}
+	period, err := time.ParseDuration(periodText)
+	if err != nil || period <= 0 {
+		return Policy{}, fmt.Errorf("rate limit %q must have a positive period", text)
+	}
+	return Policy{Limit: limit, Period: period}, nil
+}
+
+func (p *Policy) UnmarshalText(text []byte) error {
+	policy, err := ParsePolicy(string(text))
+	if err != nil {
+		return err
+	}
+	*p = policy
+	return nil
+}
+
+func (p Policy) String() string {
+	if p.Unlimited() {
+		return "off"
+	}
+	return fmt.Sprintf("%d/%s", p.Limit, p.Period)
+}
+
+func (p Policy) Unlimited() bool {
+	return p.Limit == 0
+}
+
+// Bucket is the state of one token bucket. The zero Bucket is full.
+type Bucket struct {
+	Tokens     float64
+	RefilledAt time.Time
+}
+
+// Result tells a client where it stands after a request.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again, RetryAfter the time until a denied
+	// request would be allowed.
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Take refills the bucket for the time since it was last refilled and takes a token from it, if
+// there is one.
+func (p Policy) Take(bucket *Bucket, now time.Time) Result {
+	limit := float64(p.Limit)
+	perSecond := limit / p.Period.Seconds()
+	if elapsed := now.Sub(bucket.RefilledAt).Seconds(); elapsed > 0 {
+		bucket.Tokens = min(limit, bucket.Tokens+elapsed*perSecond)
+		bucket.RefilledAt = now
+	}
+
+	result := Result{Limit: p.Limit}
+	if bucket.Tokens >= 1 {
+		bucket.Tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - bucket.Tokens) / perSecond)
+	}
+	result.Remaining = int(math.Floor(bucket.Tokens))
+	result.Reset = seconds((limit - bucket.Tokens) / perSecond)
+	return result
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
+
+// Store keeps the buckets. Take must update the bucket of key atomically, also when the store is
+// shared between instances.
+type Store interface {
+	Take(ctx context.Context, key string, policy Policy, now time.Time) (Result, error)
+}
+
+// Limiter applies named policies, keeping their buckets in Store. A name without a policy is
+// not limited.
+type Limiter struct {
+	Store    Store
+	Policies map[string]Policy
+}
+
+// sweepInterval is how often stores drop the buckets that have filled up again, which are no
+// different from missing ones.
+const sweepInterval = time.Minute
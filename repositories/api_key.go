@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"context"
+	"home-monitor-backend/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository interface {
+	APIKeyFindByUUID(ctx context.Context, uuid uuid.UUID) (*models.APIKey, error)
+	APIKeyFindByKeyHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	APIKeyFindByUserID(ctx context.Context, userID uint) ([]models.APIKey, error)
+	APIKeyCreate(ctx context.Context, key *models.APIKey) error
+	APIKeyDelete(ctx context.Context, key *models.APIKey) error
+	APIKeyTouch(ctx context.Context, key *models.APIKey, usedAt time.Time) error
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) APIKeyFindByUUID(ctx context.Context, uuid uuid.UUID) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.WithContext(ctx).Where("uuid = ?", uuid).First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) APIKeyFindByKeyHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.WithContext(ctx).Preload("User").Where("key_hash = ?", keyHash).First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) APIKeyFindByUserID(ctx context.Context, userID uint) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *apiKeyRepository) APIKeyCreate(ctx context.Context, key *models.APIKey) error {
+	return r.db.WithContext(ctx).Omit("User").Create(key).Error
+}
+
+func (r *apiKeyRepository) APIKeyDelete(ctx context.Context, key *models.APIKey) error {
+	return r.db.WithContext(ctx).Delete(key).Error
+}
+
+func (r *apiKeyRepository) APIKeyTouch(ctx context.Context, key *models.APIKey, usedAt time.Time) error {
+	key.LastUsedAt = &usedAt
+	return r.db.WithContext(ctx).Model(key).UpdateColumn("last_used_at", usedAt).Error
+}
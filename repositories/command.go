@@ -0,0 +1,120 @@
+package repositories
+
+import (
+	"context"
+	"home-monitor-backend/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CommandRepository interface {
+	CommandFindByUUID(ctx context.Context, uuid uuid.UUID) (*models.Command, error)
+	CommandFindByDeviceID(ctx context.Context, deviceID uint, status models.CommandStatus, limit int) ([]models.Command, error)
+	CommandFindDeliverable(ctx context.Context, deviceID uint, now time.Time) ([]models.Command, error)
+	CommandCreate(ctx context.Context, command *models.Command) error
+	CommandComplete(ctx context.Context, command *models.Command) (bool, error)
+	CommandMarkSent(ctx context.Context, commands []models.Command, sentAt time.Time) error
+	CommandExpire(ctx context.Context, now time.Time) (int64, error)
+	CommandCountByStatus(ctx context.Context, status models.CommandStatus) (int64, error)
+}
+
+type commandRepository struct {
+	db *gorm.DB
+}
+
+func NewCommandRepository(db *gorm.DB) CommandRepository {
+	return &commandRepository{db: db}
+}
+
+func (r *commandRepository) CommandFindByUUID(ctx context.Context, uuid uuid.UUID) (*models.Command, error) {
+	var command models.Command
+	if err := r.db.WithContext(ctx).Where("uuid = ?", uuid).First(&command).Error; err != nil {
+		return nil, err
+	}
+	return &command, nil
+}
+
+func (r *commandRepository) CommandFindByDeviceID(ctx context.Context, deviceID uint, status models.CommandStatus, limit int) ([]models.Command, error) {
+	var commands []models.Command
+	query := r.db.WithContext(ctx).Where("device_id = ?", deviceID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Order("id DESC").Limit(limit).Find(&commands).Error; err != nil {
+		return nil, err
+	}
+	return commands, nil
+}
+
+// CommandFindDeliverable returns the unexpired commands a device has not acknowledged yet, oldest first.
+// Commands that were already sent are returned again so a device that lost them can retry.
+func (r *commandRepository) CommandFindDeliverable(ctx context.Context, deviceID uint, now time.Time) ([]models.Command, error) {
+	var commands []models.Command
+	if err := r.db.WithContext(ctx).
+		Where("device_id = ? AND status IN ? AND expires_at > ?", deviceID,
+			[]models.CommandStatus{models.CommandStatusPending, models.CommandStatusSent}, now).
+		Order("id").
+		Find(&commands).Error; err != nil {
+		return nil, err
+	}
+	return commands, nil
+}
+
+func (r *commandRepository) CommandCreate(ctx context.Context, command *models.Command) error {
+	return r.db.WithContext(ctx).Create(command).Error
+}
+
+// CommandComplete stores the final status of the command only if it is still pending or sent.
+// It reports false when the command was finalised in the meantime, e.g. acked and expired at once.
+func (r *commandRepository) CommandComplete(ctx context.Context, command *models.Command) (bool, error) {
+	command.UpdatedAt = time.Now()
+	result := r.db.WithContext(ctx).Model(&models.Command{}).
+		Where("id = ? AND status IN ?", command.ID,
+			[]models.CommandStatus{models.CommandStatusPending, models.CommandStatusSent}).
+		Updates(map[string]any{
+			"status":       command.Status,
+			"result":       command.Result,
+			"error":        command.Error,
+			"sent_at":      command.SentAt,
+			"completed_at": command.CompletedAt,
+			"updated_at":   command.UpdatedAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *commandRepository) CommandMarkSent(ctx context.Context, commands []models.Command, sentAt time.Time) error {
+	ids := make([]uint, 0, len(commands))
+	for i := range commands {
+		if commands[i].Status == models.CommandStatusPending {
+			ids = append(ids, commands[i].ID)
+			commands[i].Status = models.CommandStatusSent
+			commands[i].SentAt = &sentAt
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return r.db.WithContext(ctx).Model(&models.Command{}).
+		Where("id IN ? AND status = ?", ids, models.CommandStatusPending).
+		Updates(map[string]any{"status": models.CommandStatusSent, "sent_at": sentAt}).Error
+}
+
+func (r *commandRepository) CommandExpire(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.Command{}).
+		Where("status IN ? AND expires_at <= ?",
+			[]models.CommandStatus{models.CommandStatusPending, models.CommandStatusSent}, now).
+		Updates(map[string]any{"status": models.CommandStatusExpired, "completed_at": now})
+	return result.RowsAffected, result.Error
+}
+
+func (r *commandRepository) CommandCountByStatus(ctx context.Context, status models.CommandStatus) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Command{}).Where("status = ?", status).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,58 @@
+package repositories_test
+
+import (
+	"context"
+	"home-monitor-backend/models"
+	"home-monitor-backend/repositories"
+	"home-monitor-backend/testutil"
+	"testing"
+	"time"
+)
+
+func TestCommandCompleteAfterExpire(t *testing.T) {
+	t.Parallel()
+	h := testutil.New(t)
+	ctx := context.Background()
+	user := h.CreateUser("alice", "secret-password", models.UserRoleUser)
+	device := h.CreateDevice(models.Device{UserID: user.ID, Name: "thermostat"})
+
+	commandRepo := repositories.NewCommandRepository(h.DB)
+	command := &models.Command{DeviceID: device.ID, UserID: user.ID, Name: "reboot", ExpiresAt: time.Now().Add(time.Minute)}
+	if err := commandRepo.CommandCreate(ctx, command); err != nil {
+		t.Fatal(err)
+	}
+
+	// The ack read the command while it was pending; the expiry worker finalises it first.
+	if _, err := commandRepo.CommandExpire(ctx, command.ExpiresAt); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	command.Status = models.CommandStatusAcked
+	command.CompletedAt = &now
+	completed, err := commandRepo.CommandComplete(ctx, command)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if completed {
+		t.Error("completed = true, want false for an expired command")
+	}
+
+	stored, err := commandRepo.CommandFindByUUID(ctx, command.UUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != models.CommandStatusExpired {
+		t.Errorf("status = %s, want %s", stored.Status, models.CommandStatusExpired)
+	}
+
+	// A command still pending is completed.
+	pending := &models.Command{DeviceID: device.ID, UserID: user.ID, Name: "reboot", ExpiresAt: time.Now().Add(time.Minute)}
+	if err := commandRepo.CommandCreate(ctx, pending); err != nil {
+		t.Fatal(err)
+	}
+	pending.Status = models.CommandStatusAcked
+	pending.CompletedAt = &now
+	if completed, err := commandRepo.CommandComplete(ctx, pending); err != nil || !completed {
+		t.Errorf("complete pending command = %v, %v, want true", completed, err)
+	}
+}
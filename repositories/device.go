@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"context"
+	"home-monitor-backend/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DeviceRepository interface {
+	DeviceFindByUUID(ctx context.Context, uuid uuid.UUID) (*models.Device, error)
+	DeviceFindByTokenHash(ctx context.Context, tokenHash string) (*models.Device, error)
+	DeviceFindByUserID(ctx context.Context, userID uint) ([]models.Device, error)
+	DeviceFindAll(ctx context.Context) ([]models.Device, error)
+	DeviceCreate(ctx context.Context, device *models.Device) error
+	DeviceUpdate(ctx context.Context, device *models.Device) error
+	DeviceTouch(ctx context.Context, device *models.Device, seenAt time.Time) error
+}
+
+type deviceRepository struct {
+	db *gorm.DB
+}
+
+func NewDeviceRepository(db *gorm.DB) DeviceRepository {
+	return &deviceRepository{db: db}
+}
+
+func (r *deviceRepository) DeviceFindByUUID(ctx context.Context, uuid uuid.UUID) (*models.Device, error) {
+	var device models.Device
+	if err := r.db.WithContext(ctx).Preload("DeviceType").Where("uuid = ?", uuid).First(&device).Error; err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+func (r *deviceRepository) DeviceFindByTokenHash(ctx context.Context, tokenHash string) (*models.Device, error) {
+	var device models.Device
+	if err := r.db.WithContext(ctx).Preload("DeviceType").Where("token_hash = ?", tokenHash).First(&device).Error; err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+func (r *deviceRepository) DeviceFindByUserID(ctx context.Context, userID uint) ([]models.Device, error) {
+	var devices []models.Device
+	if err := r.db.WithContext(ctx).Preload("DeviceType").Where("user_id = ?", userID).Order("id").Find(&devices).Error; err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+func (r *deviceRepository) DeviceFindAll(ctx context.Context) ([]models.Device, error) {
+	var devices []models.Device
+	if err := r.db.WithContext(ctx).Preload("DeviceType").Order("id").Find(&devices).Error; err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+func (r *deviceRepository) DeviceCreate(ctx context.Context, device *models.Device) error {
+	return r.db.WithContext(ctx).Omit("DeviceType").Create(device).Error
+}
+
+func (r *deviceRepository) DeviceUpdate(ctx context.Context, device *models.Device) error {
+	return r.db.WithContext(ctx).Omit("DeviceType").Save(device).Error
+}
+
+func (r *deviceRepository) DeviceTouch(ctx context.Context, device *models.Device, seenAt time.Time) error {
+	device.LastSeenAt = &seenAt
+	return r.db.WithContext(ctx).Model(device).UpdateColumn("last_seen_at", seenAt).Error
+}
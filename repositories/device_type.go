@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"context"
+	"home-monitor-backend/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DeviceTypeRepository interface {
+	DeviceTypeFindByUUID(ctx context.Context, uuid uuid.UUID) (*models.DeviceType, error)
+	DeviceTypeFindByName(ctx context.Context, name string) (*models.DeviceType, error)
+	DeviceTypeFindAll(ctx context.Context) ([]models.DeviceType, error)
+	DeviceTypeCreate(ctx context.Context, deviceType *models.DeviceType) error
+}
+
+type deviceTypeRepository struct {
+	db *gorm.DB
+}
+
+func NewDeviceTypeRepository(db *gorm.DB) DeviceTypeRepository {
+	return &deviceTypeRepository{db: db}
+}
+
+func (r *deviceTypeRepository) DeviceTypeFindByUUID(ctx context.Context, uuid uuid.UUID) (*models.DeviceType, error) {
+	var deviceType models.DeviceType
+	if err := r.db.WithContext(ctx).Where("uuid = ?", uuid).First(&deviceType).Error; err != nil {
+		return nil, err
+	}
+	return &deviceType, nil
+}
+
+func (r *deviceTypeRepository) DeviceTypeFindByName(ctx context.Context, name string) (*models.DeviceType, error) {
+	var deviceType models.DeviceType
+	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&deviceType).Error; err != nil {
+		return nil, err
+	}
+	return &deviceType, nil
+}
+
+func (r *deviceTypeRepository) DeviceTypeFindAll(ctx context.Context) ([]models.DeviceType, error) {
+	var deviceTypes []models.DeviceType
+	if err := r.db.WithContext(ctx).Order("name").Find(&deviceTypes).Error; err != nil {
+		return nil, err
+	}
+	return deviceTypes, nil
+}
+
+func (r *deviceTypeRepository) DeviceTypeCreate(ctx context.Context, deviceType *models.DeviceType) error {
+	return r.db.WithContext(ctx).Create(deviceType).Error
+}
@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"context"
+	"home-monitor-backend/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ExportRepository interface {
+	ExportFindByUUID(ctx context.Context, uuid uuid.UUID) (*models.ExportJob, error)
+	ExportFindByUserID(ctx context.Context, userID uint, limit int) ([]models.ExportJob, error)
+	ExportFindPending(ctx context.Context) (*models.ExportJob, error)
+	ExportFindExpired(ctx context.Context, now time.Time) ([]models.ExportJob, error)
+	ExportCreate(ctx context.Context, job *models.ExportJob) error
+	ExportUpdate(ctx context.Context, job *models.ExportJob) error
+	ExportDelete(ctx context.Context, job *models.ExportJob) error
+	ExportRequeue(ctx context.Context) (int64, error)
+	ExportCountByStatus(ctx context.Context, status models.ExportStatus) (int64, error)
+}
+
+type exportRepository struct {
+	db *gorm.DB
+}
+
+func NewExportRepository(db *gorm.DB) ExportRepository {
+	return &exportRepository{db: db}
+}
+
+func (r *exportRepository) ExportFindByUUID(ctx context.Context, uuid uuid.UUID) (*models.ExportJob, error) {
+	var job models.ExportJob
+	if err := r.db.WithContext(ctx).Where("uuid = ?", uuid).First(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *exportRepository) ExportFindByUserID(ctx context.Context, userID uint, limit int) ([]models.ExportJob, error) {
+	var jobs []models.ExportJob
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id DESC").Limit(limit).Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// ExportFindPending returns the oldest job waiting to run, or nil when there is none.
+func (r *exportRepository) ExportFindPending(ctx context.Context) (*models.ExportJob, error) {
+	var job models.ExportJob
+	result := r.db.WithContext(ctx).Preload("User").Where("status = ?", models.ExportStatusPending).Order("id").Limit(1).Find(&job)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &job, nil
+}
+
+func (r *exportRepository) ExportFindExpired(ctx context.Context, now time.Time) ([]models.ExportJob, error) {
+	var jobs []models.ExportJob
+	if err := r.db.WithContext(ctx).Where("expires_at IS NOT NULL AND expires_at < ?", now).Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (r *exportRepository) ExportCreate(ctx context.Context, job *models.ExportJob) error {
+	return r.db.WithContext(ctx).Omit("User").Create(job).Error
+}
+
+func (r *exportRepository) ExportUpdate(ctx context.Context, job *models.ExportJob) error {
+	return r.db.WithContext(ctx).Omit("User").Save(job).Error
+}
+
+func (r *exportRepository) ExportDelete(ctx context.Context, job *models.ExportJob) error {
+	return r.db.WithContext(ctx).Delete(job).Error
+}
+
+// ExportRequeue moves jobs left running by a stopped server back to pending.
+func (r *exportRepository) ExportRequeue(ctx context.Context) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.ExportJob{}).
+		Where("status = ?", models.ExportStatusRunning).
+		Updates(map[string]any{"status": models.ExportStatusPending, "started_at": nil})
+	return result.RowsAffected, result.Error
+}
+
+func (r *exportRepository) ExportCountByStatus(ctx context.Context, status models.ExportStatus) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.ExportJob{}).Where("status = ?", status).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,167 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"home-monitor-backend/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ImportRepository interface {
+	ImportFindByUUID(ctx context.Context, uuid uuid.UUID) (*models.ImportJob, error)
+	ImportFindByDeviceID(ctx context.Context, deviceID uint, limit int) ([]models.ImportJob, error)
+	ImportFindPending(ctx context.Context) (*models.ImportJob, error)
+	ImportCreate(ctx context.Context, job *models.ImportJob) error
+	ImportClaim(ctx context.Context, job *models.ImportJob, owner string) (bool, error)
+	ImportCheckpoint(ctx context.Context, job *models.ImportJob, readings []models.Reading) error
+	ImportRelease(ctx context.Context, job *models.ImportJob) error
+	ImportReset(ctx context.Context, job *models.ImportJob, staleBefore time.Time) (bool, error)
+	ImportRequeue(ctx context.Context, staleBefore time.Time) (int64, error)
+	ImportCountByStatus(ctx context.Context, status models.ImportStatus) (int64, error)
+}
+
+// ErrImportLeaseLost is returned when a job was requeued while its runner was still working on it.
+var ErrImportLeaseLost = errors.New("import job was taken over by another process")
+
+type importRepository struct {
+	db *gorm.DB
+}
+
+func NewImportRepository(db *gorm.DB) ImportRepository {
+	return &importRepository{db: db}
+}
+
+func (r *importRepository) ImportFindByUUID(ctx context.Context, uuid uuid.UUID) (*models.ImportJob, error) {
+	var job models.ImportJob
+	if err := r.db.WithContext(ctx).Preload("Device.DeviceType").Where("uuid = ?", uuid).First(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *importRepository) ImportFindByDeviceID(ctx context.Context, deviceID uint, limit int) ([]models.ImportJob, error) {
+	var jobs []models.ImportJob
+	if err := r.db.WithContext(ctx).Preload("Device").Where("device_id = ?", deviceID).Order("id DESC").Limit(limit).Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// ImportFindPending returns the oldest job waiting to run, or nil when there is none.
+func (r *importRepository) ImportFindPending(ctx context.Context) (*models.ImportJob, error) {
+	var job models.ImportJob
+	result := r.db.WithContext(ctx).Preload("Device.DeviceType").Where("status = ?", models.ImportStatusPending).Order("id").Limit(1).Find(&job)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &job, nil
+}
+
+func (r *importRepository) ImportCreate(ctx context.Context, job *models.ImportJob) error {
+	return r.db.WithContext(ctx).Omit("Device").Create(job).Error
+}
+
+// ImportClaim moves a pending job to running under a lease of owner. It reports false when another
+// process claimed it first.
+func (r *importRepository) ImportClaim(ctx context.Context, job *models.ImportJob, owner string) (bool, error) {
+	heartbeatAt := time.Now()
+	result := r.db.WithContext(ctx).Model(&models.ImportJob{}).
+		Where("id = ? AND status = ?", job.ID, models.ImportStatusPending).
+		Updates(map[string]any{
+			"status":       models.ImportStatusRunning,
+			"claimed_by":   owner,
+			"heartbeat_at": heartbeatAt,
+			"started_at":   job.StartedAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 1 {
+		job.Status = models.ImportStatusRunning
+		job.ClaimedBy = owner
+		job.HeartbeatAt = &heartbeatAt
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ImportCheckpoint stores a batch of readings together with the job progress, so that a resumed
+// job neither skips nor repeats rows. The job must still be claimed by the same runner, otherwise
+// nothing is stored and ErrImportLeaseLost is returned.
+func (r *importRepository) ImportCheckpoint(ctx context.Context, job *models.ImportJob, readings []models.Reading) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(readings) > 0 {
+			if err := tx.CreateInBatches(readings, 500).Error; err != nil {
+				return err
+			}
+		}
+		result := tx.Model(&models.ImportJob{}).Select("*").Omit("Device").
+			Where("id = ? AND status = ? AND claimed_by = ?", job.ID, models.ImportStatusRunning, job.ClaimedBy).
+			Updates(job)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrImportLeaseLost
+		}
+		return nil
+	})
+}
+
+// ImportRelease gives up the lease of an interrupted job, leaving it pending at its last checkpoint.
+func (r *importRepository) ImportRelease(ctx context.Context, job *models.ImportJob) error {
+	result := r.db.WithContext(ctx).Model(&models.ImportJob{}).
+		Where("id = ? AND status = ? AND claimed_by = ?", job.ID, models.ImportStatusRunning, job.ClaimedBy).
+		Updates(map[string]any{"status": models.ImportStatusPending, "claimed_by": ""})
+	if result.Error == nil && result.RowsAffected == 1 {
+		job.Status = models.ImportStatusPending
+		job.ClaimedBy = ""
+	}
+	return result.Error
+}
+
+// ImportReset moves a failed or pending job back to pending for a resume, as well as a running one
+// whose runner stopped sending heartbeats before staleBefore. It reports false when the job is
+// completed or still running.
+func (r *importRepository) ImportReset(ctx context.Context, job *models.ImportJob, staleBefore time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.ImportJob{}).
+		Where("id = ?", job.ID).
+		Where(r.db.Where("status IN ?", []models.ImportStatus{models.ImportStatusPending, models.ImportStatusFailed}).
+			Or(stale(r.db, staleBefore))).
+		Updates(map[string]any{"status": models.ImportStatusPending, "claimed_by": "", "error": "", "completed_at": nil})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 1 {
+		job.Status = models.ImportStatusPending
+		job.ClaimedBy = ""
+		job.Error = ""
+		job.CompletedAt = nil
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ImportRequeue moves running jobs whose runner stopped sending heartbeats before staleBefore back
+// to pending. Jobs of runners that are still alive keep their lease.
+func (r *importRepository) ImportRequeue(ctx context.Context, staleBefore time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.ImportJob{}).
+		Where(stale(r.db, staleBefore)).
+		Updates(map[string]any{"status": models.ImportStatusPending, "claimed_by": ""})
+	return result.RowsAffected, result.Error
+}
+
+// stale selects running jobs whose last heartbeat is older than staleBefore.
+func stale(db *gorm.DB, staleBefore time.Time) *gorm.DB {
+	return db.Where("status = ? AND (heartbeat_at IS NULL OR heartbeat_at < ?)", models.ImportStatusRunning, staleBefore)
+}
+
+func (r *importRepository) ImportCountByStatus(ctx context.Context, status models.ImportStatus) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.ImportJob{}).Where("status = ?", status).Count(&count).Error
+	return count, err
+}
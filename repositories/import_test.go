@@ -0,0 +1,66 @@
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"home-monitor-backend/models"
+	"home-monitor-backend/repositories"
+	"home-monitor-backend/testutil"
+	"testing"
+	"time"
+)
+
+func TestImportLease(t *testing.T) {
+	t.Parallel()
+	h := testutil.New(t)
+	ctx := context.Background()
+	user := h.CreateUser("alice", "secret-password", models.UserRoleUser)
+	device := h.CreateDevice(models.Device{UserID: user.ID, Name: "thermometer"})
+
+	importRepo := repositories.NewImportRepository(h.DB)
+	job := &models.ImportJob{UserID: user.ID, DeviceID: device.ID, FilePath: "readings.csv"}
+	if err := importRepo.ImportCreate(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+	if claimed, err := importRepo.ImportClaim(ctx, job, "first"); err != nil || !claimed {
+		t.Fatalf("claim = %v, %v, want true", claimed, err)
+	}
+
+	// A live runner keeps its job: neither a requeue nor a resume takes it over.
+	staleBefore := time.Now().Add(-time.Minute)
+	if requeued, err := importRepo.ImportRequeue(ctx, staleBefore); err != nil || requeued != 0 {
+		t.Errorf("requeue live job = %d, %v, want 0", requeued, err)
+	}
+	if reset, err := importRepo.ImportReset(ctx, &models.ImportJob{ID: job.ID}, staleBefore); err != nil || reset {
+		t.Errorf("reset live job = %v, %v, want false", reset, err)
+	}
+
+	// Once the heartbeat is stale, the job is queued again and the old runner can no longer write.
+	if requeued, err := importRepo.ImportRequeue(ctx, time.Now().Add(time.Minute)); err != nil || requeued != 1 {
+		t.Fatalf("requeue stale job = %d, %v, want 1", requeued, err)
+	}
+	second := &models.ImportJob{ID: job.ID}
+	if claimed, err := importRepo.ImportClaim(ctx, second, "second"); err != nil || !claimed {
+		t.Fatalf("claim requeued job = %v, %v, want true", claimed, err)
+	}
+	job.RowsProcessed = 500
+	readings := []models.Reading{{DeviceID: device.ID, Metric: "temperature", Value: 20, RecordedAt: time.Now()}}
+	if err := importRepo.ImportCheckpoint(ctx, job, readings); !errors.Is(err, repositories.ErrImportLeaseLost) {
+		t.Errorf("checkpoint of old runner = %v, want %v", err, repositories.ErrImportLeaseLost)
+	}
+	var count int64
+	if err := h.DB.Model(&models.Reading{}).Where("device_id = ?", device.ID).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("readings = %d, want 0 from a rolled back checkpoint", count)
+	}
+
+	// A released job is pending again and can be resumed.
+	if err := importRepo.ImportRelease(ctx, second); err != nil {
+		t.Fatal(err)
+	}
+	if reset, err := importRepo.ImportReset(ctx, second, staleBefore); err != nil || !reset {
+		t.Errorf("reset released job = %v, %v, want true", reset, err)
+	}
+}
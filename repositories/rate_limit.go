@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"context"
+	"home-monitor-backend/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RateLimitRepository interface {
+	RateLimitBucketFind(ctx context.Context, key string) (*models.RateLimitBucket, error)
+	RateLimitBucketCreate(ctx context.Context, bucket *models.RateLimitBucket) (bool, error)
+	RateLimitBucketUpdate(ctx context.Context, bucket *models.RateLimitBucket, expectedVersion uint) (bool, error)
+	RateLimitBucketDeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+type rateLimitRepository struct {
+	db *gorm.DB
+}
+
+func NewRateLimitRepository(db *gorm.DB) RateLimitRepository {
+	return &rateLimitRepository{db: db}
+}
+
+func (r *rateLimitRepository) RateLimitBucketFind(ctx context.Context, key string) (*models.RateLimitBucket, error) {
+	var bucket models.RateLimitBucket
+	if err := r.db.WithContext(ctx).Where("bucket_key = ?", key).First(&bucket).Error; err != nil {
+		return nil, err
+	}
+	return &bucket, nil
+}
+
+// RateLimitBucketCreate stores a new bucket. It reports false when a bucket with the key already
+// exists.
+func (r *rateLimitRepository) RateLimitBucketCreate(ctx context.Context, bucket *models.RateLimitBucket) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(bucket)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RateLimitBucketUpdate stores the bucket only if its stored version still equals expectedVersion.
+// It reports false when another writer got there first.
+func (r *rateLimitRepository) RateLimitBucketUpdate(ctx context.Context, bucket *models.RateLimitBucket, expectedVersion uint) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.RateLimitBucket{}).
+		Where("bucket_key = ? AND version = ?", bucket.Key, expectedVersion).
+		Updates(map[string]any{
+			"tokens":      bucket.Tokens,
+			"refilled_at": bucket.RefilledAt,
+			"expires_at":  bucket.ExpiresAt,
+			"version":     bucket.Version,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RateLimitBucketDeleteExpired deletes the buckets that are full again by now.
+func (r *rateLimitRepository) RateLimitBucketDeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&models.RateLimitBucket{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,156 @@
+package repositories
+
+import (
+	"context"
+	"home-monitor-backend/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ReadingRepository interface {
+	ReadingCreateBatch(ctx context.Context, readings []models.Reading) error
+	ReadingFind(ctx context.Context, deviceID uint, query models.ReadingQuery) ([]models.Reading, error)
+	ReadingFindBetween(ctx context.Context, from time.Time, to time.Time) ([]models.Reading, error)
+	ReadingFindForDevice(ctx context.Context, deviceID uint, metric string, from time.Time, to time.Time) ([]models.Reading, error)
+	ReadingFirstRecordedAt(ctx context.Context) (*time.Time, error)
+	ReadingDeleteBefore(ctx context.Context, scope models.RetentionScope, cutoff time.Time) (int64, error)
+	ReadingFindTimes(ctx context.Context, deviceID uint, metrics []string, from time.Time, to time.Time) ([]models.Reading, error)
+	ReadingFindLatest(ctx context.Context, deviceIDs []uint) ([]models.Reading, error)
+	ReadingEach(ctx context.Context, deviceIDs []uint, metrics []string, from time.Time, to time.Time, fn func(reading *models.Reading) error) error
+}
+
+type readingRepository struct {
+	db *gorm.DB
+}
+
+func NewReadingRepository(db *gorm.DB) ReadingRepository {
+	return &readingRepository{db: db}
+}
+
+func (r *readingRepository) ReadingCreateBatch(ctx context.Context, readings []models.Reading) error {
+	if len(readings) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).CreateInBatches(readings, 500).Error
+}
+
+func (r *readingRepository) ReadingFind(ctx context.Context, deviceID uint, query models.ReadingQuery) ([]models.Reading, error) {
+	var readings []models.Reading
+	db := r.db.WithContext(ctx).Where("device_id = ?", deviceID)
+	if query.Metric != "" {
+		db = db.Where("metric = ?", query.Metric)
+	}
+	if !query.From.IsZero() {
+		db = db.Where("recorded_at >= ?", query.From)
+	}
+	if !query.To.IsZero() {
+		db = db.Where("recorded_at < ?", query.To)
+	}
+	if err := db.Order("recorded_at DESC").Limit(query.Limit).Find(&readings).Error; err != nil {
+		return nil, err
+	}
+	return readings, nil
+}
+
+func (r *readingRepository) ReadingFindBetween(ctx context.Context, from time.Time, to time.Time) ([]models.Reading, error) {
+	var readings []models.Reading
+	if err := r.db.WithContext(ctx).
+		Where("recorded_at >= ? AND recorded_at < ?", from, to).
+		Order("recorded_at").
+		Find(&readings).Error; err != nil {
+		return nil, err
+	}
+	return readings, nil
+}
+
+func (r *readingRepository) ReadingFindForDevice(ctx context.Context, deviceID uint, metric string, from time.Time, to time.Time) ([]models.Reading, error) {
+	var readings []models.Reading
+	if err := r.db.WithContext(ctx).
+		Where("device_id = ? AND metric = ? AND recorded_at >= ? AND recorded_at < ?", deviceID, metric, from, to).
+		Order("recorded_at").
+		Find(&readings).Error; err != nil {
+		return nil, err
+	}
+	return readings, nil
+}
+
+// ReadingFindTimes loads only the metric and time of the matching readings, for deduplication.
+func (r *readingRepository) ReadingFindTimes(ctx context.Context, deviceID uint, metrics []string, from time.Time, to time.Time) ([]models.Reading, error) {
+	var readings []models.Reading
+	if err := r.db.WithContext(ctx).
+		Select("metric", "recorded_at").
+		Where("device_id = ? AND metric IN ? AND recorded_at >= ? AND recorded_at <= ?", deviceID, metrics, from, to).
+		Find(&readings).Error; err != nil {
+		return nil, err
+	}
+	return readings, nil
+}
+
+// ReadingFindLatest returns the newest reading of every metric of the given devices.
+func (r *readingRepository) ReadingFindLatest(ctx context.Context, deviceIDs []uint) ([]models.Reading, error) {
+	var readings []models.Reading
+	if len(deviceIDs) == 0 {
+		return readings, nil
+	}
+
+	latest := r.db.WithContext(ctx).Model(&models.Reading{}).
+		Select("device_id, metric, MAX(recorded_at) AS recorded_at").
+		Where("device_id IN ?", deviceIDs).
+		Group("device_id, metric")
+
+	if err := r.db.WithContext(ctx).
+		Joins("JOIN (?) latest ON readings.device_id = latest.device_id AND readings.metric = latest.metric AND readings.recorded_at = latest.recorded_at", latest).
+		Order("readings.device_id, readings.metric, readings.id DESC").
+		Find(&readings).Error; err != nil {
+		return nil, err
+	}
+	return readings, nil
+}
+
+func (r *readingRepository) ReadingFirstRecordedAt(ctx context.Context) (*time.Time, error) {
+	var reading models.Reading
+	result := r.db.WithContext(ctx).Order("recorded_at").Limit(1).Find(&reading)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &reading.RecordedAt, nil
+}
+
+func (r *readingRepository) ReadingDeleteBefore(ctx context.Context, scope models.RetentionScope, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("recorded_at < ?", cutoff).
+		Where("device_id IN (?)", retentionDevices(r.db, scope)).
+		Delete(&models.Reading{})
+	return result.RowsAffected, result.Error
+}
+
+// ReadingEach streams the matching readings to fn one row at a time, ordered by device, metric
+// and time, so exports never hold the whole result in memory.
+func (r *readingRepository) ReadingEach(ctx context.Context, deviceIDs []uint, metrics []string, from time.Time, to time.Time, fn func(reading *models.Reading) error) error {
+	db := r.db.WithContext(ctx).Model(&models.Reading{}).
+		Where("device_id IN ? AND recorded_at >= ? AND recorded_at < ?", deviceIDs, from, to)
+	if len(metrics) > 0 {
+		db = db.Where("metric IN ?", metrics)
+	}
+
+	rows, err := db.Order("device_id, metric, recorded_at").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var reading models.Reading
+		if err := r.db.WithContext(ctx).ScanRows(rows, &reading); err != nil {
+			return err
+		}
+		if err := fn(&reading); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
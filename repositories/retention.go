@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"context"
+	"home-monitor-backend/models"
+
+	"gorm.io/gorm"
+)
+
+type RetentionRepository interface {
+	RetentionPolicyFindAll(ctx context.Context) ([]models.RetentionPolicy, error)
+	RetentionPolicyFindByScope(ctx context.Context, deviceTypeID *uint, home *string) (*models.RetentionPolicy, error)
+	RetentionPolicySave(ctx context.Context, policy *models.RetentionPolicy) error
+}
+
+type retentionRepository struct {
+	db *gorm.DB
+}
+
+func NewRetentionRepository(db *gorm.DB) RetentionRepository {
+	return &retentionRepository{db: db}
+}
+
+func (r *retentionRepository) RetentionPolicyFindAll(ctx context.Context) ([]models.RetentionPolicy, error) {
+	var policies []models.RetentionPolicy
+	if err := r.db.WithContext(ctx).Preload("DeviceType").Order("id").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// RetentionPolicyFindByScope finds the policy of a device type or a home, or the default policy
+// when both are nil.
+func (r *retentionRepository) RetentionPolicyFindByScope(ctx context.Context, deviceTypeID *uint, home *string) (*models.RetentionPolicy, error) {
+	var policy models.RetentionPolicy
+	query := r.db.WithContext(ctx).Preload("DeviceType")
+	if deviceTypeID == nil {
+		query = query.Where("device_type_id IS NULL")
+	} else {
+		query = query.Where("device_type_id = ?", *deviceTypeID)
+	}
+	if home == nil {
+		query = query.Where("home IS NULL")
+	} else {
+		query = query.Where("home = ?", *home)
+	}
+	if err := query.First(&policy).Error; err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *retentionRepository) RetentionPolicySave(ctx context.Context, policy *models.RetentionPolicy) error {
+	return r.db.WithContext(ctx).Omit("DeviceType").Save(policy).Error
+}
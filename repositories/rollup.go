@@ -0,0 +1,167 @@
+package repositories
+
+import (
+	"context"
+	"home-monitor-backend/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RollupRepository interface {
+	RollupStateFind(ctx context.Context, tier models.RollupTier) (*models.RollupState, error)
+	RollupStateSave(ctx context.Context, state *models.RollupState) error
+	RollupFindBetween(ctx context.Context, tier models.RollupTier, from time.Time, to time.Time) ([]models.ReadingRollup, error)
+	RollupFindForDevice(ctx context.Context, deviceID uint, metric string, tier models.RollupTier, from time.Time, to time.Time) ([]models.ReadingRollup, error)
+	RollupUpsert(ctx context.Context, rollups []models.ReadingRollup) error
+	RollupMerge(ctx context.Context, rollups []models.ReadingRollup) error
+	RollupDeleteBefore(ctx context.Context, scope models.RetentionScope, tier models.RollupTier, cutoff time.Time) (int64, error)
+}
+
+type rollupRepository struct {
+	db *gorm.DB
+}
+
+func NewRollupRepository(db *gorm.DB) RollupRepository {
+	return &rollupRepository{db: db}
+}
+
+func (r *rollupRepository) RollupStateFind(ctx context.Context, tier models.RollupTier) (*models.RollupState, error) {
+	var state models.RollupState
+	if err := r.db.WithContext(ctx).Where("tier = ?", tier).First(&state).Error; err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func (r *rollupRepository) RollupStateSave(ctx context.Context, state *models.RollupState) error {
+	state.UpdatedAt = time.Now()
+	return r.db.WithContext(ctx).Save(state).Error
+}
+
+func (r *rollupRepository) RollupFindBetween(ctx context.Context, tier models.RollupTier, from time.Time, to time.Time) ([]models.ReadingRollup, error) {
+	var rollups []models.ReadingRollup
+	if err := r.db.WithContext(ctx).
+		Where("tier = ? AND bucket_start >= ? AND bucket_start < ?", tier, from, to).
+		Find(&rollups).Error; err != nil {
+		return nil, err
+	}
+	return rollups, nil
+}
+
+func (r *rollupRepository) RollupFindForDevice(ctx context.Context, deviceID uint, metric string, tier models.RollupTier, from time.Time, to time.Time) ([]models.ReadingRollup, error) {
+	var rollups []models.ReadingRollup
+	if err := r.db.WithContext(ctx).
+		Where("device_id = ? AND metric = ? AND tier = ? AND bucket_start >= ? AND bucket_start < ?", deviceID, metric, tier, from, to).
+		Order("bucket_start").
+		Find(&rollups).Error; err != nil {
+		return nil, err
+	}
+	return rollups, nil
+}
+
+// RollupUpsert inserts the rollups or overwrites existing buckets, so re-running a range is idempotent.
+func (r *rollupRepository) RollupUpsert(ctx context.Context, rollups []models.ReadingRollup) error {
+	return rollupUpsert(r.db.WithContext(ctx), rollups)
+}
+
+// RollupMerge adds the rollups to the buckets already stored and inserts the others. The stored
+// buckets are locked until the merged ones are written, so concurrent merges do not lose readings.
+func (r *rollupRepository) RollupMerge(ctx context.Context, rollups []models.ReadingRollup) error {
+	if len(rollups) == 0 {
+		return nil
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		type rollupKey struct {
+			deviceID uint
+			metric   string
+			tier     models.RollupTier
+			bucket   int64
+		}
+		type rollupRange struct {
+			deviceID uint
+			tier     models.RollupTier
+		}
+
+		merged := make(map[rollupKey]*models.ReadingRollup, len(rollups))
+		ranges := make(map[rollupRange][2]time.Time)
+		for i := range rollups {
+			rollup := rollups[i]
+			merged[rollupKey{rollup.DeviceID, rollup.Metric, rollup.Tier, rollup.BucketStart.Unix()}] = &rollup
+
+			key := rollupRange{rollup.DeviceID, rollup.Tier}
+			bounds, ok := ranges[key]
+			if !ok || rollup.BucketStart.Before(bounds[0]) {
+				bounds[0] = rollup.BucketStart
+			}
+			if !ok || rollup.BucketStart.After(bounds[1]) {
+				bounds[1] = rollup.BucketStart
+			}
+			ranges[key] = bounds
+		}
+
+		for key, bounds := range ranges {
+			var stored []models.ReadingRollup
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("device_id = ? AND tier = ? AND bucket_start >= ? AND bucket_start <= ?", key.deviceID, key.tier, bounds[0], bounds[1]).
+				Find(&stored).Error; err != nil {
+				return err
+			}
+			for i := range stored {
+				late, ok := merged[rollupKey{stored[i].DeviceID, stored[i].Metric, stored[i].Tier, stored[i].BucketStart.Unix()}]
+				if !ok {
+					continue
+				}
+				bucket := stored[i]
+				bucket.ID = 0
+				bucket.Merge(late)
+				*late = bucket
+			}
+		}
+
+		result := make([]models.ReadingRollup, 0, len(merged))
+		for _, rollup := range merged {
+			result = append(result, *rollup)
+		}
+		return rollupUpsert(tx, result)
+	})
+}
+
+func rollupUpsert(db *gorm.DB, rollups []models.ReadingRollup) error {
+	if len(rollups) == 0 {
+		return nil
+	}
+
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "device_id"}, {Name: "metric"}, {Name: "tier"}, {Name: "bucket_start"}},
+		DoUpdates: clause.AssignmentColumns([]string{"min", "max", "sum", "count", "last", "last_at"}),
+	}).CreateInBatches(rollups, 500).Error
+}
+
+func (r *rollupRepository) RollupDeleteBefore(ctx context.Context, scope models.RetentionScope, tier models.RollupTier, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("tier = ? AND bucket_start < ?", tier, cutoff).
+		Where("device_id IN (?)", retentionDevices(r.db, scope)).
+		Delete(&models.ReadingRollup{})
+	return result.RowsAffected, result.Error
+}
+
+// retentionDevices builds the subquery selecting the IDs of the devices a retention scope covers.
+func retentionDevices(db *gorm.DB, scope models.RetentionScope) *gorm.DB {
+	query := db.Session(&gorm.Session{NewDB: true}).Model(&models.Device{}).Select("id")
+	if scope.Home != nil {
+		return query.Where("home = ?", *scope.Home)
+	}
+	if len(scope.ExcludeHomes) > 0 {
+		query = query.Where("home NOT IN ?", scope.ExcludeHomes)
+	}
+	if scope.DeviceTypeID != nil {
+		return query.Where("device_type_id = ?", *scope.DeviceTypeID)
+	}
+	if len(scope.ExcludeDeviceTypeIDs) == 0 {
+		return query
+	}
+	return query.Where("device_type_id IS NULL OR device_type_id NOT IN ?", scope.ExcludeDeviceTypeIDs)
+}
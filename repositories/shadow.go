@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+	"home-monitor-backend/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ShadowRepository interface {
+	ShadowFindByDeviceID(ctx context.Context, deviceID uint) (*models.DeviceShadow, error)
+	ShadowCreate(ctx context.Context, shadow *models.DeviceShadow) (bool, error)
+	ShadowUpdate(ctx context.Context, shadow *models.DeviceShadow, expectedVersion uint) (bool, error)
+}
+
+type shadowRepository struct {
+	db *gorm.DB
+}
+
+func NewShadowRepository(db *gorm.DB) ShadowRepository {
+	return &shadowRepository{db: db}
+}
+
+func (r *shadowRepository) ShadowFindByDeviceID(ctx context.Context, deviceID uint) (*models.DeviceShadow, error) {
+	var shadow models.DeviceShadow
+	if err := r.db.WithContext(ctx).Where("device_id = ?", deviceID).First(&shadow).Error; err != nil {
+		return nil, err
+	}
+	return &shadow, nil
+}
+
+// ShadowCreate stores the first shadow of a device. It reports false when another writer created
+// one first.
+func (r *shadowRepository) ShadowCreate(ctx context.Context, shadow *models.DeviceShadow) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(shadow)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ShadowUpdate stores the shadow only if its stored version still equals expectedVersion.
+// It reports false when another writer got there first.
+func (r *shadowRepository) ShadowUpdate(ctx context.Context, shadow *models.DeviceShadow, expectedVersion uint) (bool, error) {
+	shadow.UpdatedAt = time.Now()
+	result := r.db.WithContext(ctx).Model(&models.DeviceShadow{}).
+		Where("id = ? AND version = ?", shadow.ID, expectedVersion).
+		Updates(map[string]any{
+			"desired":    shadow.Desired,
+			"reported":   shadow.Reported,
+			"version":    shadow.Version,
+			"updated_at": shadow.UpdatedAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
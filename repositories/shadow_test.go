@@ -0,0 +1,37 @@
+package repositories_test
+
+import (
+	"context"
+	"encoding/json"
+	"home-monitor-backend/models"
+	"home-monitor-backend/repositories"
+	"home-monitor-backend/testutil"
+	"testing"
+)
+
+func TestShadowCreateConflict(t *testing.T) {
+	t.Parallel()
+	h := testutil.New(t)
+	ctx := context.Background()
+	user := h.CreateUser("alice", "secret-password", models.UserRoleUser)
+	device := h.CreateDevice(models.Device{UserID: user.ID, Name: "lamp"})
+
+	shadowRepo := repositories.NewShadowRepository(h.DB)
+	first := &models.DeviceShadow{DeviceID: device.ID, Desired: json.RawMessage(`{"on":true}`), Version: 1}
+	if created, err := shadowRepo.ShadowCreate(ctx, first); err != nil || !created {
+		t.Fatalf("first create = %v, %v, want true", created, err)
+	}
+
+	second := &models.DeviceShadow{DeviceID: device.ID, Desired: json.RawMessage(`{"on":false}`), Version: 1}
+	if created, err := shadowRepo.ShadowCreate(ctx, second); err != nil || created {
+		t.Errorf("second create = %v, %v, want false without an error", created, err)
+	}
+
+	// Other failures are errors, not conflicts.
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	other := &models.DeviceShadow{DeviceID: device.ID + 1, Version: 1}
+	if _, err := shadowRepo.ShadowCreate(canceled, other); err == nil {
+		t.Error("create with a canceled context: want an error")
+	}
+}
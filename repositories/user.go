@@ -1,7 +1,7 @@
 package repositories
 
 import (
-	"home-monitor-backend/database"
+	"context"
 	"home-monitor-backend/models"
 
 	"github.com/google/uuid"
@@ -9,48 +9,64 @@ import (
 )
 
 type UserRepository interface {
-	UserFindByUsername(username string) (*models.User, error)
-	UserFindByUUID(uuid uuid.UUID) (*models.User, error)
-	UserCreate(user *models.User) error
-	UserUpdate(user *models.User) error
+	UserFindByUsername(ctx context.Context, username string) (*models.User, error)
+	UserFindByUUID(ctx context.Context, uuid uuid.UUID) (*models.User, error)
+	UserFindAll(ctx context.Context) ([]models.User, error)
+	UserCountByRole(ctx context.Context, role models.UserRole) (int64, error)
+	UserCreate(ctx context.Context, user *models.User) error
+	UserUpdate(ctx context.Context, user *models.User) error
 }
 
 type userRepository struct {
 	db *gorm.DB
 }
 
-func NewUserRepository() UserRepository {
-	return &userRepository{db: database.DB}
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userRepository{db: db}
 }
 
-func (r *userRepository) UserFindByUsername(username string) (*models.User, error) {
+func (r *userRepository) UserFindByUsername(ctx context.Context, username string) (*models.User, error) {
 	var user models.User
-	if err := r.db.Where("username = ?", username).First(&user).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("username = ?", username).First(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (r *userRepository) UserFindByID(id uint) (*models.User, error) {
+func (r *userRepository) UserFindByID(ctx context.Context, id uint) (*models.User, error) {
 	var user models.User
-	if err := r.db.First(&user, id).Error; err != nil {
+	if err := r.db.WithContext(ctx).First(&user, id).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (r *userRepository) UserFindByUUID(uuid uuid.UUID) (*models.User, error) {
+func (r *userRepository) UserFindByUUID(ctx context.Context, uuid uuid.UUID) (*models.User, error) {
 	var user models.User
-	if err := r.db.Where("uuid = ?", uuid).First(&user).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("uuid = ?", uuid).First(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (r *userRepository) UserCreate(user *models.User) error {
-	return r.db.Create(user).Error
+func (r *userRepository) UserFindAll(ctx context.Context) ([]models.User, error) {
+	var users []models.User
+	if err := r.db.WithContext(ctx).Order("id").Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *userRepository) UserCountByRole(ctx context.Context, role models.UserRole) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.User{}).Where("role = ?", role).Count(&count).Error
+	return count, err
+}
+
+func (r *userRepository) UserCreate(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Create(user).Error
 }
 
-func (r *userRepository) UserUpdate(user *models.User) error {
-	return r.db.Save(user).Error
+func (r *userRepository) UserUpdate(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Save(user).Error
 }
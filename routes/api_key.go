@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"home-monitor-backend/controllers"
+	"home-monitor-backend/middlewares"
+	"home-monitor-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func APIKeyRoutes(r *gin.Engine, controllers *controllers.APIKeyController, signer *utils.JWTSigner) {
+	apiAuth := r.Group("/api/api-keys")
+	apiAuth.Use(middlewares.Auth(signer))
+	{
+		apiAuth.POST("", controllers.APIKeyCreate)
+		apiAuth.GET("", controllers.APIKeyList)
+		apiAuth.DELETE("/:uuid", controllers.APIKeyDelete)
+	}
+}
@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"home-monitor-backend/controllers"
+	"home-monitor-backend/middlewares"
+	"home-monitor-backend/services"
+	"home-monitor-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func CommandRoutes(r *gin.Engine, controllers *controllers.CommandController, signer *utils.JWTSigner, deviceService services.DeviceService) {
+	apiAuth := r.Group("/api/devices/:uuid/commands")
+	apiAuth.Use(middlewares.Auth(signer))
+	{
+		apiAuth.POST("", controllers.CommandCreate)
+		apiAuth.GET("", controllers.CommandList)
+		apiAuth.GET("/:command_uuid", controllers.CommandDetail)
+	}
+
+	apiDevice := r.Group("/api/device/commands")
+	apiDevice.Use(middlewares.DeviceAuth(deviceService))
+	{
+		apiDevice.GET("", controllers.CommandPull)
+		apiDevice.POST("/:command_uuid/ack", controllers.CommandAck)
+	}
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"home-monitor-backend/controllers"
+	"home-monitor-backend/middlewares"
+	"home-monitor-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func DeviceRoutes(r *gin.Engine, controllers *controllers.DeviceController, signer *utils.JWTSigner) {
+	apiAuth := r.Group("/api/devices")
+	apiAuth.Use(middlewares.Auth(signer))
+	{
+		apiAuth.POST("", controllers.DeviceCreate)
+		apiAuth.GET("", controllers.DeviceList)
+		apiAuth.GET("/:uuid", controllers.DeviceDetail)
+		apiAuth.PUT("/:uuid", controllers.DeviceUpdate)
+	}
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"home-monitor-backend/controllers"
+	"home-monitor-backend/middlewares"
+	"home-monitor-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func DeviceTypeRoutes(r *gin.Engine, controllers *controllers.DeviceTypeController, signer *utils.JWTSigner) {
+	apiAuth := r.Group("/api/device-types")
+	apiAuth.Use(middlewares.Auth(signer))
+	{
+		apiAuth.POST("", controllers.DeviceTypeCreate)
+		apiAuth.GET("", controllers.DeviceTypeList)
+		apiAuth.GET("/:uuid", controllers.DeviceTypeDetail)
+	}
+}
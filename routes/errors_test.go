@@ -0,0 +1,137 @@
+package routes_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"home-monitor-backend/middlewares"
+	"home-monitor-backend/models"
+	"home-monitor-backend/testutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestErrorResponses(t *testing.T) {
+	t.Parallel()
+	h := testutil.New(t)
+	h.CreateUser("admin", "admin-password", models.UserRoleAdmin)
+	h.CreateUser("alice", "alice-password", models.UserRoleUser)
+	admin := h.Login("admin", "admin-password")
+	alice := h.Login("alice", "alice-password")
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		authorization string
+		body          any
+		wantStatus    int
+		wantCode      string
+		wantField     string
+	}{
+		{"wrong password", http.MethodPost, "/api/user/login", "", models.UserLoginRequest{Username: "alice", Password: "wrong-password"}, http.StatusUnauthorized, "invalid_credentials", ""},
+		{"missing token", http.MethodGet, "/api/user/profile", "", nil, http.StatusUnauthorized, "token_required", ""},
+		{"invalid token", http.MethodGet, "/api/user/profile", "Bearer not-a-token", nil, http.StatusUnauthorized, "invalid_token", ""},
+		{"not an admin", http.MethodPost, "/api/user/register", alice, models.UserRegisterRequest{Username: "bob", Password: "bob-password"}, http.StatusForbidden, "admin_required", ""},
+		{"username taken", http.MethodPost, "/api/user/register", admin, models.UserRegisterRequest{Username: "alice", Password: "alice-password"}, http.StatusConflict, "username_taken", ""},
+		{"invalid field", http.MethodPost, "/api/user/register", admin, models.UserRegisterRequest{Username: "bob", Password: "abc"}, http.StatusBadRequest, "validation_failed", "password"},
+		{"malformed body", http.MethodPost, "/api/user/login", "", "not an object", http.StatusBadRequest, "invalid_request", ""},
+		{"unknown route", http.MethodGet, "/api/nowhere", "", nil, http.StatusNotFound, "route_not_found", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := h.Do(tt.method, tt.path, tt.authorization, tt.body)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if contentType := w.Header().Get("Content-Type"); contentType != middlewares.ProblemContentType {
+				t.Errorf("content type = %q, want %q", contentType, middlewares.ProblemContentType)
+			}
+
+			problem := testutil.Decode[models.ProblemDetails](t, w)
+			if problem.Status != tt.wantStatus || problem.Code != tt.wantCode {
+				t.Errorf("problem = %+v, want status %d and code %q", problem, tt.wantStatus, tt.wantCode)
+			}
+			if problem.Title != http.StatusText(tt.wantStatus) || problem.Instance != tt.path {
+				t.Errorf("problem = %+v, want title %q and instance %q", problem, http.StatusText(tt.wantStatus), tt.path)
+			}
+			if tt.wantField != "" && problem.Errors[tt.wantField] == "" {
+				t.Errorf("errors = %v, want a message for %s", problem.Errors, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestValidationLanguages(t *testing.T) {
+	t.Parallel()
+	h := testutil.New(t)
+
+	tests := []struct {
+		acceptLanguage string
+		want           string
+	}{
+		{"", "username is a required field"},
+		{"en-US,en;q=0.9", "username is a required field"},
+		{"id-ID,id;q=0.9,en;q=0.8", "username wajib diisi"},
+		{"fr-FR, id;q=0.5", "username wajib diisi"},
+		{"de", "username is a required field"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.acceptLanguage, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/user/login", strings.NewReader(`{"password":"secret-password"}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept-Language", tt.acceptLanguage)
+			w := httptest.NewRecorder()
+			h.Router.ServeHTTP(w, req)
+
+			problem := testutil.Decode[models.ProblemDetails](t, w)
+			if got := problem.Errors["username"]; got != tt.want {
+				t.Errorf("errors = %v, want username: %q", problem.Errors, tt.want)
+			}
+		})
+	}
+}
+
+func TestProblemLanguages(t *testing.T) {
+	t.Parallel()
+	h := testutil.New(t)
+	h.CreateUser("alice", "alice-password", models.UserRoleUser)
+	alice := h.Login("alice", "alice-password")
+	wrongPassword := models.UserLoginRequest{Username: "alice", Password: "wrong-password"}
+
+	tests := []struct {
+		name           string
+		path           string
+		authorization  string
+		body           any
+		acceptLanguage string
+		wantTitle      string
+		wantDetail     string
+	}{
+		{"english", "/api/user/login", "", wrongPassword, "en", "Unauthorized", "invalid username or password"},
+		{"indonesian", "/api/user/login", "", wrongPassword, "id-ID,id;q=0.9", "Tidak Terautentikasi", "nama pengguna atau kata sandi salah"},
+		{"unsupported", "/api/user/login", "", wrongPassword, "de", "Unauthorized", "invalid username or password"},
+		{"detail with a parameter", "/api/user/register", alice, models.UserRegisterRequest{Username: "bob", Password: "bob-password"}, "id", "Dilarang", "hanya admin yang dapat mendaftarkan pengguna baru"},
+		{"english detail with a parameter", "/api/user/register", alice, models.UserRegisterRequest{Username: "bob", Password: "bob-password"}, "en", "Forbidden", "only admin can register new users"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := json.Marshal(tt.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := httptest.NewRequest(http.MethodPost, tt.path, bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", tt.authorization)
+			req.Header.Set("Accept-Language", tt.acceptLanguage)
+			w := httptest.NewRecorder()
+			h.Router.ServeHTTP(w, req)
+
+			problem := testutil.Decode[models.ProblemDetails](t, w)
+			if problem.Title != tt.wantTitle || problem.Detail != tt.wantDetail {
+				t.Errorf("problem = %+v, want title %q and detail %q", problem, tt.wantTitle, tt.wantDetail)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"home-monitor-backend/controllers"
+	"home-monitor-backend/middlewares"
+	"home-monitor-backend/ratelimit"
+	"home-monitor-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func ExportRoutes(r *gin.Engine, controllers *controllers.ExportController, signer *utils.JWTSigner, limiter *ratelimit.Limiter) {
+	apiAuth := r.Group("/api/export")
+	apiAuth.Use(middlewares.Auth(signer), middlewares.RateLimit(limiter, ratelimit.PolicyQuery, middlewares.RateLimitByUser))
+	{
+		apiAuth.GET("/readings", controllers.ExportReadings)
+		apiAuth.POST("/jobs", controllers.ExportJobCreate)
+		apiAuth.GET("/jobs", controllers.ExportJobList)
+		apiAuth.GET("/jobs/:uuid", controllers.ExportJobDetail)
+		apiAuth.GET("/jobs/:uuid/download", controllers.ExportJobDownload)
+	}
+}
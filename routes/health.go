@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"home-monitor-backend/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func HealthRoutes(r *gin.Engine, controllers *controllers.HealthController) {
+	r.GET("/healthz", controllers.HealthLive)
+	r.GET("/readyz", controllers.HealthReady)
+}
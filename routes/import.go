@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"home-monitor-backend/controllers"
+	"home-monitor-backend/middlewares"
+	"home-monitor-backend/ratelimit"
+	"home-monitor-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func ImportRoutes(r *gin.Engine, controllers *controllers.ImportController, signer *utils.JWTSigner, limiter *ratelimit.Limiter) {
+	apiAuth := r.Group("/api/devices/:uuid/imports")
+	apiAuth.Use(middlewares.Auth(signer))
+	{
+		apiAuth.POST("", middlewares.RateLimit(limiter, ratelimit.PolicyQuery, middlewares.RateLimitByUser), controllers.ImportCreate)
+		apiAuth.GET("", controllers.ImportList)
+		apiAuth.GET("/:import_uuid", controllers.ImportDetail)
+	}
+}
@@ -0,0 +1,88 @@
+package routes_test
+
+import (
+	"bytes"
+	"home-monitor-backend/controllers"
+	"home-monitor-backend/middlewares"
+	"home-monitor-backend/models"
+	"home-monitor-backend/ratelimit"
+	"home-monitor-backend/repositories"
+	"home-monitor-backend/routes"
+	"home-monitor-backend/services"
+	"home-monitor-backend/testutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// importRouter serves the import routes of h with uploads limited to maxBytes.
+func importRouter(t *testing.T, h *testutil.Harness, maxBytes int64, limiter *ratelimit.Limiter) *gin.Engine {
+	t.Helper()
+
+	readingRepo := repositories.NewReadingRepository(h.DB)
+	rollupRepo := repositories.NewRollupRepository(h.DB)
+	importService := services.NewImportService(
+		repositories.NewUserRepository(h.DB),
+		repositories.NewDeviceRepository(h.DB),
+		readingRepo,
+		repositories.NewImportRepository(h.DB),
+		services.NewRollupService(readingRepo, rollupRepo),
+		t.TempDir(),
+	)
+
+	r := gin.New()
+	r.Use(middlewares.Errors())
+	routes.ImportRoutes(r, controllers.NewImportController(importService, maxBytes), h.Signer, limiter)
+	return r
+}
+
+func importUpload(t *testing.T, r *gin.Engine, path string, token string, csv string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if err := form.WriteField("mapping", `{"timestamp_column":"time","metrics":[{"column":"temp","metric":"temperature"}]}`); err != nil {
+		t.Fatal(err)
+	}
+	file, err := form.CreateFormFile("file", "readings.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Write([]byte(csv))
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, path, &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Authorization", token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestImportUploadLimit(t *testing.T) {
+	t.Parallel()
+
+	h := testutil.New(t)
+	user := h.CreateUser("alice", "secret-password", models.UserRoleUser)
+	device := h.CreateDevice(models.Device{UserID: user.ID, Name: "thermometer"})
+	token := h.Login("alice", "secret-password")
+	r := importRouter(t, h, 4096, &ratelimit.Limiter{})
+	path := "/api/devices/" + device.UUID.String() + "/imports"
+
+	w := importUpload(t, r, path, token, "time,temp\n2024-01-01T00:00:00Z,20\n")
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("small upload: status = %d, want %d: %s", w.Code, http.StatusAccepted, w.Body)
+	}
+
+	w = importUpload(t, r, path, token, "time,temp\n"+strings.Repeat("2024-01-01T00:00:00Z,20\n", 1000))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("large upload: status = %d, want %d: %s", w.Code, http.StatusRequestEntityTooLarge, w.Body)
+	}
+	if problem := testutil.Decode[models.ProblemDetails](t, w); problem.Code != "file_too_large" {
+		t.Errorf("code = %q, want file_too_large", problem.Code)
+	}
+}
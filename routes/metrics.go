@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"home-monitor-backend/middlewares"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+func MetricsRoute(r *gin.Engine, token string) {
+	r.GET("/metrics", middlewares.MetricsAuth(token), gin.WrapH(promhttp.Handler()))
+}
@@ -0,0 +1,210 @@
+package routes_test
+
+import (
+	"home-monitor-backend/controllers"
+	"home-monitor-backend/middlewares"
+	"home-monitor-backend/models"
+	"home-monitor-backend/ratelimit"
+	"home-monitor-backend/repositories"
+	"home-monitor-backend/routes"
+	"home-monitor-backend/services"
+	"home-monitor-backend/testutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimitedRouter serves the user routes of h with a login policy of 3 requests per minute.
+func rateLimitedRouter(h *testutil.Harness, store ratelimit.Store) *gin.Engine {
+	limiter := &ratelimit.Limiter{
+		Store:    store,
+		Policies: map[string]ratelimit.Policy{ratelimit.PolicyLogin: {Limit: 3, Period: time.Minute}},
+	}
+	userService := services.NewUserService(repositories.NewUserRepository(h.DB), h.Signer)
+
+	r := gin.New()
+	r.Use(middlewares.Errors())
+	routes.UserRoutes(r, controllers.NewUserController(userService), h.Signer, limiter)
+	return r
+}
+
+func login(r *gin.Engine, remoteAddr string, forwardedFor ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/user/login", strings.NewReader(`{"username":"alice","password":"secret-password"}`))
+	req.Header.Set("Content-Type", "application/json")
+	for _, address := range forwardedFor {
+		req.Header.Add("X-Forwarded-For", address)
+	}
+	req.RemoteAddr = remoteAddr
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestLoginRateLimit(t *testing.T) {
+	t.Parallel()
+
+	stores := map[string]func(h *testutil.Harness) ratelimit.Store{
+		"memory": func(h *testutil.Harness) ratelimit.Store {
+			return ratelimit.NewMemoryStore()
+		},
+		"database": func(h *testutil.Harness) ratelimit.Store {
+			return ratelimit.NewDatabaseStore(repositories.NewRateLimitRepository(h.DB))
+		},
+	}
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			h := testutil.New(t)
+			h.CreateUser("alice", "secret-password", models.UserRoleUser)
+			r := rateLimitedRouter(h, newStore(h))
+
+			for _, wantRemaining := range []string{"2", "1", "0"} {
+				w := login(r, "192.0.2.1:1234")
+				if w.Code != http.StatusOK {
+					t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+				}
+				if got := w.Header().Get("RateLimit-Remaining"); got != wantRemaining {
+					t.Errorf("RateLimit-Remaining = %q, want %q", got, wantRemaining)
+				}
+				if limit, policy := w.Header().Get("RateLimit-Limit"), w.Header().Get("RateLimit-Policy"); limit != "3" || policy != "3;w=60" {
+					t.Errorf("RateLimit-Limit = %q and RateLimit-Policy = %q, want 3 and 3;w=60", limit, policy)
+				}
+			}
+
+			w := login(r, "192.0.2.1:1234")
+			if w.Code != http.StatusTooManyRequests {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusTooManyRequests, w.Body)
+			}
+			if problem := testutil.Decode[models.ProblemDetails](t, w); problem.Code != "rate_limited" {
+				t.Errorf("code = %q, want rate_limited", problem.Code)
+			}
+			// A token is refilled every 20 seconds, less the time the logins took.
+			if got, err := strconv.Atoi(w.Header().Get("Retry-After")); err != nil || got < 1 || got > 20 {
+				t.Errorf("Retry-After = %d, want 1 to 20 seconds", got)
+			}
+			if got, err := strconv.Atoi(w.Header().Get("RateLimit-Reset")); err != nil || got < 41 || got > 60 {
+				t.Errorf("RateLimit-Reset = %d, want 41 to 60 seconds", got)
+			}
+
+			// Other clients have buckets of their own.
+			if w := login(r, "192.0.2.2:1234"); w.Code != http.StatusOK {
+				t.Errorf("other client: status = %d, want %d", w.Code, http.StatusOK)
+			}
+		})
+	}
+}
+
+func TestLoginRateLimitConcurrent(t *testing.T) {
+	t.Parallel()
+	h := testutil.New(t)
+	h.CreateUser("alice", "secret-password", models.UserRoleUser)
+
+	// Two routers sharing the database stand in for two instances of the API.
+	instances := []*gin.Engine{
+		rateLimitedRouter(h, ratelimit.NewDatabaseStore(repositories.NewRateLimitRepository(h.DB))),
+		rateLimitedRouter(h, ratelimit.NewDatabaseStore(repositories.NewRateLimitRepository(h.DB))),
+	}
+
+	var mu sync.Mutex
+	codes := make(map[int]int)
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := login(instances[i%len(instances)], "192.0.2.1:1234")
+			mu.Lock()
+			codes[w.Code]++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if codes[http.StatusOK] != 3 || codes[http.StatusTooManyRequests] != 7 {
+		t.Errorf("status counts = %v, want 3 OK and 7 Too Many Requests", codes)
+	}
+}
+
+func TestLoginRateLimitTrustedProxies(t *testing.T) {
+	t.Parallel()
+	h := testutil.New(t)
+	h.CreateUser("alice", "secret-password", models.UserRoleUser)
+	r := rateLimitedRouter(h, ratelimit.NewMemoryStore())
+	if err := r.SetTrustedProxies([]string{"10.0.0.0/8"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A client cannot escape its bucket by claiming to forward for others.
+	for i := range 3 {
+		if w := login(r, "192.0.2.1:1234", "198.51.100."+strconv.Itoa(i+1)); w.Code != http.StatusOK {
+			t.Fatalf("spoofed request %d: status = %d, want %d", i+1, w.Code, http.StatusOK)
+		}
+	}
+	if w := login(r, "192.0.2.1:1234", "198.51.100.4"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("spoofed request 4: status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+
+	// The clients behind a trusted proxy have buckets of their own.
+	for i := range 4 {
+		if w := login(r, "10.0.0.1:1234", "198.51.100."+strconv.Itoa(i+1)); w.Code != http.StatusOK {
+			t.Errorf("client %d behind the proxy: status = %d, want %d", i+1, w.Code, http.StatusOK)
+		}
+	}
+}
+
+// TestQueryRateLimit checks that the expensive reads and uploads outside the readings routes take
+// their tokens from the query policy of the user.
+func TestQueryRateLimit(t *testing.T) {
+	t.Parallel()
+	h := testutil.New(t)
+	user := h.CreateUser("alice", "secret-password", models.UserRoleUser)
+	device := h.CreateDevice(models.Device{UserID: user.ID, Name: "thermometer"})
+	token := h.Login("alice", "secret-password")
+
+	limiter := func() *ratelimit.Limiter {
+		return &ratelimit.Limiter{
+			Store:    ratelimit.NewMemoryStore(),
+			Policies: map[string]ratelimit.Policy{ratelimit.PolicyQuery: {Limit: 1, Period: time.Minute}},
+		}
+	}
+
+	t.Run("export", func(t *testing.T) {
+		exportService := services.NewExportService(
+			repositories.NewUserRepository(h.DB),
+			repositories.NewDeviceRepository(h.DB),
+			repositories.NewReadingRepository(h.DB),
+			repositories.NewExportRepository(h.DB),
+			t.TempDir(),
+		)
+		r := gin.New()
+		r.Use(middlewares.Errors())
+		routes.ExportRoutes(r, controllers.NewExportController(exportService), h.Signer, limiter())
+
+		path := "/api/export/readings?device_uuid=" + device.UUID.String() + "&from=2024-01-01T00:00:00Z&to=2024-01-02T00:00:00Z"
+		for _, want := range []int{http.StatusOK, http.StatusTooManyRequests} {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.Header.Set("Authorization", token)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, want, w.Body)
+			}
+		}
+	})
+
+	t.Run("import", func(t *testing.T) {
+		r := importRouter(t, h, 4096, limiter())
+		path := "/api/devices/" + device.UUID.String() + "/imports"
+		for _, want := range []int{http.StatusAccepted, http.StatusTooManyRequests} {
+			if w := importUpload(t, r, path, token, "time,temp\n2024-01-01T00:00:00Z,20\n"); w.Code != want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, want, w.Body)
+			}
+		}
+	})
+}
@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"home-monitor-backend/controllers"
+	"home-monitor-backend/middlewares"
+	"home-monitor-backend/ratelimit"
+	"home-monitor-backend/services"
+	"home-monitor-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func ReadingRoutes(r *gin.Engine, controllers *controllers.ReadingController, signer *utils.JWTSigner, deviceService services.DeviceService, apiKeyService services.APIKeyService, limiter *ratelimit.Limiter) {
+	apiAuth := r.Group("/api/devices/:uuid/readings")
+	apiAuth.Use(middlewares.Auth(signer), middlewares.RateLimit(limiter, ratelimit.PolicyQuery, middlewares.RateLimitByUser))
+	{
+		apiAuth.GET("", controllers.ReadingList)
+		apiAuth.GET("/aggregate", controllers.ReadingAggregate)
+	}
+
+	apiDevice := r.Group("/api/device/readings")
+	apiDevice.Use(middlewares.DeviceAuth(deviceService), middlewares.RateLimit(limiter, ratelimit.PolicyIngest, middlewares.RateLimitByDevice))
+	{
+		apiDevice.POST("", controllers.ReadingIngest)
+	}
+
+	apiKey := r.Group("/api/metrics/readings")
+	apiKey.Use(middlewares.APIKeyAuth(apiKeyService), middlewares.RateLimit(limiter, ratelimit.PolicyQuery, middlewares.RateLimitByAPIKey))
+	{
+		apiKey.GET("", controllers.ReadingPrometheus)
+	}
+}
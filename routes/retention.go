@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"home-monitor-backend/controllers"
+	"home-monitor-backend/middlewares"
+	"home-monitor-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RetentionRoutes(r *gin.Engine, controllers *controllers.RetentionController, signer *utils.JWTSigner) {
+	apiAuth := r.Group("/api/retention-policies")
+	apiAuth.Use(middlewares.Auth(signer))
+	{
+		apiAuth.GET("", controllers.RetentionList)
+		apiAuth.PUT("", controllers.RetentionSave)
+	}
+}
@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"home-monitor-backend/apperrors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -12,4 +13,8 @@ func RootRoute(r *gin.Engine) {
 			"message": "Welcome to the Home Monitor API",
 		})
 	})
+
+	r.NoRoute(func(c *gin.Context) {
+		c.Error(apperrors.NotFound("route_not_found", "Route not found"))
+	})
 }
@@ -0,0 +1,108 @@
+package routes_test
+
+import (
+	"home-monitor-backend/config"
+	"home-monitor-backend/middlewares"
+	"home-monitor-backend/routes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func securedRouter(cors config.CORS) *gin.Engine {
+	r := gin.New()
+	r.Use(middlewares.Errors(), middlewares.SecurityHeaders(time.Hour), middlewares.CORS(cors))
+	routes.RootRoute(r)
+	r.GET("/swagger/*any", func(c *gin.Context) { c.String(http.StatusOK, "swagger") })
+	return r
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	t.Parallel()
+	r := securedRouter(config.CORS{})
+
+	tests := []struct {
+		path    string
+		wantCSP string
+	}{
+		{"/", "default-src 'none'"},
+		{"/api/nowhere", "default-src 'none'"},
+		{"/swagger/index.html", "script-src 'self' 'unsafe-inline'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			for header, want := range map[string]string{
+				"X-Content-Type-Options":    "nosniff",
+				"X-Frame-Options":           "DENY",
+				"Strict-Transport-Security": "max-age=3600",
+			} {
+				if got := w.Header().Get(header); got != want {
+					t.Errorf("%s = %q, want %q", header, got, want)
+				}
+			}
+			if csp := w.Header().Get("Content-Security-Policy"); !strings.Contains(csp, tt.wantCSP) {
+				t.Errorf("Content-Security-Policy = %q, want it to contain %q", csp, tt.wantCSP)
+			}
+		})
+	}
+}
+
+func TestCORS(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		cors            config.CORS
+		method          string
+		origin          string
+		wantStatus      int
+		wantAllowOrigin string
+		wantCredentials string
+		wantMaxAge      string
+	}{
+		{"allowed origin", config.CORS{AllowedOrigins: []string{"https://app.example.com"}, AllowCredentials: true}, http.MethodGet, "https://app.example.com", http.StatusOK, "https://app.example.com", "true", ""},
+		{"other origin", config.CORS{AllowedOrigins: []string{"https://app.example.com"}}, http.MethodGet, "https://evil.example.com", http.StatusOK, "", "", ""},
+		{"same origin", config.CORS{AllowedOrigins: []string{"https://app.example.com"}}, http.MethodGet, "", http.StatusOK, "", "", ""},
+		{"any origin", config.CORS{AllowedOrigins: []string{"*"}}, http.MethodGet, "https://evil.example.com", http.StatusOK, "*", "", ""},
+		{"preflight", config.CORS{AllowedOrigins: []string{"https://app.example.com"}, MaxAge: 10 * time.Minute}, http.MethodOptions, "https://app.example.com", http.StatusNoContent, "https://app.example.com", "", "600"},
+		{"preflight of other origin", config.CORS{AllowedOrigins: []string{"https://app.example.com"}}, http.MethodOptions, "https://evil.example.com", http.StatusForbidden, "", "", ""},
+		{"preflight without CORS", config.CORS{}, http.MethodOptions, "https://app.example.com", http.StatusForbidden, "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.method == http.MethodOptions {
+				req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+				req.Header.Set("Access-Control-Request-Headers", "authorization,content-type")
+			}
+			w := httptest.NewRecorder()
+			securedRouter(tt.cors).ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantAllowOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantAllowOrigin)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Credentials"); got != tt.wantCredentials {
+				t.Errorf("Access-Control-Allow-Credentials = %q, want %q", got, tt.wantCredentials)
+			}
+			if got := w.Header().Get("Access-Control-Max-Age"); got != tt.wantMaxAge {
+				t.Errorf("Access-Control-Max-Age = %q, want %q", got, tt.wantMaxAge)
+			}
+			if tt.wantStatus == http.StatusNoContent && !strings.Contains(w.Header().Get("Access-Control-Allow-Headers"), "Authorization") {
+				t.Errorf("Access-Control-Allow-Headers = %q, want Authorization", w.Header().Get("Access-Control-Allow-Headers"))
+			}
+		})
+	}
+}
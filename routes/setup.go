@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"home-monitor-backend/controllers"
+	"home-monitor-backend/middlewares"
+	"home-monitor-backend/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupRoutes(r *gin.Engine, controllers *controllers.SetupController, limiter *ratelimit.Limiter) {
+	api := r.Group("/api/setup")
+	{
+		api.GET("", controllers.SetupStatus)
+		api.POST("", middlewares.RateLimit(limiter, ratelimit.PolicyLogin, middlewares.RateLimitByIP), controllers.SetupComplete)
+	}
+}
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"home-monitor-backend/controllers"
+	"home-monitor-backend/middlewares"
+	"home-monitor-backend/services"
+	"home-monitor-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func ShadowRoutes(r *gin.Engine, controllers *controllers.ShadowController, signer *utils.JWTSigner, deviceService services.DeviceService) {
+	apiAuth := r.Group("/api/devices/:uuid/shadow")
+	apiAuth.Use(middlewares.Auth(signer))
+	{
+		apiAuth.GET("", controllers.ShadowGet)
+		apiAuth.PATCH("", controllers.ShadowUpdate)
+		apiAuth.GET("/events", controllers.ShadowEvents)
+	}
+
+	apiDevice := r.Group("/api/device/shadow")
+	apiDevice.Use(middlewares.DeviceAuth(deviceService))
+	{
+		apiDevice.GET("", controllers.ShadowDeviceGet)
+		apiDevice.PATCH("", controllers.ShadowDeviceUpdate)
+		apiDevice.GET("/events", controllers.ShadowDeviceEvents)
+	}
+}
@@ -3,21 +3,24 @@ package routes
 import (
 	"home-monitor-backend/controllers"
 	"home-monitor-backend/middlewares"
+	"home-monitor-backend/ratelimit"
+	"home-monitor-backend/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
-func UserRoutes(r *gin.Engine, controllers *controllers.UserController) {
+func UserRoutes(r *gin.Engine, controllers *controllers.UserController, signer *utils.JWTSigner, limiter *ratelimit.Limiter) {
 	api := r.Group("/api/user")
 	{
-		api.POST("/login", controllers.UserLogin)
+		api.POST("/login", middlewares.RateLimit(limiter, ratelimit.PolicyLogin, middlewares.RateLimitByIP), controllers.UserLogin)
 	}
 
 	apiAuth := r.Group("/api/user")
-	apiAuth.Use(middlewares.Auth())
+	apiAuth.Use(middlewares.Auth(signer))
 	{
 		apiAuth.POST("/register", controllers.UserRegister)
 		apiAuth.GET("/profile", controllers.UserProfile)
 		apiAuth.PUT("/update", controllers.UserUpdate)
+		apiAuth.PUT("/preferences", controllers.UserPreferences)
 	}
 }
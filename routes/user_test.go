@@ -0,0 +1,230 @@
+package routes_test
+
+import (
+	"home-monitor-backend/models"
+	"home-monitor-backend/testutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestUserLogin(t *testing.T) {
+	t.Parallel()
+	h := testutil.New(t)
+	user := h.CreateUser("alice", "secret-password", models.UserRoleUser)
+
+	tests := []struct {
+		name     string
+		body     any
+		wantCode int
+	}{
+		{"valid credentials", models.UserLoginRequest{Username: "alice", Password: "secret-password"}, http.StatusOK},
+		{"wrong password", models.UserLoginRequest{Username: "alice", Password: "wrong-password"}, http.StatusUnauthorized},
+		{"unknown user", models.UserLoginRequest{Username: "bob", Password: "secret-password"}, http.StatusUnauthorized},
+		{"password too short", models.UserLoginRequest{Username: "alice", Password: "abc"}, http.StatusBadRequest},
+		{"missing body", nil, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := h.Do(http.MethodPost, "/api/user/login", "", tt.body)
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantCode, w.Body)
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			response := testutil.Decode[models.UserLoginResponse](t, w)
+			if response.UUID != user.UUID || response.Username != "alice" {
+				t.Errorf("response = %+v, want user %s", response, user.UUID)
+			}
+			if !strings.HasPrefix(response.Token, "Bearer ") {
+				t.Errorf("token = %q, want a Bearer token", response.Token)
+			}
+		})
+	}
+}
+
+func TestUserRegister(t *testing.T) {
+	t.Parallel()
+	h := testutil.New(t)
+	h.CreateUser("admin", "admin-password", models.UserRoleAdmin)
+	h.CreateUser("alice", "alice-password", models.UserRoleUser)
+	admin := h.Login("admin", "admin-password")
+	alice := h.Login("alice", "alice-password")
+
+	tests := []struct {
+		name          string
+		authorization string
+		body          any
+		wantCode      int
+	}{
+		{"admin registers a user", admin, models.UserRegisterRequest{Username: "bob", Password: "bob-password"}, http.StatusCreated},
+		{"username taken", admin, models.UserRegisterRequest{Username: "alice", Password: "other-password"}, http.StatusConflict},
+		{"not an admin", alice, models.UserRegisterRequest{Username: "carol", Password: "carol-password"}, http.StatusForbidden},
+		{"not logged in", "", models.UserRegisterRequest{Username: "carol", Password: "carol-password"}, http.StatusUnauthorized},
+		{"invalid token", "Bearer not-a-token", models.UserRegisterRequest{Username: "carol", Password: "carol-password"}, http.StatusUnauthorized},
+		{"username too short", admin, models.UserRegisterRequest{Username: "cj", Password: "carol-password"}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := h.Do(http.MethodPost, "/api/user/register", tt.authorization, tt.body)
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantCode, w.Body)
+			}
+		})
+	}
+
+	// The registered user can log in and is not an admin.
+	bob := h.Login("bob", "bob-password")
+	w := h.Do(http.MethodPost, "/api/user/register", bob, models.UserRegisterRequest{Username: "dave", Password: "dave-password"})
+	if w.Code != http.StatusForbidden {
+		t.Errorf("register as new user: status = %d, want %d", w.Code, http.StatusForbidden)
+	}
+}
+
+func TestUserProfile(t *testing.T) {
+	t.Parallel()
+	h := testutil.New(t)
+	user := h.CreateUser("alice", "alice-password", models.UserRoleUser)
+	alice := h.Login("alice", "alice-password")
+
+	w := h.Do(http.MethodGet, "/api/user/profile", alice, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+	profile := testutil.Decode[models.UserProfileResponse](t, w)
+	if profile.UUID != user.UUID || profile.Username != "alice" {
+		t.Errorf("profile = %+v, want alice", profile)
+	}
+	if profile.Preferences.Timezone != "UTC" || len(profile.Preferences.Units) != 0 {
+		t.Errorf("preferences = %+v, want the defaults", profile.Preferences)
+	}
+
+	if w := h.Do(http.MethodGet, "/api/user/profile", "", nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("without token: status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+
+	// A valid token of a user that no longer exists.
+	if err := h.DB.Delete(user).Error; err != nil {
+		t.Fatal(err)
+	}
+	if w := h.Do(http.MethodGet, "/api/user/profile", alice, nil); w.Code != http.StatusNotFound {
+		t.Errorf("deleted user: status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestUserUpdate(t *testing.T) {
+	t.Parallel()
+	userRole := models.UserRoleUser
+	adminRole := models.UserRoleAdmin
+
+	tests := []struct {
+		name     string
+		as       string
+		body     models.UserUpdateRequest
+		wantCode int
+		// login is the username and password that work afterwards.
+		login [2]string
+	}{
+		{
+			name:     "change password",
+			as:       "alice",
+			body:     models.UserUpdateRequest{Username: "alice", Password: "alice-password", NewPassword: "new-password"},
+			wantCode: http.StatusOK,
+			login:    [2]string{"alice", "new-password"},
+		},
+		{
+			name:     "change username",
+			as:       "alice",
+			body:     models.UserUpdateRequest{Username: "alice", Password: "alice-password", NewUsername: "alicia"},
+			wantCode: http.StatusOK,
+			login:    [2]string{"alicia", "alice-password"},
+		},
+		{
+			name:     "wrong password",
+			as:       "alice",
+			body:     models.UserUpdateRequest{Username: "alice", Password: "wrong-password", NewPassword: "new-password"},
+			wantCode: http.StatusUnauthorized,
+			login:    [2]string{"alice", "alice-password"},
+		},
+		{
+			name:     "no credentials",
+			as:       "alice",
+			body:     models.UserUpdateRequest{NewPassword: "new-password"},
+			wantCode: http.StatusBadRequest,
+			login:    [2]string{"alice", "alice-password"},
+		},
+		{
+			name:     "nothing to change",
+			as:       "alice",
+			body:     models.UserUpdateRequest{Username: "alice", Password: "alice-password"},
+			wantCode: http.StatusBadRequest,
+			login:    [2]string{"alice", "alice-password"},
+		},
+		{
+			name:     "user cannot change a role",
+			as:       "alice",
+			body:     models.UserUpdateRequest{Username: "alice", Password: "alice-password", Role: &adminRole},
+			wantCode: http.StatusForbidden,
+			login:    [2]string{"alice", "alice-password"},
+		},
+		{
+			name:     "admin changes the role of a user",
+			as:       "admin",
+			body:     models.UserUpdateRequest{Username: "alice", Password: "alice-password", Role: &adminRole},
+			wantCode: http.StatusOK,
+			login:    [2]string{"alice", "alice-password"},
+		},
+		{
+			name:     "admin cannot change their own role",
+			as:       "admin",
+			body:     models.UserUpdateRequest{Username: "admin", Password: "admin-password", Role: &userRole},
+			wantCode: http.StatusForbidden,
+			login:    [2]string{"admin", "admin-password"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := testutil.New(t)
+			h.CreateUser("admin", "admin-password", models.UserRoleAdmin)
+			h.CreateUser("alice", "alice-password", models.UserRoleUser)
+			authorization := h.Login(tt.as, tt.as+"-password")
+
+			w := h.Do(http.MethodPut, "/api/user/update", authorization, tt.body)
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantCode, w.Body)
+			}
+			if tt.wantCode == http.StatusOK {
+				response := testutil.Decode[models.UserRegisterResponse](t, w)
+				if response.Username != tt.login[0] {
+					t.Errorf("username = %q, want %q", response.Username, tt.login[0])
+				}
+			}
+
+			h.Login(tt.login[0], tt.login[1])
+		})
+	}
+}
+
+func TestUserUpdateRole(t *testing.T) {
+	t.Parallel()
+	h := testutil.New(t)
+	h.CreateUser("admin", "admin-password", models.UserRoleAdmin)
+	h.CreateUser("alice", "alice-password", models.UserRoleUser)
+	admin := h.Login("admin", "admin-password")
+	adminRole := models.UserRoleAdmin
+
+	w := h.Do(http.MethodPut, "/api/user/update", admin, models.UserUpdateRequest{Username: "alice", Password: "alice-password", Role: &adminRole})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	// As an admin, alice can now register users.
+	alice := h.Login("alice", "alice-password")
+	w = h.Do(http.MethodPost, "/api/user/register", alice, models.UserRegisterRequest{Username: "bob", Password: "bob-password"})
+	if w.Code != http.StatusCreated {
+		t.Errorf("register as promoted user: status = %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+	}
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"home-monitor-backend/models"
+	"home-monitor-backend/repositories"
+	"home-monitor-backend/utils"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type APIKeyService interface {
+	APIKeyCreate(ctx context.Context, input models.APIKeyCreateRequest, userUUID uuid.UUID) (*models.APIKey, string, error)
+	APIKeyList(ctx context.Context, userUUID uuid.UUID) ([]models.APIKey, error)
+	APIKeyDelete(ctx context.Context, userUUID uuid.UUID, keyUUID uuid.UUID) error
+	APIKeyAuthenticate(ctx context.Context, key string) (*models.APIKey, error)
+}
+
+type apiKeyService struct {
+	userRepo   repositories.UserRepository
+	apiKeyRepo repositories.APIKeyRepository
+}
+
+func NewAPIKeyService(userRepo repositories.UserRepository, apiKeyRepo repositories.APIKeyRepository) APIKeyService {
+	return &apiKeyService{userRepo: userRepo, apiKeyRepo: apiKeyRepo}
+}
+
+func (s *apiKeyService) APIKeyCreate(ctx context.Context, input models.APIKeyCreateRequest, userUUID uuid.UUID) (*models.APIKey, string, error) {
+	user, err := s.userRepo.UserFindByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, "", ErrUserNotFound
+	}
+
+	key, keyHash, err := utils.GenerateAPIKey()
+	if err != nil {
+		return nil, "", errors.New("failed to generate api key")
+	}
+
+	apiKey := &models.APIKey{
+		UUID:    uuid.New(),
+		UserID:  user.ID,
+		Name:    input.Name,
+		Home:    input.Home,
+		KeyHash: keyHash,
+	}
+	if err := s.apiKeyRepo.APIKeyCreate(ctx, apiKey); err != nil {
+		return nil, "", err
+	}
+	return apiKey, key, nil
+}
+
+func (s *apiKeyService) APIKeyList(ctx context.Context, userUUID uuid.UUID) ([]models.APIKey, error) {
+	user, err := s.userRepo.UserFindByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	keys, err := s.apiKeyRepo.APIKeyFindByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (s *apiKeyService) APIKeyDelete(ctx context.Context, userUUID uuid.UUID, keyUUID uuid.UUID) error {
+	user, err := s.userRepo.UserFindByUUID(ctx, userUUID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+
+	key, err := s.apiKeyRepo.APIKeyFindByUUID(ctx, keyUUID)
+	if err != nil || key.UserID != user.ID {
+		return ErrAPIKeyNotFound
+	}
+
+	if err := s.apiKeyRepo.APIKeyDelete(ctx, key); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *apiKeyService) APIKeyAuthenticate(ctx context.Context, key string) (*models.APIKey, error) {
+	if !strings.HasPrefix(key, utils.APIKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	apiKey, err := s.apiKeyRepo.APIKeyFindByKeyHash(ctx, utils.HashAPIKey(key))
+	if err != nil || apiKey.User == nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if err := s.apiKeyRepo.APIKeyTouch(ctx, apiKey, time.Now()); err != nil {
+		return nil, err
+	}
+	return apiKey, nil
+}
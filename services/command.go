@@ -0,0 +1,207 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"home-monitor-backend/apperrors"
+	"home-monitor-backend/models"
+	"home-monitor-backend/repositories"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const commandHistoryLimit = 100
+
+var errInvalidParams = apperrors.Validation("invalid_params", "invalid params: {0}", "must be a JSON object")
+
+// CommandPublisher pushes a command to a device over a live transport such as MQTT.
+// Devices without one pick their commands up from the pull endpoint.
+type CommandPublisher interface {
+	CommandPublish(device *models.Device, command *models.Command) error
+}
+
+type CommandService interface {
+	CommandCreate(ctx context.Context, input models.CommandCreateRequest, userUUID uuid.UUID, deviceUUID uuid.UUID) (*models.Command, error)
+	CommandList(ctx context.Context, userUUID uuid.UUID, deviceUUID uuid.UUID, status models.CommandStatus) ([]models.Command, error)
+	CommandDetail(ctx context.Context, userUUID uuid.UUID, deviceUUID uuid.UUID, commandUUID uuid.UUID) (*models.Command, error)
+	CommandPull(ctx context.Context, device *models.Device) ([]models.Command, error)
+	CommandAck(ctx context.Context, input models.CommandAckRequest, device *models.Device, commandUUID uuid.UUID) (*models.Command, error)
+	CommandExpire(ctx context.Context) (int64, error)
+}
+
+type commandService struct {
+	userRepo    repositories.UserRepository
+	deviceRepo  repositories.DeviceRepository
+	commandRepo repositories.CommandRepository
+	publisher   CommandPublisher
+}
+
+// NewCommandService builds the command service. publisher may be nil when no push transport is configured.
+func NewCommandService(userRepo repositories.UserRepository, deviceRepo repositories.DeviceRepository, commandRepo repositories.CommandRepository, publisher CommandPublisher) CommandService {
+	return &commandService{userRepo: userRepo, deviceRepo: deviceRepo, commandRepo: commandRepo, publisher: publisher}
+}
+
+func (s *commandService) CommandCreate(ctx context.Context, input models.CommandCreateRequest, userUUID uuid.UUID, deviceUUID uuid.UUID) (*models.Command, error) {
+	user, device, err := findUserDevice(ctx, s.userRepo, s.deviceRepo, userUUID, deviceUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	params := bytes.TrimSpace(input.Params)
+	if len(params) > 0 && !bytes.Equal(params, []byte("null")) && params[0] != '{' {
+		return nil, errInvalidParams
+	}
+	if bytes.Equal(params, []byte("null")) {
+		params = nil
+	}
+
+	if capabilities := device.Capabilities(); capabilities != nil {
+		schema := capabilities.Command(input.Name)
+		if schema == nil {
+			return nil, apperrors.Validation("command_not_supported", "command is not supported by the device type")
+		}
+
+		values := make(map[string]any)
+		if len(params) > 0 {
+			if err := json.Unmarshal(params, &values); err != nil {
+				return nil, errInvalidParams
+			}
+		}
+
+		if err := schema.ValidateParams(values); err != nil {
+			return nil, apperrors.Validation("invalid_params", "invalid params: {0}", err.Error())
+		}
+	}
+
+	timeout := models.CommandDefaultTimeout
+	if input.TimeoutSeconds > 0 {
+		timeout = time.Duration(input.TimeoutSeconds) * time.Second
+	}
+
+	now := time.Now()
+	command := &models.Command{
+		UUID:      uuid.New(),
+		DeviceID:  device.ID,
+		UserID:    user.ID,
+		Name:      input.Name,
+		Params:    params,
+		Status:    models.CommandStatusPending,
+		ExpiresAt: now.Add(timeout),
+	}
+
+	if err := s.commandRepo.CommandCreate(ctx, command); err != nil {
+		return nil, err
+	}
+
+	if s.publisher != nil {
+		if err := s.publisher.CommandPublish(device, command); err != nil {
+			slog.WarnContext(ctx, "Command publish failed, leaving it for pull delivery", "command_uuid", command.UUID, "error", err)
+		} else {
+			commands := []models.Command{*command}
+			if err := s.commandRepo.CommandMarkSent(ctx, commands, time.Now()); err != nil {
+				return nil, err
+			}
+			*command = commands[0]
+		}
+	}
+
+	return command, nil
+}
+
+func (s *commandService) CommandList(ctx context.Context, userUUID uuid.UUID, deviceUUID uuid.UUID, status models.CommandStatus) ([]models.Command, error) {
+	_, device, err := findUserDevice(ctx, s.userRepo, s.deviceRepo, userUUID, deviceUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	commands, err := s.commandRepo.CommandFindByDeviceID(ctx, device.ID, status, commandHistoryLimit)
+	if err != nil {
+		return nil, err
+	}
+	return commands, nil
+}
+
+func (s *commandService) CommandDetail(ctx context.Context, userUUID uuid.UUID, deviceUUID uuid.UUID, commandUUID uuid.UUID) (*models.Command, error) {
+	_, device, err := findUserDevice(ctx, s.userRepo, s.deviceRepo, userUUID, deviceUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	command, err := s.commandRepo.CommandFindByUUID(ctx, commandUUID)
+	if err != nil || command.DeviceID != device.ID {
+		return nil, ErrCommandNotFound
+	}
+	return command, nil
+}
+
+func (s *commandService) CommandPull(ctx context.Context, device *models.Device) ([]models.Command, error) {
+	now := time.Now()
+	commands, err := s.commandRepo.CommandFindDeliverable(ctx, device.ID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.commandRepo.CommandMarkSent(ctx, commands, now); err != nil {
+		return nil, err
+	}
+	return commands, nil
+}
+
+func (s *commandService) CommandAck(ctx context.Context, input models.CommandAckRequest, device *models.Device, commandUUID uuid.UUID) (*models.Command, error) {
+	command, err := s.commandRepo.CommandFindByUUID(ctx, commandUUID)
+	if err != nil || command.DeviceID != device.ID {
+		return nil, ErrCommandNotFound
+	}
+
+	if command.IsFinal() {
+		return nil, errCommandCompleted(command)
+	}
+
+	now := time.Now()
+	if !command.ExpiresAt.After(now) {
+		command.Status = models.CommandStatusExpired
+		command.CompletedAt = &now
+		if _, err := s.commandRepo.CommandComplete(ctx, command); err != nil {
+			return nil, err
+		}
+		return nil, ErrCommandExpired
+	}
+
+	result := bytes.TrimSpace(input.Result)
+	if bytes.Equal(result, []byte("null")) {
+		result = nil
+	}
+
+	command.Status = input.Status
+	command.Result = result
+	command.Error = input.Error
+	command.CompletedAt = &now
+	if command.SentAt == nil {
+		command.SentAt = &now
+	}
+
+	completed, err := s.commandRepo.CommandComplete(ctx, command)
+	if err != nil {
+		return nil, err
+	}
+	if !completed {
+		// Expired by the worker or acked by another request since it was read.
+		current, err := s.commandRepo.CommandFindByUUID(ctx, commandUUID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, errCommandCompleted(current)
+	}
+	return command, nil
+}
+
+func errCommandCompleted(command *models.Command) error {
+	return apperrors.Conflict("command_completed", "command is already {0}", string(command.Status))
+}
+
+func (s *commandService) CommandExpire(ctx context.Context) (int64, error) {
+	return s.commandRepo.CommandExpire(ctx, time.Now())
+}
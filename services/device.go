@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"home-monitor-backend/models"
+	"home-monitor-backend/repositories"
+	"home-monitor-backend/utils"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// deviceTouchInterval is how stale last_seen_at may get before an authenticated request refreshes
+// it, so that frequent ingests do not each cost an extra write.
+const deviceTouchInterval = time.Minute
+
+type DeviceService interface {
+	DeviceCreate(ctx context.Context, input models.DeviceCreateRequest, userUUID uuid.UUID) (*models.Device, string, error)
+	DeviceList(ctx context.Context, userUUID uuid.UUID) ([]models.Device, error)
+	DeviceDetail(ctx context.Context, userUUID uuid.UUID, deviceUUID uuid.UUID) (*models.Device, error)
+	DeviceUpdate(ctx context.Context, input models.DeviceUpdateRequest, userUUID uuid.UUID, deviceUUID uuid.UUID) (*models.Device, error)
+	DeviceAuthenticate(ctx context.Context, token string) (*models.Device, error)
+}
+
+type deviceService struct {
+	userRepo       repositories.UserRepository
+	deviceRepo     repositories.DeviceRepository
+	deviceTypeRepo repositories.DeviceTypeRepository
+}
+
+func NewDeviceService(userRepo repositories.UserRepository, deviceRepo repositories.DeviceRepository, deviceTypeRepo repositories.DeviceTypeRepository) DeviceService {
+	return &deviceService{userRepo: userRepo, deviceRepo: deviceRepo, deviceTypeRepo: deviceTypeRepo}
+}
+
+func (s *deviceService) DeviceCreate(ctx context.Context, input models.DeviceCreateRequest, userUUID uuid.UUID) (*models.Device, string, error) {
+	user, err := s.userRepo.UserFindByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, "", ErrUserNotFound
+	}
+
+	var deviceType *models.DeviceType
+	if input.DeviceTypeUUID != nil {
+		deviceType, err = s.deviceTypeRepo.DeviceTypeFindByUUID(ctx, *input.DeviceTypeUUID)
+		if err != nil {
+			return nil, "", ErrDeviceTypeNotFound
+		}
+	}
+
+	token, tokenHash, err := utils.GenerateDeviceToken()
+	if err != nil {
+		return nil, "", errors.New("failed to generate device token")
+	}
+
+	device := &models.Device{
+		UUID:      uuid.New(),
+		UserID:    user.ID,
+		Name:      input.Name,
+		Home:      input.Home,
+		Room:      input.Room,
+		TokenHash: tokenHash,
+	}
+	if deviceType != nil {
+		device.DeviceTypeID = &deviceType.ID
+		device.DeviceType = deviceType
+	}
+
+	if err := s.deviceRepo.DeviceCreate(ctx, device); err != nil {
+		return nil, "", err
+	}
+	return device, token, nil
+}
+
+func (s *deviceService) DeviceList(ctx context.Context, userUUID uuid.UUID) ([]models.Device, error) {
+	user, err := s.userRepo.UserFindByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	var devices []models.Device
+	if user.Role == models.UserRoleAdmin {
+		devices, err = s.deviceRepo.DeviceFindAll(ctx)
+	} else {
+		devices, err = s.deviceRepo.DeviceFindByUserID(ctx, user.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+func (s *deviceService) DeviceDetail(ctx context.Context, userUUID uuid.UUID, deviceUUID uuid.UUID) (*models.Device, error) {
+	_, device, err := findUserDevice(ctx, s.userRepo, s.deviceRepo, userUUID, deviceUUID)
+	return device, err
+}
+
+func (s *deviceService) DeviceUpdate(ctx context.Context, input models.DeviceUpdateRequest, userUUID uuid.UUID, deviceUUID uuid.UUID) (*models.Device, error) {
+	_, device, err := findUserDevice(ctx, s.userRepo, s.deviceRepo, userUUID, deviceUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name == nil && input.Home == nil && input.Room == nil {
+		return nil, ErrNothingToUpdate
+	}
+
+	if input.Name != nil {
+		device.Name = *input.Name
+	}
+	if input.Home != nil {
+		device.Home = *input.Home
+	}
+	if input.Room != nil {
+		device.Room = *input.Room
+	}
+
+	if err := s.deviceRepo.DeviceUpdate(ctx, device); err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+// DeviceAuthenticate finds the device a token belongs to and records that it was seen, at most
+// once per deviceTouchInterval.
+func (s *deviceService) DeviceAuthenticate(ctx context.Context, token string) (*models.Device, error) {
+	device, err := s.deviceRepo.DeviceFindByTokenHash(ctx, utils.HashDeviceToken(token))
+	if err != nil {
+		return nil, ErrInvalidDeviceToken
+	}
+
+	now := time.Now()
+	if device.LastSeenAt != nil && now.Sub(*device.LastSeenAt) < deviceTouchInterval {
+		return device, nil
+	}
+	if err := s.deviceRepo.DeviceTouch(ctx, device, now); err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+// findUserDevice loads a device on behalf of a user. Admins can reach every device,
+// other users only the devices they own.
+func findUserDevice(ctx context.Context, userRepo repositories.UserRepository, deviceRepo repositories.DeviceRepository, userUUID uuid.UUID, deviceUUID uuid.UUID) (*models.User, *models.Device, error) {
+	user, err := userRepo.UserFindByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, nil, ErrUserNotFound
+	}
+
+	device, err := deviceRepo.DeviceFindByUUID(ctx, deviceUUID)
+	if err != nil {
+		return nil, nil, ErrDeviceNotFound
+	}
+
+	if user.Role != models.UserRoleAdmin && device.UserID != user.ID {
+		return nil, nil, ErrDeviceNotFound
+	}
+	return user, device, nil
+}
@@ -0,0 +1,65 @@
+package services_test
+
+import (
+	"context"
+	"home-monitor-backend/models"
+	"home-monitor-backend/repositories"
+	"home-monitor-backend/services"
+	"home-monitor-backend/testutil"
+	"testing"
+	"time"
+)
+
+func TestDeviceAuthenticateTouchInterval(t *testing.T) {
+	t.Parallel()
+
+	h := testutil.New(t)
+	user := h.CreateUser("alice", "secret-password", models.UserRoleUser)
+	deviceService := services.NewDeviceService(
+		repositories.NewUserRepository(h.DB),
+		repositories.NewDeviceRepository(h.DB),
+		repositories.NewDeviceTypeRepository(h.DB),
+	)
+	ctx := context.Background()
+
+	device, token, err := deviceService.DeviceCreate(ctx, models.DeviceCreateRequest{Name: "thermometer"}, user.UUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lastSeenAt := func() time.Time {
+		t.Helper()
+		var stored models.Device
+		if err := h.DB.First(&stored, device.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+		if stored.LastSeenAt == nil {
+			t.Fatal("last_seen_at is not set")
+		}
+		return *stored.LastSeenAt
+	}
+
+	if _, err := deviceService.DeviceAuthenticate(ctx, token); err != nil {
+		t.Fatal(err)
+	}
+	first := lastSeenAt()
+
+	// A second request within the interval does not write.
+	if _, err := deviceService.DeviceAuthenticate(ctx, token); err != nil {
+		t.Fatal(err)
+	}
+	if got := lastSeenAt(); !got.Equal(first) {
+		t.Errorf("last_seen_at = %s, want %s", got, first)
+	}
+
+	// Once the stored value is older than the interval, it is refreshed.
+	stale := time.Now().Add(-2 * time.Minute)
+	if err := h.DB.Model(&models.Device{}).Where("id = ?", device.ID).UpdateColumn("last_seen_at", stale).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := deviceService.DeviceAuthenticate(ctx, token); err != nil {
+		t.Fatal(err)
+	}
+	if got := lastSeenAt(); !got.After(stale.Add(time.Minute)) {
+		t.Errorf("last_seen_at = %s, want a refreshed time after %s", got, stale)
+	}
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"home-monitor-backend/apperrors"
+	"home-monitor-backend/models"
+	"home-monitor-backend/repositories"
+
+	"github.com/google/uuid"
+)
+
+type DeviceTypeService interface {
+	DeviceTypeCreate(ctx context.Context, input models.DeviceTypeCreateRequest, userUUID uuid.UUID) (*models.DeviceType, error)
+	DeviceTypeList(ctx context.Context) ([]models.DeviceType, error)
+	DeviceTypeDetail(ctx context.Context, deviceTypeUUID uuid.UUID) (*models.DeviceType, error)
+}
+
+type deviceTypeService struct {
+	userRepo       repositories.UserRepository
+	deviceTypeRepo repositories.DeviceTypeRepository
+}
+
+func NewDeviceTypeService(userRepo repositories.UserRepository, deviceTypeRepo repositories.DeviceTypeRepository) DeviceTypeService {
+	return &deviceTypeService{userRepo: userRepo, deviceTypeRepo: deviceTypeRepo}
+}
+
+func (s *deviceTypeService) DeviceTypeCreate(ctx context.Context, input models.DeviceTypeCreateRequest, userUUID uuid.UUID) (*models.DeviceType, error) {
+	user, err := s.userRepo.UserFindByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	if user.Role != models.UserRoleAdmin {
+		return nil, errAdminRequired("create device types")
+	}
+
+	if err := input.Capabilities.Validate(); err != nil {
+		return nil, apperrors.Validation("invalid_capabilities", "invalid capabilities: {0}", err.Error())
+	}
+
+	_, err = s.deviceTypeRepo.DeviceTypeFindByName(ctx, input.Name)
+	if err == nil {
+		return nil, apperrors.Conflict("device_type_exists", "device type already exists")
+	}
+
+	deviceType := &models.DeviceType{
+		UUID:         uuid.New(),
+		Name:         input.Name,
+		Description:  input.Description,
+		Capabilities: input.Capabilities,
+	}
+
+	if err := s.deviceTypeRepo.DeviceTypeCreate(ctx, deviceType); err != nil {
+		return nil, err
+	}
+	return deviceType, nil
+}
+
+func (s *deviceTypeService) DeviceTypeList(ctx context.Context) ([]models.DeviceType, error) {
+	deviceTypes, err := s.deviceTypeRepo.DeviceTypeFindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return deviceTypes, nil
+}
+
+func (s *deviceTypeService) DeviceTypeDetail(ctx context.Context, deviceTypeUUID uuid.UUID) (*models.DeviceType, error) {
+	deviceType, err := s.deviceTypeRepo.DeviceTypeFindByUUID(ctx, deviceTypeUUID)
+	if err != nil {
+		return nil, ErrDeviceTypeNotFound
+	}
+	return deviceType, nil
+}
@@ -0,0 +1,35 @@
+package services
+
+import "home-monitor-backend/apperrors"
+
+// Errors returned by more than one service. Errors specific to one call are created where they occur.
+var (
+	ErrUserNotFound       = apperrors.NotFound("user_not_found", "user not found")
+	ErrDeviceNotFound     = apperrors.NotFound("device_not_found", "device not found")
+	ErrDeviceTypeNotFound = apperrors.NotFound("device_type_not_found", "device type not found")
+	ErrCommandNotFound    = apperrors.NotFound("command_not_found", "command not found")
+	ErrAPIKeyNotFound     = apperrors.NotFound("api_key_not_found", "api key not found")
+	ErrExportJobNotFound  = apperrors.NotFound("export_job_not_found", "export job not found")
+	ErrImportJobNotFound  = apperrors.NotFound("import_job_not_found", "import job not found")
+
+	ErrUsernameTaken      = apperrors.Conflict("username_taken", "username already exists")
+	ErrSetupCompleted     = apperrors.Conflict("setup_completed", "setup already completed")
+	ErrShadowVersion      = apperrors.Conflict("shadow_version_mismatch", "shadow version mismatch")
+	ErrShadowModified     = apperrors.Conflict("shadow_modified", "shadow was modified concurrently")
+	ErrCommandExpired     = apperrors.Conflict("command_expired", "command has expired")
+	ErrExportNotCompleted = apperrors.Conflict("export_job_not_completed", "export job is not completed")
+
+	ErrInvalidCredentials = apperrors.Unauthorized("invalid_credentials", "invalid username or password")
+	ErrInvalidSetupToken  = apperrors.Unauthorized("invalid_setup_token", "invalid setup token")
+	ErrInvalidDeviceToken = apperrors.Unauthorized("invalid_device_token", "invalid device token")
+	ErrInvalidAPIKey      = apperrors.Unauthorized("invalid_api_key", "invalid api key")
+
+	ErrNothingToUpdate  = apperrors.Validation("nothing_to_update", "need to provide at least one field to update")
+	ErrInvalidTimeRange = apperrors.Validation("invalid_time_range", "from must be before to")
+	ErrRetentionScope   = apperrors.Validation("invalid_retention_scope", "a retention policy is for a device type or a home, not both")
+)
+
+// errAdminRequired is returned when a user without the admin role attempts the action.
+func errAdminRequired(action string) error {
+	return apperrors.Forbidden("admin_required", "only admin can {0}", action)
+}
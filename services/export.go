@@ -0,0 +1,386 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"home-monitor-backend/apperrors"
+	"home-monitor-backend/models"
+	"home-monitor-backend/repositories"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+const (
+	exportJobHistoryLimit = 50
+
+	// exportRowGroupSize bounds the rows a Parquet export buffers before writing them out.
+	exportRowGroupSize = 16 * 1024 * 1024
+)
+
+// ExportWriter writes an export to w and returns the number of rows written.
+type ExportWriter func(w io.Writer) (int64, error)
+
+type ExportService interface {
+	ExportReadings(ctx context.Context, userUUID uuid.UUID, request models.ReadingExportRequest) (ExportWriter, error)
+	ExportJobCreate(ctx context.Context, userUUID uuid.UUID, request models.ReadingExportRequest) (*models.ExportJob, error)
+	ExportJobList(ctx context.Context, userUUID uuid.UUID) ([]models.ExportJob, error)
+	ExportJobDetail(ctx context.Context, userUUID uuid.UUID, jobUUID uuid.UUID) (*models.ExportJob, error)
+	ExportJobFile(ctx context.Context, userUUID uuid.UUID, jobUUID uuid.UUID) (*models.ExportJob, error)
+	ExportRun(ctx context.Context) error
+	ExportRequeue(ctx context.Context) (int64, error)
+	ExportCleanup(ctx context.Context) (int, error)
+}
+
+type exportService struct {
+	userRepo    repositories.UserRepository
+	deviceRepo  repositories.DeviceRepository
+	readingRepo repositories.ReadingRepository
+	exportRepo  repositories.ExportRepository
+	dir         string
+}
+
+// NewExportService builds the export service. Export job files are written below dir.
+func NewExportService(userRepo repositories.UserRepository, deviceRepo repositories.DeviceRepository, readingRepo repositories.ReadingRepository, exportRepo repositories.ExportRepository, dir string) ExportService {
+	return &exportService{userRepo: userRepo, deviceRepo: deviceRepo, readingRepo: readingRepo, exportRepo: exportRepo, dir: dir}
+}
+
+func (s *exportService) ExportReadings(ctx context.Context, userUUID uuid.UUID, request models.ReadingExportRequest) (ExportWriter, error) {
+	user, err := s.userRepo.UserFindByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	return s.exportWriter(ctx, user, request)
+}
+
+// ExportJobCreate validates the request up front so that a job only fails later when the data
+// changes underneath it, e.g. a device is deleted while the job waits.
+func (s *exportService) ExportJobCreate(ctx context.Context, userUUID uuid.UUID, request models.ReadingExportRequest) (*models.ExportJob, error) {
+	user, err := s.userRepo.UserFindByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	if _, err := s.exportWriter(ctx, user, request); err != nil {
+		return nil, err
+	}
+
+	if request.Format == "" {
+		request.Format = models.ExportFormatCSV
+	}
+
+	job := &models.ExportJob{UserID: user.ID, Request: request}
+	if err := s.exportRepo.ExportCreate(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (s *exportService) ExportJobList(ctx context.Context, userUUID uuid.UUID) ([]models.ExportJob, error) {
+	user, err := s.userRepo.UserFindByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	jobs, err := s.exportRepo.ExportFindByUserID(ctx, user.ID, exportJobHistoryLimit)
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (s *exportService) ExportJobDetail(ctx context.Context, userUUID uuid.UUID, jobUUID uuid.UUID) (*models.ExportJob, error) {
+	user, err := s.userRepo.UserFindByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	job, err := s.exportRepo.ExportFindByUUID(ctx, jobUUID)
+	if err != nil || job.UserID != user.ID {
+		return nil, ErrExportJobNotFound
+	}
+	return job, nil
+}
+
+func (s *exportService) ExportJobFile(ctx context.Context, userUUID uuid.UUID, jobUUID uuid.UUID) (*models.ExportJob, error) {
+	job, err := s.ExportJobDetail(ctx, userUUID, jobUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.Status != models.ExportStatusCompleted || job.FilePath == "" {
+		return nil, ErrExportNotCompleted
+	}
+	return job, nil
+}
+
+// ExportRun runs pending export jobs one after another until none are left.
+func (s *exportService) ExportRun(ctx context.Context) error {
+	for ctx.Err() == nil {
+		job, err := s.exportRepo.ExportFindPending(ctx)
+		if err != nil {
+			return err
+		}
+		if job == nil {
+			return nil
+		}
+
+		startedAt := time.Now()
+		job.Status = models.ExportStatusRunning
+		job.StartedAt = &startedAt
+		if err := s.exportRepo.ExportUpdate(ctx, job); err != nil {
+			return err
+		}
+
+		rows, path, err := s.exportJobRun(ctx, job)
+		if ctx.Err() != nil {
+			// Shutting down: the job stays running and is queued again on the next start.
+			return ctx.Err()
+		}
+		completedAt := time.Now()
+		job.CompletedAt = &completedAt
+		if err != nil {
+			slog.ErrorContext(ctx, "Export job failed", "export_uuid", job.UUID, "error", err)
+			job.Status = models.ExportStatusFailed
+			job.Error = err.Error()
+		} else {
+			expiresAt := completedAt.Add(models.ExportJobTTL)
+			job.Status = models.ExportStatusCompleted
+			job.RowCount = rows
+			job.FilePath = path
+			job.ExpiresAt = &expiresAt
+		}
+
+		if err := s.exportRepo.ExportUpdate(ctx, job); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+func (s *exportService) ExportRequeue(ctx context.Context) (int64, error) {
+	return s.exportRepo.ExportRequeue(ctx)
+}
+
+// ExportCleanup deletes export jobs and their files once they can no longer be downloaded.
+func (s *exportService) ExportCleanup(ctx context.Context) (int, error) {
+	jobs, err := s.exportRepo.ExportFindExpired(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range jobs {
+		if jobs[i].FilePath != "" {
+			if err := os.Remove(jobs[i].FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return i, err
+			}
+		}
+		if err := s.exportRepo.ExportDelete(ctx, &jobs[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(jobs), nil
+}
+
+// exportJobRun writes the export to a temporary file and only moves it into place once it is
+// complete, so a half written file is never offered for download.
+func (s *exportService) exportJobRun(ctx context.Context, job *models.ExportJob) (int64, string, error) {
+	if job.User == nil {
+		return 0, "", ErrUserNotFound
+	}
+
+	write, err := s.exportWriter(ctx, job.User, job.Request)
+	if err != nil {
+		return 0, "", err
+	}
+
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return 0, "", err
+	}
+
+	path := filepath.Join(s.dir, job.UUID.String()+"."+string(job.Request.Format))
+	file, err := os.Create(path + ".tmp")
+	if err != nil {
+		return 0, "", err
+	}
+	defer os.Remove(path + ".tmp")
+
+	rows, err := write(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, "", err
+	}
+
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return 0, "", err
+	}
+	return rows, path, nil
+}
+
+type exportDevice struct {
+	device    *models.Device
+	converter *readingConverter
+}
+
+// exportWriter checks the request and the user's access to every device before returning the
+// writer, so that errors can still be reported as a status code rather than a truncated file.
+func (s *exportService) exportWriter(ctx context.Context, user *models.User, request models.ReadingExportRequest) (ExportWriter, error) {
+	if !request.From.Before(request.To) {
+		return nil, ErrInvalidTimeRange
+	}
+
+	format := request.Format
+	if format == "" {
+		format = models.ExportFormatCSV
+	}
+
+	devices := make(map[uint]exportDevice, len(request.DeviceUUIDs))
+	deviceIDs := make([]uint, 0, len(request.DeviceUUIDs))
+	for _, value := range request.DeviceUUIDs {
+		deviceUUID, err := uuid.Parse(value)
+		if err != nil {
+			return nil, apperrors.Validation("invalid_uuid", "invalid UUID {0}", value)
+		}
+
+		device, err := s.deviceRepo.DeviceFindByUUID(ctx, deviceUUID)
+		if err != nil || (user.Role != models.UserRoleAdmin && device.UserID != user.ID) {
+			return nil, ErrDeviceNotFound
+		}
+
+		if _, exists := devices[device.ID]; exists {
+			continue
+		}
+		devices[device.ID] = exportDevice{device: device, converter: newReadingConverter(device, user, request.Units)}
+		deviceIDs = append(deviceIDs, device.ID)
+	}
+
+	write := func(w io.Writer) (int64, error) {
+		encoder, err := newExportEncoder(format, w)
+		if err != nil {
+			return 0, err
+		}
+
+		var rows int64
+		err = s.readingRepo.ReadingEach(ctx, deviceIDs, request.Metrics, request.From, request.To, func(reading *models.Reading) error {
+			d := devices[reading.DeviceID]
+			value, unit := d.converter.value(reading.Metric, reading.Value)
+			rows++
+			return encoder.Write(exportRow{
+				DeviceUUID: d.device.UUID.String(),
+				DeviceName: d.device.Name,
+				Metric:     reading.Metric,
+				Value:      value,
+				Unit:       unit,
+				Flagged:    reading.Flagged,
+				RecordedAt: d.converter.localTime(reading.RecordedAt),
+			})
+		})
+		if err != nil {
+			return rows, err
+		}
+		return rows, encoder.Close()
+	}
+	return write, nil
+}
+
+type exportRow struct {
+	DeviceUUID string
+	DeviceName string
+	Metric     string
+	Value      float64
+	Unit       string
+	Flagged    bool
+	RecordedAt time.Time
+}
+
+type exportEncoder interface {
+	Write(row exportRow) error
+	Close() error
+}
+
+func newExportEncoder(format models.ExportFormat, w io.Writer) (exportEncoder, error) {
+	if format == models.ExportFormatParquet {
+		return newParquetEncoder(w)
+	}
+	return newCSVEncoder(w)
+}
+
+var exportCSVHeader = []string{"device_uuid", "device_name", "metric", "value", "unit", "flagged", "recorded_at"}
+
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func newCSVEncoder(w io.Writer) (*csvEncoder, error) {
+	encoder := &csvEncoder{w: csv.NewWriter(w)}
+	if err := encoder.w.Write(exportCSVHeader); err != nil {
+		return nil, err
+	}
+	return encoder, nil
+}
+
+func (e *csvEncoder) Write(row exportRow) error {
+	return e.w.Write([]string{
+		row.DeviceUUID,
+		row.DeviceName,
+		row.Metric,
+		strconv.FormatFloat(row.Value, 'f', -1, 64),
+		row.Unit,
+		strconv.FormatBool(row.Flagged),
+		row.RecordedAt.Format(time.RFC3339Nano),
+	})
+}
+
+func (e *csvEncoder) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// parquetRow is the Parquet schema of an export. Timestamps are stored as UTC milliseconds.
+type parquetRow struct {
+	DeviceUUID string  `parquet:"name=device_uuid, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	DeviceName string  `parquet:"name=device_name, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Metric     string  `parquet:"name=metric, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Value      float64 `parquet:"name=value, type=DOUBLE"`
+	Unit       string  `parquet:"name=unit, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Flagged    bool    `parquet:"name=flagged, type=BOOLEAN"`
+	RecordedAt int64   `parquet:"name=recorded_at, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+}
+
+type parquetEncoder struct {
+	w *writer.ParquetWriter
+}
+
+func newParquetEncoder(w io.Writer) (*parquetEncoder, error) {
+	pw, err := writer.NewParquetWriterFromWriter(w, new(parquetRow), 1)
+	if err != nil {
+		return nil, err
+	}
+	pw.RowGroupSize = exportRowGroupSize
+	return &parquetEncoder{w: pw}, nil
+}
+
+func (e *parquetEncoder) Write(row exportRow) error {
+	return e.w.Write(parquetRow{
+		DeviceUUID: row.DeviceUUID,
+		DeviceName: row.DeviceName,
+		Metric:     row.Metric,
+		Value:      row.Value,
+		Unit:       row.Unit,
+		Flagged:    row.Flagged,
+		RecordedAt: row.RecordedAt.UnixMilli(),
+	})
+}
+
+func (e *parquetEncoder) Close() error {
+	return e.w.WriteStop()
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"home-monitor-backend/models"
+	"sync"
+	"time"
+)
+
+const healthCheckTimeout = 2 * time.Second
+
+// HealthCheck reports the state of one dependency, with optional details. A failing critical
+// check makes the instance unavailable; any other failing check only degrades it.
+type HealthCheck struct {
+	Name     string
+	Critical bool
+	Check    func(ctx context.Context) (any, error)
+}
+
+type HealthService interface {
+	HealthLive(ctx context.Context) *models.HealthResponse
+	HealthReady(ctx context.Context) *models.ReadinessResponse
+}
+
+type healthService struct {
+	checks []HealthCheck
+}
+
+func NewHealthService(checks ...HealthCheck) HealthService {
+	return &healthService{checks: checks}
+}
+
+func (s *healthService) HealthLive(ctx context.Context) *models.HealthResponse {
+	return &models.HealthResponse{Status: models.HealthStatusOK}
+}
+
+// HealthReady runs every check concurrently, each with its own timeout.
+func (s *healthService) HealthReady(ctx context.Context) *models.ReadinessResponse {
+	results := make([]models.HealthCheckResult, len(s.checks))
+
+	var wg sync.WaitGroup
+	for i, check := range s.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			defer cancel()
+
+			start := time.Now()
+			details, err := check.Check(checkCtx)
+			result := models.HealthCheckResult{
+				Status:     models.HealthStatusOK,
+				Critical:   check.Critical,
+				DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+				Details:    details,
+			}
+			if err != nil {
+				result.Status = models.HealthStatusFail
+				result.Error = err.Error()
+			}
+			results[i] = result
+		}()
+	}
+	wg.Wait()
+
+	response := &models.ReadinessResponse{Status: models.HealthStatusOK, Checks: make(map[string]models.HealthCheckResult, len(s.checks))}
+	for i, check := range s.checks {
+		result := results[i]
+		response.Checks[check.Name] = result
+		if result.Status != models.HealthStatusFail {
+			continue
+		}
+		if check.Critical {
+			response.Status = models.HealthStatusUnavailable
+		} else if response.Status == models.HealthStatusOK {
+			response.Status = models.HealthStatusDegraded
+		}
+	}
+	return response
+}
@@ -0,0 +1,617 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"home-monitor-backend/apperrors"
+	"home-monitor-backend/metrics"
+	"home-monitor-backend/models"
+	"home-monitor-backend/repositories"
+	"home-monitor-backend/utils"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	importJobHistoryLimit = 50
+
+	// importBatchRows is the number of CSV rows stored per checkpoint.
+	importBatchRows = 500
+
+	// importLeaseTimeout is how long a running job may go without a checkpoint before another
+	// process may take it over.
+	importLeaseTimeout = 5 * time.Minute
+)
+
+type ImportService interface {
+	ImportCreate(ctx context.Context, userUUID uuid.UUID, deviceUUID uuid.UUID, mapping models.ImportMapping, file io.Reader) (*models.ImportJob, error)
+	ImportList(ctx context.Context, userUUID uuid.UUID, deviceUUID uuid.UUID) ([]models.ImportJob, error)
+	ImportDetail(ctx context.Context, userUUID uuid.UUID, deviceUUID uuid.UUID, importUUID uuid.UUID) (*models.ImportJob, error)
+	ImportFile(ctx context.Context, deviceUUID uuid.UUID, mapping models.ImportMapping, path string) (*models.ImportJob, error)
+	ImportResume(ctx context.Context, importUUID uuid.UUID) (*models.ImportJob, error)
+	ImportRun(ctx context.Context) error
+	ImportRequeue(ctx context.Context) (int64, error)
+}
+
+type importService struct {
+	userRepo      repositories.UserRepository
+	deviceRepo    repositories.DeviceRepository
+	readingRepo   repositories.ReadingRepository
+	importRepo    repositories.ImportRepository
+	rollupService RollupService
+	dir           string
+	owner         string
+}
+
+// NewImportService builds the import service. Uploaded files are stored below dir until their job completes.
+func NewImportService(userRepo repositories.UserRepository, deviceRepo repositories.DeviceRepository, readingRepo repositories.ReadingRepository, importRepo repositories.ImportRepository, rollupService RollupService, dir string) ImportService {
+	return &importService{userRepo: userRepo, deviceRepo: deviceRepo, readingRepo: readingRepo, importRepo: importRepo, rollupService: rollupService, dir: dir, owner: importOwner()}
+}
+
+// importOwner names this process in the lease of the jobs it runs.
+func importOwner() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s:%d:%s", hostname, os.Getpid(), uuid.NewString()[:8])
+}
+
+// ImportCreate stores the uploaded file and queues it for the import worker. The mapping is
+// checked against the header row and the device type before the job is accepted.
+func (s *importService) ImportCreate(ctx context.Context, userUUID uuid.UUID, deviceUUID uuid.UUID, mapping models.ImportMapping, file io.Reader) (*models.ImportJob, error) {
+	user, device, err := findUserDevice(ctx, s.userRepo, s.deviceRepo, userUUID, deviceUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	job := &models.ImportJob{UUID: uuid.New(), UserID: user.ID, DeviceID: device.ID, Device: device, Mapping: mapping}
+	job.FilePath = filepath.Join(s.dir, job.UUID.String()+".csv")
+
+	out, err := os.Create(job.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.Copy(out, file)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(job.FilePath)
+		return nil, err
+	}
+
+	if err := importCheck(job); err != nil {
+		os.Remove(job.FilePath)
+		return nil, apperrors.Validation("invalid_mapping", "invalid mapping: {0}", err.Error())
+	}
+
+	if err := s.importRepo.ImportCreate(ctx, job); err != nil {
+		os.Remove(job.FilePath)
+		return nil, err
+	}
+	return job, nil
+}
+
+func (s *importService) ImportList(ctx context.Context, userUUID uuid.UUID, deviceUUID uuid.UUID) ([]models.ImportJob, error) {
+	_, device, err := findUserDevice(ctx, s.userRepo, s.deviceRepo, userUUID, deviceUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs, err := s.importRepo.ImportFindByDeviceID(ctx, device.ID, importJobHistoryLimit)
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (s *importService) ImportDetail(ctx context.Context, userUUID uuid.UUID, deviceUUID uuid.UUID, importUUID uuid.UUID) (*models.ImportJob, error) {
+	_, device, err := findUserDevice(ctx, s.userRepo, s.deviceRepo, userUUID, deviceUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := s.importRepo.ImportFindByUUID(ctx, importUUID)
+	if err != nil || job.DeviceID != device.ID {
+		return nil, ErrImportJobNotFound
+	}
+	return job, nil
+}
+
+// ImportFile imports a local file for the command line. The job is owned by the device owner
+// and runs in the calling process; the file is read in place and never removed.
+func (s *importService) ImportFile(ctx context.Context, deviceUUID uuid.UUID, mapping models.ImportMapping, path string) (*models.ImportJob, error) {
+	device, err := s.deviceRepo.DeviceFindByUUID(ctx, deviceUUID)
+	if err != nil {
+		return nil, ErrDeviceNotFound
+	}
+
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &models.ImportJob{UserID: device.UserID, DeviceID: device.ID, Device: device, Mapping: mapping, FilePath: path}
+	if err := importCheck(job); err != nil {
+		return nil, err
+	}
+
+	if err := s.importRepo.ImportCreate(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, s.importClaimAndRun(ctx, job)
+}
+
+// ImportResume continues an interrupted or failed job from its last checkpoint. A job that another
+// process is still running is left to it.
+func (s *importService) ImportResume(ctx context.Context, importUUID uuid.UUID) (*models.ImportJob, error) {
+	job, err := s.importRepo.ImportFindByUUID(ctx, importUUID)
+	if err != nil {
+		return nil, ErrImportJobNotFound
+	}
+
+	reset, err := s.importRepo.ImportReset(ctx, job, time.Now().Add(-importLeaseTimeout))
+	if err != nil {
+		return nil, err
+	}
+	if !reset {
+		if job.Status == models.ImportStatusCompleted {
+			return job, apperrors.Conflict("import_job_completed", "import job is already completed")
+		}
+		return job, apperrors.Conflict("import_job_running", "import job is running in another process")
+	}
+	return job, s.importClaimAndRun(ctx, job)
+}
+
+// ImportRun runs pending import jobs one after another until none are left.
+func (s *importService) ImportRun(ctx context.Context) error {
+	for ctx.Err() == nil {
+		job, err := s.importRepo.ImportFindPending(ctx)
+		if err != nil {
+			return err
+		}
+		if job == nil {
+			return nil
+		}
+
+		if err := s.importClaimAndRun(ctx, job); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Import job failed", "import_uuid", job.UUID, "error", err)
+		}
+	}
+	return ctx.Err()
+}
+
+// ImportRequeue queues running jobs again whose process stopped checkpointing them.
+func (s *importService) ImportRequeue(ctx context.Context) (int64, error) {
+	return s.importRepo.ImportRequeue(ctx, time.Now().Add(-importLeaseTimeout))
+}
+
+// importClaimAndRun processes a pending job unless another process got to it first. The returned
+// error is the reason the job failed; it is also stored on the job.
+func (s *importService) importClaimAndRun(ctx context.Context, job *models.ImportJob) error {
+	if job.StartedAt == nil {
+		startedAt := time.Now()
+		job.StartedAt = &startedAt
+	}
+
+	claimed, err := s.importRepo.ImportClaim(ctx, job, s.owner)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return nil
+	}
+
+	runErr := s.importRows(ctx, job)
+	if errors.Is(runErr, repositories.ErrImportLeaseLost) {
+		// Another process took the job over and continues from the last checkpoint.
+		return runErr
+	}
+	if runErr != nil && ctx.Err() != nil {
+		// Cancelled: the job goes back to pending with its last checkpoint and is resumed later.
+		if err := s.importRepo.ImportRelease(context.WithoutCancel(ctx), job); err != nil {
+			slog.ErrorContext(ctx, "Releasing import job failed", "import_uuid", job.UUID, "error", err)
+		}
+		return runErr
+	}
+
+	completedAt := time.Now()
+	job.CompletedAt = &completedAt
+	if runErr != nil {
+		job.Status = models.ImportStatusFailed
+		job.Error = runErr.Error()
+	} else {
+		job.Status = models.ImportStatusCompleted
+		job.Error = ""
+	}
+
+	if err := s.importRepo.ImportCheckpoint(ctx, job, nil); err != nil {
+		return err
+	}
+	if runErr != nil {
+		return runErr
+	}
+
+	if filepath.Dir(job.FilePath) == filepath.Clean(s.dir) {
+		if err := os.Remove(job.FilePath); err != nil {
+			slog.WarnContext(ctx, "Removing import file failed", "path", job.FilePath, "error", err)
+		}
+	}
+	return nil
+}
+
+// importRows reads the file from the job's checkpoint and stores it batch by batch. Every batch
+// is committed together with the new byte offset, so an interrupted job resumes where it stopped.
+func (s *importService) importRows(ctx context.Context, job *models.ImportJob) error {
+	file, err := os.Open(job.FilePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := newImportReader(file, job.Mapping)
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("reading header: %w", err)
+	}
+
+	plan, err := newImportPlan(job.Mapping, job.Device, header)
+	if err != nil {
+		return err
+	}
+
+	if job.ByteOffset == 0 {
+		job.ByteOffset = reader.InputOffset()
+	}
+	if _, err := file.Seek(job.ByteOffset, io.SeekStart); err != nil {
+		return err
+	}
+	reader = newImportReader(file, job.Mapping)
+	start := job.ByteOffset
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Progress is only applied to the job once its batch is stored, so a failed batch is
+		// read again on resume.
+		progress := *job
+		var candidates []models.Reading
+		eof := false
+		for rows := 0; rows < importBatchRows; rows++ {
+			record, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				eof = true
+				break
+			}
+
+			progress.RowsProcessed++
+			if err != nil {
+				importRowError(&progress, models.ImportRowError{Row: progress.RowsProcessed, Error: err.Error()})
+				continue
+			}
+
+			readings, rowErrors := plan.row(progress.RowsProcessed, record)
+			for _, rowError := range rowErrors {
+				importRowError(&progress, rowError)
+			}
+			candidates = append(candidates, readings...)
+		}
+
+		readings, err := s.importDeduplicate(ctx, &progress, candidates)
+		if err != nil {
+			return err
+		}
+
+		heartbeatAt := time.Now()
+		progress.HeartbeatAt = &heartbeatAt
+		progress.ByteOffset = start + reader.InputOffset()
+		progress.ReadingsAdded += int64(len(readings))
+		for _, reading := range readings {
+			if progress.FirstAt == nil || reading.RecordedAt.Before(*progress.FirstAt) {
+				progress.FirstAt = &reading.RecordedAt
+			}
+			if progress.LastAt == nil || reading.RecordedAt.After(*progress.LastAt) {
+				progress.LastAt = &reading.RecordedAt
+			}
+		}
+
+		if err := s.importRepo.ImportCheckpoint(ctx, &progress, readings); err != nil {
+			return err
+		}
+		// Rollups behind the watermark do not see the imported readings unless they are merged in.
+		if err := s.rollupService.RollupMerge(ctx, readings); err != nil {
+			slog.ErrorContext(ctx, "Rollup after import failed", "import_uuid", job.UUID, "error", err)
+		}
+		metrics.ReadingsIngested.WithLabelValues("import", "accepted").Add(float64(len(readings)))
+		metrics.ReadingsIngested.WithLabelValues("import", "duplicate").Add(float64(progress.DuplicateCount - job.DuplicateCount))
+		metrics.ReadingsIngested.WithLabelValues("import", "rejected").Add(float64(progress.ErrorCount - job.ErrorCount))
+		*job = progress
+		if eof {
+			return nil
+		}
+	}
+}
+
+// importDeduplicate drops readings that already exist for the same metric and time, whether
+// stored earlier or repeated within the batch.
+func (s *importService) importDeduplicate(ctx context.Context, job *models.ImportJob, candidates []models.Reading) ([]models.Reading, error) {
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	from, to := candidates[0].RecordedAt, candidates[0].RecordedAt
+	metrics := make(map[string]bool)
+	for _, candidate := range candidates {
+		from = minTime(from, candidate.RecordedAt)
+		to = maxTime(to, candidate.RecordedAt)
+		metrics[candidate.Metric] = true
+	}
+
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+
+	existing, err := s.readingRepo.ReadingFindTimes(ctx, job.DeviceID, names, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(existing)+len(candidates))
+	for _, reading := range existing {
+		seen[importKey(reading.Metric, reading.RecordedAt)] = true
+	}
+
+	now := time.Now()
+	readings := make([]models.Reading, 0, len(candidates))
+	for _, candidate := range candidates {
+		key := importKey(candidate.Metric, candidate.RecordedAt)
+		if seen[key] {
+			job.DuplicateCount++
+			continue
+		}
+		seen[key] = true
+
+		candidate.DeviceID = job.DeviceID
+		candidate.CreatedAt = now
+		readings = append(readings, candidate)
+	}
+	return readings, nil
+}
+
+func importKey(metric string, t time.Time) string {
+	return metric + "|" + strconv.FormatInt(t.UnixMilli(), 10)
+}
+
+func importRowError(job *models.ImportJob, rowError models.ImportRowError) {
+	job.ErrorCount++
+	if len(job.RowErrors) < models.ImportMaxRowErrors {
+		job.RowErrors = append(job.RowErrors, rowError)
+	}
+}
+
+// importCheck reads the header of a job's file and checks the mapping against it.
+func importCheck(job *models.ImportJob) error {
+	file, err := os.Open(job.FilePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	header, err := newImportReader(file, job.Mapping).Read()
+	if err != nil {
+		return fmt.Errorf("reading header: %w", err)
+	}
+
+	_, err = newImportPlan(job.Mapping, job.Device, header)
+	return err
+}
+
+func newImportReader(r io.Reader, mapping models.ImportMapping) *csv.Reader {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+	if mapping.Delimiter != "" {
+		reader.Comma = []rune(mapping.Delimiter)[0]
+	}
+	return reader
+}
+
+// importPlan resolves a mapping against the header row of a file and the device type schema.
+type importPlan struct {
+	timestampIndex int
+	parseTime      func(value string) (time.Time, error)
+	columns        []importColumn
+	capabilities   *models.DeviceCapabilities
+}
+
+type importColumn struct {
+	index    int
+	column   string
+	metric   string
+	schema   *models.MetricSchema
+	fromUnit string
+}
+
+func newImportPlan(mapping models.ImportMapping, device *models.Device, header []string) (*importPlan, error) {
+	indexes := make(map[string]int, len(header))
+	for i, name := range header {
+		indexes[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+
+	timestampIndex, ok := indexes[mapping.TimestampColumn]
+	if !ok {
+		return nil, fmt.Errorf("timestamp column %q not found in header", mapping.TimestampColumn)
+	}
+
+	parseTime, err := importTimeParser(mapping.TimestampFormat, mapping.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &importPlan{timestampIndex: timestampIndex, parseTime: parseTime, capabilities: device.Capabilities()}
+	for _, m := range mapping.Metrics {
+		index, ok := indexes[m.Column]
+		if !ok {
+			return nil, fmt.Errorf("column %q not found in header", m.Column)
+		}
+
+		column := importColumn{index: index, column: m.Column, metric: m.Metric}
+		if plan.capabilities != nil {
+			column.schema = plan.capabilities.Metric(m.Metric)
+			if column.schema == nil {
+				return nil, fmt.Errorf("metric %q is not declared by the device type", m.Metric)
+			}
+		}
+
+		if m.Unit != "" && (column.schema == nil || column.schema.Unit == "") {
+			return nil, fmt.Errorf("metric %q has no declared unit to convert %s to", m.Metric, m.Unit)
+		}
+		if m.Unit != "" && utils.NormalizeUnit(m.Unit) != utils.NormalizeUnit(column.schema.Unit) {
+			if _, err := utils.ConvertUnit(0, m.Unit, column.schema.Unit); err != nil {
+				return nil, fmt.Errorf("metric %q: %w", m.Metric, err)
+			}
+			column.fromUnit = m.Unit
+		}
+		plan.columns = append(plan.columns, column)
+	}
+	return plan, nil
+}
+
+func importTimeParser(format string, timezone string) (func(value string) (time.Time, error), error) {
+	location := time.UTC
+	if timezone != "" {
+		loaded, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, errors.New("unknown timezone " + timezone)
+		}
+		location = loaded
+	}
+
+	switch format {
+	case "", models.ImportTimestampRFC3339:
+		return func(value string) (time.Time, error) {
+			return time.Parse(time.RFC3339Nano, value)
+		}, nil
+	case models.ImportTimestampUnix:
+		return func(value string) (time.Time, error) {
+			seconds, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return time.Time{}, errors.New("invalid unix timestamp")
+			}
+			return time.UnixMilli(int64(seconds * 1000)), nil
+		}, nil
+	case models.ImportTimestampUnixMs:
+		return func(value string) (time.Time, error) {
+			millis, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return time.Time{}, errors.New("invalid unix millisecond timestamp")
+			}
+			return time.UnixMilli(millis), nil
+		}, nil
+	default:
+		return func(value string) (time.Time, error) {
+			return time.ParseInLocation(format, value, location)
+		}, nil
+	}
+}
+
+// row turns one CSV record into readings. Empty cells are skipped; every other problem is
+// reported against the row and column it occurred in.
+func (p *importPlan) row(row int64, record []string) ([]models.Reading, []models.ImportRowError) {
+	if p.timestampIndex >= len(record) {
+		return nil, []models.ImportRowError{{Row: row, Error: "missing timestamp"}}
+	}
+
+	recordedAt, err := p.parseTime(strings.TrimSpace(record[p.timestampIndex]))
+	if err != nil {
+		return nil, []models.ImportRowError{{Row: row, Error: "invalid timestamp: " + err.Error()}}
+	}
+	// Readings are stored with millisecond precision; deduplication compares at the same precision.
+	recordedAt = recordedAt.UTC().Truncate(time.Millisecond)
+
+	var readings []models.Reading
+	var rowErrors []models.ImportRowError
+	for _, column := range p.columns {
+		if column.index >= len(record) {
+			continue
+		}
+
+		cell := strings.TrimSpace(record[column.index])
+		if cell == "" {
+			continue
+		}
+
+		value, err := column.value(cell)
+		if err == nil {
+			var flagged bool
+			var number float64
+			number, flagged, err = readingValue(p.capabilities, models.ReadingInput{Metric: column.metric, Value: value})
+			if err == nil {
+				readings = append(readings, models.Reading{
+					Metric:     column.metric,
+					Value:      number,
+					Flagged:    flagged,
+					RecordedAt: recordedAt,
+				})
+				continue
+			}
+		}
+		rowErrors = append(rowErrors, models.ImportRowError{Row: row, Column: column.column, Error: err.Error()})
+	}
+	return readings, rowErrors
+}
+
+// value parses a cell into the JSON-like value readingValue expects, converting units on the way.
+func (c *importColumn) value(cell string) (any, error) {
+	if c.schema != nil && c.schema.DataType == models.DataTypeBoolean {
+		b, err := strconv.ParseBool(cell)
+		if err != nil {
+			return nil, errors.New("value must be a boolean")
+		}
+		return b, nil
+	}
+
+	number, err := strconv.ParseFloat(cell, 64)
+	if err != nil {
+		if b, boolErr := strconv.ParseBool(cell); boolErr == nil && c.schema == nil {
+			return b, nil
+		}
+		return nil, errors.New("value must be a number")
+	}
+
+	if c.fromUnit != "" {
+		return utils.ConvertUnit(number, c.fromUnit, c.schema.Unit)
+	}
+	return number, nil
+}
+
+func minTime(a time.Time, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+func maxTime(a time.Time, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,444 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"home-monitor-backend/apperrors"
+	"home-monitor-backend/metrics"
+	"home-monitor-backend/models"
+	"home-monitor-backend/repositories"
+	"home-monitor-backend/utils"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ReadingService interface {
+	ReadingIngest(ctx context.Context, input models.ReadingIngestRequest, device *models.Device) (*models.ReadingIngestResponse, error)
+	ReadingList(ctx context.Context, userUUID uuid.UUID, deviceUUID uuid.UUID, query models.ReadingQuery) ([]models.ReadingResponse, error)
+	ReadingAggregate(ctx context.Context, userUUID uuid.UUID, deviceUUID uuid.UUID, query models.ReadingAggregateQuery) (*models.ReadingAggregateResponse, error)
+	ReadingLatest(ctx context.Context, apiKey *models.APIKey) ([]models.ReadingLatest, error)
+}
+
+type readingService struct {
+	userRepo      repositories.UserRepository
+	deviceRepo    repositories.DeviceRepository
+	readingRepo   repositories.ReadingRepository
+	rollupRepo    repositories.RollupRepository
+	rollupService RollupService
+}
+
+func NewReadingService(userRepo repositories.UserRepository, deviceRepo repositories.DeviceRepository, readingRepo repositories.ReadingRepository, rollupRepo repositories.RollupRepository, rollupService RollupService) ReadingService {
+	return &readingService{userRepo: userRepo, deviceRepo: deviceRepo, readingRepo: readingRepo, rollupRepo: rollupRepo, rollupService: rollupService}
+}
+
+// ReadingIngest stores the valid readings of a batch and reports the rejected ones by index.
+// Readings of typed devices are checked against the metric schema of their device type.
+// Readings recorded behind the rollup watermark are rolled up right away, as they would
+// otherwise never reach the rollups and be lost to retention.
+func (s *readingService) ReadingIngest(ctx context.Context, input models.ReadingIngestRequest, device *models.Device) (*models.ReadingIngestResponse, error) {
+	now := time.Now()
+	capabilities := device.Capabilities()
+	response := &models.ReadingIngestResponse{Rejected: []models.ReadingIngestError{}}
+	readings := make([]models.Reading, 0, len(input.Readings))
+
+	for i, item := range input.Readings {
+		value, flagged, err := readingValue(capabilities, item)
+		if err != nil {
+			response.Rejected = append(response.Rejected, models.ReadingIngestError{Index: i, Metric: item.Metric, Error: err.Error()})
+			continue
+		}
+
+		recordedAt := now
+		if item.RecordedAt != nil {
+			recordedAt = *item.RecordedAt
+		}
+
+		readings = append(readings, models.Reading{
+			DeviceID:   device.ID,
+			Metric:     item.Metric,
+			Value:      value,
+			Flagged:    flagged,
+			RecordedAt: recordedAt,
+			CreatedAt:  now,
+		})
+		if flagged {
+			response.Flagged++
+		}
+	}
+
+	if err := s.readingRepo.ReadingCreateBatch(ctx, readings); err != nil {
+		return nil, err
+	}
+	s.rollupBackfill(ctx, readings)
+	response.Accepted = len(readings)
+	metrics.ReadingsIngested.WithLabelValues("device", "accepted").Add(float64(response.Accepted))
+	metrics.ReadingsIngested.WithLabelValues("device", "rejected").Add(float64(len(response.Rejected)))
+	return response, nil
+}
+
+// rollupBackfill merges readings that arrived behind the minute watermark into the rollups.
+func (s *readingService) rollupBackfill(ctx context.Context, readings []models.Reading) {
+	if len(readings) == 0 {
+		return
+	}
+
+	state, err := s.rollupRepo.RollupStateFind(ctx, models.RollupTierMinute)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Loading the rollup watermark failed", "error", err)
+		return
+	}
+
+	first := readings[0].RecordedAt
+	for _, reading := range readings[1:] {
+		first = minTime(first, reading.RecordedAt)
+	}
+	if !first.Before(state.RolledUpTo) {
+		return
+	}
+
+	if err := s.rollupService.RollupMerge(ctx, readings); err != nil {
+		slog.ErrorContext(ctx, "Rollup after backfilled readings failed", "error", err)
+	}
+}
+
+func (s *readingService) ReadingList(ctx context.Context, userUUID uuid.UUID, deviceUUID uuid.UUID, query models.ReadingQuery) ([]models.ReadingResponse, error) {
+	user, device, err := findUserDevice(ctx, s.userRepo, s.deviceRepo, userUUID, deviceUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		return nil, ErrInvalidTimeRange
+	}
+
+	if query.Limit == 0 {
+		query.Limit = models.ReadingDefaultLimit
+	}
+
+	readings, err := s.readingRepo.ReadingFind(ctx, device.ID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	converter := newReadingConverter(device, user, query.Units)
+	response := make([]models.ReadingResponse, 0, len(readings))
+	for i := range readings {
+		response = append(response, converter.convert(&readings[i]))
+	}
+	return response, nil
+}
+
+// ReadingAggregate buckets the readings of one metric. It reads from the coarsest rollup tier
+// whose bucket size divides the requested one, falling back to raw readings for small buckets.
+// The part of the range the tier has not been rolled up to yet is read from finer tiers. With
+// preferred units, buckets follow the wall clock of the user's time zone, so a tier is only used
+// when its UTC buckets line up with the local ones.
+func (s *readingService) ReadingAggregate(ctx context.Context, userUUID uuid.UUID, deviceUUID uuid.UUID, query models.ReadingAggregateQuery) (*models.ReadingAggregateResponse, error) {
+	user, device, err := findUserDevice(ctx, s.userRepo, s.deviceRepo, userUUID, deviceUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !query.From.Before(query.To) {
+		return nil, ErrInvalidTimeRange
+	}
+
+	bucket, err := utils.ParseDuration(query.Bucket)
+	if err != nil || bucket < time.Second {
+		return nil, apperrors.Validation("invalid_bucket", "bucket must be a duration of at least 1s")
+	}
+
+	if query.To.Sub(query.From)/bucket > models.ReadingAggregateMaxBuckets {
+		return nil, apperrors.Validation("too_many_buckets", "too many buckets, use a larger bucket or a shorter range")
+	}
+
+	converter := newReadingConverter(device, user, query.Units)
+	location := time.UTC
+	if converter.location != nil {
+		location = converter.location
+	}
+
+	tier := models.RollupTierRaw
+	for _, t := range models.RollupTiers {
+		if bucket >= t.Duration() && bucket%t.Duration() == 0 && zoneAligned(location, query.From, query.To, t.Duration()) {
+			tier = t
+		}
+	}
+
+	source, err := s.aggregateSource(ctx, device.ID, query.Metric, tier, query.From, query.To)
+	if err != nil {
+		return nil, err
+	}
+
+	_, unit := converter.value(query.Metric, 0)
+	response := &models.ReadingAggregateResponse{
+		DeviceUUID: device.UUID,
+		Metric:     query.Metric,
+		Unit:       unit,
+		Bucket:     query.Bucket,
+		Tier:       tier,
+		Buckets:    []models.ReadingBucket{},
+	}
+
+	var current *models.ReadingBucket
+	var currentLastAt time.Time
+	var sum float64
+	flush := func() {
+		if current == nil {
+			return
+		}
+		current.Avg = sum / float64(current.Count)
+		current.Min, _ = converter.value(query.Metric, current.Min)
+		current.Max, _ = converter.value(query.Metric, current.Max)
+		current.Avg, _ = converter.value(query.Metric, current.Avg)
+		current.Last, _ = converter.value(query.Metric, current.Last)
+		response.Buckets = append(response.Buckets, *current)
+	}
+
+	// Source rows are ordered by time, so buckets are completed one after another.
+	for _, row := range source {
+		start := truncateIn(row.BucketStart, bucket, location)
+		if current == nil || !current.Start.Equal(start) {
+			flush()
+			current = &models.ReadingBucket{Start: start, Min: row.Min, Max: row.Max, Last: row.Last}
+			currentLastAt = row.LastAt
+			sum = 0
+		}
+
+		current.Min = min(current.Min, row.Min)
+		current.Max = max(current.Max, row.Max)
+		current.Count += row.Count
+		sum += row.Sum
+		if !row.LastAt.Before(currentLastAt) {
+			current.Last = row.Last
+			currentLastAt = row.LastAt
+		}
+	}
+	flush()
+
+	return response, nil
+}
+
+// truncateIn rounds t down to a multiple of d on the wall clock of location.
+func truncateIn(t time.Time, d time.Duration, location *time.Location) time.Time {
+	local := t.In(location)
+	wall := time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), local.Second(), local.Nanosecond(), time.UTC).Truncate(d)
+	return time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), location)
+}
+
+// zoneAligned reports whether every UTC offset location uses in [from, to] is a multiple of d, so
+// that buckets of d in UTC are also buckets of d on the local wall clock. An offset stays in
+// effect for much longer than a day, so checking once a day finds them all.
+func zoneAligned(location *time.Location, from time.Time, to time.Time, d time.Duration) bool {
+	for t := from; ; t = t.Add(24 * time.Hour) {
+		if t.After(to) {
+			t = to
+		}
+		if _, offset := t.In(location).Zone(); time.Duration(offset)*time.Second%d != 0 {
+			return false
+		}
+		if !t.Before(to) {
+			return true
+		}
+	}
+}
+
+// aggregateSource returns the rows to aggregate for [from, to), ordered by time: the rollups of
+// tier up to its watermark, followed by the rows of the next finer tier for the rest, down to raw
+// readings. Tier watermarks are aligned to their buckets, so no bucket is counted twice.
+func (s *readingService) aggregateSource(ctx context.Context, deviceID uint, metric string, tier models.RollupTier, from time.Time, to time.Time) ([]models.ReadingRollup, error) {
+	if tier == models.RollupTierRaw {
+		readings, err := s.readingRepo.ReadingFindForDevice(ctx, deviceID, metric, from, to)
+		if err != nil {
+			return nil, err
+		}
+		source := make([]models.ReadingRollup, 0, len(readings))
+		for _, reading := range readings {
+			source = append(source, models.ReadingRollup{
+				BucketStart: reading.RecordedAt,
+				Min:         reading.Value,
+				Max:         reading.Value,
+				Sum:         reading.Value,
+				Count:       1,
+				Last:        reading.Value,
+				LastAt:      reading.RecordedAt,
+			})
+		}
+		return source, nil
+	}
+
+	split := from
+	state, err := s.rollupRepo.RollupStateFind(ctx, tier)
+	if err == nil && state.RolledUpTo.After(from) {
+		split = state.RolledUpTo
+		if split.After(to) {
+			split = to
+		}
+	} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var source []models.ReadingRollup
+	if split.After(from) {
+		source, err = s.rollupRepo.RollupFindForDevice(ctx, deviceID, metric, tier, from, split)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if split.Before(to) {
+		rest, err := s.aggregateSource(ctx, deviceID, metric, tier.Finer(), split, to)
+		if err != nil {
+			return nil, err
+		}
+		source = append(source, rest...)
+	}
+	return source, nil
+}
+
+// ReadingLatest returns the newest value of every metric of the devices an API key can see: the
+// devices of its owner, or every device for admins, limited to the key's home when it has one.
+func (s *readingService) ReadingLatest(ctx context.Context, apiKey *models.APIKey) ([]models.ReadingLatest, error) {
+	var devices []models.Device
+	var err error
+	if apiKey.User.Role == models.UserRoleAdmin {
+		devices, err = s.deviceRepo.DeviceFindAll(ctx)
+	} else {
+		devices, err = s.deviceRepo.DeviceFindByUserID(ctx, apiKey.UserID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]*models.Device, len(devices))
+	deviceIDs := make([]uint, 0, len(devices))
+	for i := range devices {
+		if apiKey.Home != "" && devices[i].Home != apiKey.Home {
+			continue
+		}
+		byID[devices[i].ID] = &devices[i]
+		deviceIDs = append(deviceIDs, devices[i].ID)
+	}
+
+	readings, err := s.readingRepo.ReadingFindLatest(ctx, deviceIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]models.ReadingLatest, 0, len(readings))
+	for i, reading := range readings {
+		// Several readings can share the newest timestamp; the one stored last wins.
+		if i > 0 && readings[i-1].DeviceID == reading.DeviceID && readings[i-1].Metric == reading.Metric {
+			continue
+		}
+
+		device := byID[reading.DeviceID]
+		_, unit := newReadingConverter(device, apiKey.User, "").value(reading.Metric, reading.Value)
+		response = append(response, models.ReadingLatest{
+			DeviceUUID: device.UUID,
+			DeviceName: device.Name,
+			Home:       device.Home,
+			Room:       device.Room,
+			Metric:     reading.Metric,
+			Value:      reading.Value,
+			Unit:       unit,
+			RecordedAt: reading.RecordedAt,
+		})
+	}
+	return response, nil
+}
+
+func readingValue(capabilities *models.DeviceCapabilities, item models.ReadingInput) (float64, bool, error) {
+	if item.Value == nil {
+		return 0, false, errors.New("value is required")
+	}
+
+	if capabilities == nil {
+		switch v := item.Value.(type) {
+		case float64:
+			return v, false, nil
+		case bool:
+			if v {
+				return 1, false, nil
+			}
+			return 0, false, nil
+		default:
+			return 0, false, errors.New("value must be a number or a boolean")
+		}
+	}
+
+	metric := capabilities.Metric(item.Metric)
+	if metric == nil {
+		return 0, false, errors.New("metric is not declared by the device type")
+	}
+	return metric.Validate(item.Value)
+}
+
+// readingConverter turns stored readings into responses, attaching the declared unit of the metric
+// and, when preferred units are requested, converting values and times to the user's preferences.
+type readingConverter struct {
+	device       *models.Device
+	capabilities *models.DeviceCapabilities
+	preferred    models.UnitPreferences
+	location     *time.Location
+}
+
+func newReadingConverter(device *models.Device, user *models.User, units string) *readingConverter {
+	converter := &readingConverter{device: device, capabilities: device.Capabilities()}
+	if units == models.ReadingUnitsPreferred {
+		converter.preferred = user.PreferredUnits
+		converter.location = user.Location()
+	}
+	return converter
+}
+
+func (c *readingConverter) value(metric string, value float64) (float64, string) {
+	if c.capabilities == nil {
+		return value, ""
+	}
+
+	schema := c.capabilities.Metric(metric)
+	if schema == nil {
+		return value, ""
+	}
+
+	quantity, ok := utils.UnitQuantity(schema.Unit)
+	if !ok {
+		return value, schema.Unit
+	}
+
+	preferredUnit, ok := c.preferred[quantity]
+	if !ok {
+		return value, schema.Unit
+	}
+
+	converted, err := utils.ConvertUnit(value, schema.Unit, preferredUnit)
+	if err != nil {
+		return value, schema.Unit
+	}
+	return converted, preferredUnit
+}
+
+func (c *readingConverter) localTime(t time.Time) time.Time {
+	if c.location == nil {
+		return t
+	}
+	return t.In(c.location)
+}
+
+func (c *readingConverter) convert(reading *models.Reading) models.ReadingResponse {
+	value, unit := c.value(reading.Metric, reading.Value)
+	return models.ReadingResponse{
+		DeviceUUID: c.device.UUID,
+		Metric:     reading.Metric,
+		Value:      value,
+		Unit:       unit,
+		Flagged:    reading.Flagged,
+		RecordedAt: c.localTime(reading.RecordedAt),
+	}
+}
@@ -0,0 +1,221 @@
+package services_test
+
+import (
+	"context"
+	"home-monitor-backend/models"
+	"home-monitor-backend/repositories"
+	"home-monitor-backend/services"
+	"home-monitor-backend/testutil"
+	"testing"
+	"time"
+)
+
+type readingFixture struct {
+	h              *testutil.Harness
+	user           *models.User
+	device         *models.Device
+	readingService services.ReadingService
+	rollupService  services.RollupService
+}
+
+func newReadingFixture(t *testing.T) *readingFixture {
+	t.Helper()
+
+	h := testutil.New(t)
+	user := h.CreateUser("alice", "secret-password", models.UserRoleUser)
+	device := h.CreateDevice(models.Device{UserID: user.ID, Name: "thermometer"})
+
+	userRepo := repositories.NewUserRepository(h.DB)
+	deviceRepo := repositories.NewDeviceRepository(h.DB)
+	readingRepo := repositories.NewReadingRepository(h.DB)
+	rollupRepo := repositories.NewRollupRepository(h.DB)
+	rollupService := services.NewRollupService(readingRepo, rollupRepo)
+	return &readingFixture{
+		h:              h,
+		user:           user,
+		device:         device,
+		readingService: services.NewReadingService(userRepo, deviceRepo, readingRepo, rollupRepo, rollupService),
+		rollupService:  rollupService,
+	}
+}
+
+func (f *readingFixture) createReadings(t *testing.T, times ...time.Time) {
+	t.Helper()
+
+	for _, recordedAt := range times {
+		reading := &models.Reading{DeviceID: f.device.ID, Metric: "temperature", Value: 20, RecordedAt: recordedAt}
+		if err := f.h.DB.Create(reading).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// aggregateCount returns how many readings the aggregate of the last week counts.
+func (f *readingFixture) aggregateCount(t *testing.T, bucket string) int64 {
+	t.Helper()
+
+	now := time.Now()
+	response, err := f.readingService.ReadingAggregate(context.Background(), f.user.UUID, f.device.UUID, models.ReadingAggregateQuery{
+		Metric: "temperature",
+		From:   now.Add(-7 * 24 * time.Hour).Truncate(24 * time.Hour),
+		To:     now.Add(24 * time.Hour).Truncate(24 * time.Hour),
+		Bucket: bucket,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var count int64
+	for _, b := range response.Buckets {
+		count += b.Count
+	}
+	return count
+}
+
+func TestReadingAggregatePastWatermark(t *testing.T) {
+	t.Parallel()
+	f := newReadingFixture(t)
+
+	now := time.Now()
+	f.createReadings(t, now.Add(-72*time.Hour), now.Add(-30*time.Hour), now.Add(-2*time.Hour))
+	if err := f.rollupService.RollupRun(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// Newer than every watermark: only raw readings have them.
+	f.createReadings(t, time.Now())
+
+	for _, bucket := range []string{"15m", "1h", "1d"} {
+		if got := f.aggregateCount(t, bucket); got != 4 {
+			t.Errorf("bucket %s: count = %d, want 4", bucket, got)
+		}
+	}
+}
+
+func TestReadingIngestBehindWatermark(t *testing.T) {
+	t.Parallel()
+	f := newReadingFixture(t)
+
+	now := time.Now()
+	f.createReadings(t, now.Add(-72*time.Hour), now.Add(-2*time.Hour))
+	if err := f.rollupService.RollupRun(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	backfilled := now.Add(-50 * time.Hour)
+	response, err := f.readingService.ReadingIngest(context.Background(), models.ReadingIngestRequest{
+		Readings: []models.ReadingInput{{Metric: "temperature", Value: 20.0, RecordedAt: &backfilled}},
+	}, f.device)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Accepted != 1 {
+		t.Fatalf("accepted = %d, want 1", response.Accepted)
+	}
+
+	// The minute rollups must hold the reading before retention deletes the raw row.
+	var minutes int64
+	if err := f.h.DB.Model(&models.ReadingRollup{}).
+		Where("tier = ? AND bucket_start = ?", models.RollupTierMinute, backfilled.UTC().Truncate(time.Minute)).
+		Count(&minutes).Error; err != nil {
+		t.Fatal(err)
+	}
+	if minutes != 1 {
+		t.Errorf("minute rollups = %d, want 1", minutes)
+	}
+
+	for _, bucket := range []string{"1h", "1d"} {
+		if got := f.aggregateCount(t, bucket); got != 3 {
+			t.Errorf("bucket %s: count = %d, want 3", bucket, got)
+		}
+	}
+}
+
+func TestReadingIngestAfterRetention(t *testing.T) {
+	t.Parallel()
+	f := newReadingFixture(t)
+
+	now := time.Now()
+	old := now.Add(-72 * time.Hour).Truncate(time.Minute)
+	f.createReadings(t, old, now.Add(-2*time.Hour))
+	if err := f.rollupService.RollupRun(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Retention deletes the old raw reading; only the rollups remember it.
+	if err := f.h.DB.Create(&models.RetentionPolicy{RawDays: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+	retentionService := services.NewRetentionService(
+		repositories.NewUserRepository(f.h.DB),
+		repositories.NewDeviceTypeRepository(f.h.DB),
+		repositories.NewReadingRepository(f.h.DB),
+		repositories.NewRollupRepository(f.h.DB),
+		repositories.NewRetentionRepository(f.h.DB),
+	)
+	if err := retentionService.RetentionRun(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	late := old.Add(30 * time.Second)
+	if _, err := f.readingService.ReadingIngest(context.Background(), models.ReadingIngestRequest{
+		Readings: []models.ReadingInput{{Metric: "temperature", Value: 30.0, RecordedAt: &late}},
+	}, f.device); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tier := range models.RollupTiers {
+		var rollup models.ReadingRollup
+		if err := f.h.DB.Where("tier = ? AND bucket_start = ?", tier, old.UTC().Truncate(tier.Duration())).First(&rollup).Error; err != nil {
+			t.Fatal(err)
+		}
+		if rollup.Count != 2 || rollup.Sum != 50 || rollup.Min != 20 || rollup.Max != 30 {
+			t.Errorf("tier %s: count %d, sum %g, min %g, max %g, want 2, 50, 20, 30", tier, rollup.Count, rollup.Sum, rollup.Min, rollup.Max)
+		}
+	}
+
+	for _, bucket := range []string{"15m", "1h", "1d"} {
+		if got := f.aggregateCount(t, bucket); got != 3 {
+			t.Errorf("bucket %s: count = %d, want 3", bucket, got)
+		}
+	}
+}
+
+func TestReadingAggregateLocalDays(t *testing.T) {
+	t.Parallel()
+	f := newReadingFixture(t)
+	if err := f.h.DB.Model(f.user).Update("timezone", "Asia/Jakarta").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// Both readings fall on the same day in Jakarta (UTC+7) but on different days in UTC.
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().In(jakarta)
+	day := time.Date(now.Year(), now.Month(), now.Day()-3, 0, 0, 0, 0, jakarta)
+	f.createReadings(t, day.Add(time.Hour), day.Add(20*time.Hour))
+	if err := f.rollupService.RollupRun(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := f.readingService.ReadingAggregate(context.Background(), f.user.UUID, f.device.UUID, models.ReadingAggregateQuery{
+		Metric: "temperature",
+		From:   day,
+		To:     day.AddDate(0, 0, 1),
+		Bucket: "1d",
+		Units:  models.ReadingUnitsPreferred,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Buckets) != 1 {
+		t.Fatalf("buckets = %d, want 1", len(response.Buckets))
+	}
+	if got := response.Buckets[0]; !got.Start.Equal(day) || got.Count != 2 {
+		t.Errorf("bucket = %s with %d readings, want %s with 2", got.Start, got.Count, day)
+	}
+	if response.Tier != models.RollupTierHour {
+		t.Errorf("tier = %s, want %s for days not aligned to UTC", response.Tier, models.RollupTierHour)
+	}
+}
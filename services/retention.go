@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"home-monitor-backend/models"
+	"home-monitor-backend/repositories"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RetentionService interface {
+	RetentionList(ctx context.Context, userUUID uuid.UUID) ([]models.RetentionPolicy, error)
+	RetentionSave(ctx context.Context, input models.RetentionPolicyRequest, userUUID uuid.UUID) (*models.RetentionPolicy, error)
+	RetentionRun(ctx context.Context) error
+}
+
+type retentionService struct {
+	userRepo       repositories.UserRepository
+	deviceTypeRepo repositories.DeviceTypeRepository
+	readingRepo    repositories.ReadingRepository
+	rollupRepo     repositories.RollupRepository
+	retentionRepo  repositories.RetentionRepository
+}
+
+func NewRetentionService(userRepo repositories.UserRepository, deviceTypeRepo repositories.DeviceTypeRepository, readingRepo repositories.ReadingRepository, rollupRepo repositories.RollupRepository, retentionRepo repositories.RetentionRepository) RetentionService {
+	return &retentionService{
+		userRepo:       userRepo,
+		deviceTypeRepo: deviceTypeRepo,
+		readingRepo:    readingRepo,
+		rollupRepo:     rollupRepo,
+		retentionRepo:  retentionRepo,
+	}
+}
+
+func (s *retentionService) RetentionList(ctx context.Context, userUUID uuid.UUID) ([]models.RetentionPolicy, error) {
+	if err := s.requireAdmin(ctx, userUUID); err != nil {
+		return nil, err
+	}
+
+	policies, err := s.retentionRepo.RetentionPolicyFindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// RetentionSave creates or replaces the policy of a device type or a home, or the default policy
+// when neither is given.
+func (s *retentionService) RetentionSave(ctx context.Context, input models.RetentionPolicyRequest, userUUID uuid.UUID) (*models.RetentionPolicy, error) {
+	if err := s.requireAdmin(ctx, userUUID); err != nil {
+		return nil, err
+	}
+
+	if input.DeviceTypeUUID != nil && input.Home != nil {
+		return nil, ErrRetentionScope
+	}
+
+	var deviceType *models.DeviceType
+	var deviceTypeID *uint
+	if input.DeviceTypeUUID != nil {
+		var err error
+		deviceType, err = s.deviceTypeRepo.DeviceTypeFindByUUID(ctx, *input.DeviceTypeUUID)
+		if err != nil {
+			return nil, ErrDeviceTypeNotFound
+		}
+		deviceTypeID = &deviceType.ID
+	}
+
+	policy, err := s.retentionRepo.RetentionPolicyFindByScope(ctx, deviceTypeID, input.Home)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		policy = &models.RetentionPolicy{DeviceTypeID: deviceTypeID, Home: input.Home}
+	} else if err != nil {
+		return nil, err
+	}
+
+	policy.DeviceType = deviceType
+	policy.RawDays = input.RawDays
+	policy.MinuteDays = input.MinuteDays
+	policy.HourDays = input.HourDays
+	policy.DayDays = input.DayDays
+
+	if err := s.retentionRepo.RetentionPolicySave(ctx, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// RetentionRun deletes the readings and rollups that are older than their policy allows. A device
+// follows the policy of its home, else the policy of its type, else the default. Raw readings are
+// never deleted before they have been rolled up.
+func (s *retentionService) RetentionRun(ctx context.Context) error {
+	policies, err := s.retentionRepo.RetentionPolicyFindAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	var homes []string
+	var typed []uint
+	for _, policy := range policies {
+		if policy.Home != nil {
+			homes = append(homes, *policy.Home)
+		}
+		if policy.DeviceTypeID != nil {
+			typed = append(typed, *policy.DeviceTypeID)
+		}
+	}
+
+	rawLimit := time.Time{}
+	if state, err := s.rollupRepo.RollupStateFind(ctx, models.RollupTierMinute); err == nil {
+		rawLimit = state.RolledUpTo
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	now := time.Now()
+	for _, policy := range policies {
+		scope := models.RetentionScope{Home: policy.Home, DeviceTypeID: policy.DeviceTypeID, ExcludeHomes: homes}
+		if policy.Home == nil && policy.DeviceTypeID == nil {
+			scope.ExcludeDeviceTypeIDs = typed
+		}
+
+		if days := policy.Days(models.RollupTierRaw); days > 0 {
+			cutoff := now.AddDate(0, 0, -days)
+			if cutoff.After(rawLimit) {
+				cutoff = rawLimit
+			}
+			deleted, err := s.readingRepo.ReadingDeleteBefore(ctx, scope, cutoff)
+			if err != nil {
+				return err
+			}
+			if deleted > 0 {
+				slog.InfoContext(ctx, "Retention deleted readings", "count", deleted)
+			}
+		}
+
+		for _, tier := range models.RollupTiers {
+			days := policy.Days(tier)
+			if days == 0 {
+				continue
+			}
+			deleted, err := s.rollupRepo.RollupDeleteBefore(ctx, scope, tier, now.AddDate(0, 0, -days))
+			if err != nil {
+				return err
+			}
+			if deleted > 0 {
+				slog.InfoContext(ctx, "Retention deleted rollups", "tier", tier, "count", deleted)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *retentionService) requireAdmin(ctx context.Context, userUUID uuid.UUID) error {
+	user, err := s.userRepo.UserFindByUUID(ctx, userUUID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+
+	if user.Role != models.UserRoleAdmin {
+		return errAdminRequired("manage retention policies")
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"home-monitor-backend/apperrors"
+	"home-monitor-backend/models"
+	"home-monitor-backend/repositories"
+	"home-monitor-backend/services"
+	"home-monitor-backend/testutil"
+	"testing"
+	"time"
+)
+
+func TestRetentionRunPolicyPrecedence(t *testing.T) {
+	t.Parallel()
+
+	h := testutil.New(t)
+	admin := h.CreateUser("admin", "admin-password", models.UserRoleAdmin)
+	retentionService := services.NewRetentionService(
+		repositories.NewUserRepository(h.DB),
+		repositories.NewDeviceTypeRepository(h.DB),
+		repositories.NewReadingRepository(h.DB),
+		repositories.NewRollupRepository(h.DB),
+		repositories.NewRetentionRepository(h.DB),
+	)
+
+	shortLived := h.CreateDeviceType("motion-sensor")
+	longLived := h.CreateDeviceType("energy-meter")
+
+	cabin := "cabin"
+	policies := []models.RetentionPolicyRequest{
+		{HourDays: 3},
+		{DeviceTypeUUID: &shortLived.UUID, HourDays: 3},
+		{DeviceTypeUUID: &longLived.UUID, HourDays: 30},
+		{Home: &cabin, HourDays: 10},
+	}
+	for _, policy := range policies {
+		if _, err := retentionService.RetentionSave(context.Background(), policy, admin.UUID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		home       string
+		deviceType *models.DeviceType
+		kept       bool
+	}{
+		{name: "home over device type", home: cabin, deviceType: shortLived, kept: true},
+		{name: "device type", home: "main", deviceType: shortLived, kept: false},
+		{name: "device type over default", home: "main", deviceType: longLived, kept: true},
+		{name: "default", home: "main", kept: false},
+	}
+
+	bucketStart := time.Now().UTC().Add(-5 * 24 * time.Hour).Truncate(time.Hour)
+	devices := make([]*models.Device, len(tests))
+	for i, tt := range tests {
+		device := models.Device{UserID: admin.ID, Name: tt.name, Home: tt.home}
+		if tt.deviceType != nil {
+			device.DeviceTypeID = &tt.deviceType.ID
+		}
+		devices[i] = h.CreateDevice(device)
+		rollup := &models.ReadingRollup{DeviceID: devices[i].ID, Metric: "temperature", Tier: models.RollupTierHour, BucketStart: bucketStart, Count: 1, LastAt: bucketStart}
+		if err := h.DB.Create(rollup).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := retentionService.RetentionRun(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, tt := range tests {
+		var count int64
+		if err := h.DB.Model(&models.ReadingRollup{}).Where("device_id = ?", devices[i].ID).Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		if kept := count == 1; kept != tt.kept {
+			t.Errorf("%s: kept = %v, want %v", tt.name, kept, tt.kept)
+		}
+	}
+}
+
+func TestRetentionSaveHomeAndDeviceType(t *testing.T) {
+	t.Parallel()
+
+	h := testutil.New(t)
+	admin := h.CreateUser("admin", "admin-password", models.UserRoleAdmin)
+	retentionService := services.NewRetentionService(
+		repositories.NewUserRepository(h.DB),
+		repositories.NewDeviceTypeRepository(h.DB),
+		repositories.NewReadingRepository(h.DB),
+		repositories.NewRollupRepository(h.DB),
+		repositories.NewRetentionRepository(h.DB),
+	)
+
+	deviceType := h.CreateDeviceType("thermostat")
+
+	home := "cabin"
+	_, err := retentionService.RetentionSave(context.Background(), models.RetentionPolicyRequest{DeviceTypeUUID: &deviceType.UUID, Home: &home}, admin.UUID)
+	var appErr *apperrors.Error
+	if !errors.As(err, &appErr) || appErr.Code != "invalid_retention_scope" {
+		t.Fatalf("err = %v, want invalid_retention_scope", err)
+	}
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"home-monitor-backend/models"
+	"home-monitor-backend/repositories"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// rollupChunks bounds how much source data a single rollup step loads into memory.
+var rollupChunks = map[models.RollupTier]time.Duration{
+	models.RollupTierMinute: 10 * time.Minute,
+	models.RollupTierHour:   6 * time.Hour,
+	models.RollupTierDay:    7 * 24 * time.Hour,
+}
+
+type RollupService interface {
+	RollupRun(ctx context.Context) error
+	RollupRange(ctx context.Context, from time.Time, to time.Time) error
+	RollupMerge(ctx context.Context, readings []models.Reading) error
+}
+
+type rollupService struct {
+	readingRepo repositories.ReadingRepository
+	rollupRepo  repositories.RollupRepository
+}
+
+func NewRollupService(readingRepo repositories.ReadingRepository, rollupRepo repositories.RollupRepository) RollupService {
+	return &rollupService{readingRepo: readingRepo, rollupRepo: rollupRepo}
+}
+
+// RollupRun materializes every complete bucket of every tier that has not been rolled up yet.
+// A tier never gets ahead of the tier it is built from.
+func (s *rollupService) RollupRun(ctx context.Context) error {
+	limit := time.Now().UTC()
+	for _, tier := range models.RollupTiers {
+		end := limit.Truncate(tier.Duration())
+
+		state, err := s.rollupState(ctx, tier, end)
+		if err != nil {
+			return err
+		}
+
+		for state.RolledUpTo.Before(end) {
+			to := state.RolledUpTo.Add(rollupChunks[tier])
+			if to.After(end) {
+				to = end
+			}
+
+			if err := s.rollupWindow(ctx, tier, state.RolledUpTo, to); err != nil {
+				return err
+			}
+
+			state.RolledUpTo = to
+			if err := s.rollupRepo.RollupStateSave(ctx, state); err != nil {
+				return err
+			}
+		}
+
+		limit = state.RolledUpTo
+	}
+	return nil
+}
+
+// RollupRange recomputes the already materialized buckets overlapping [from, to) from the tier
+// below, e.g. after seeding history. Buckets whose source retention has deleted are rebuilt from
+// whatever is left, so readings arriving late go through RollupMerge instead.
+func (s *rollupService) RollupRange(ctx context.Context, from time.Time, to time.Time) error {
+	for _, tier := range models.RollupTiers {
+		state, err := s.rollupRepo.RollupStateFind(ctx, tier)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		start := from.UTC().Truncate(tier.Duration())
+		end := to.UTC().Truncate(tier.Duration())
+		if end.Before(to) {
+			end = end.Add(tier.Duration())
+		}
+		if end.After(state.RolledUpTo) {
+			end = state.RolledUpTo
+		}
+
+		for start.Before(end) {
+			next := start.Add(rollupChunks[tier])
+			if next.After(end) {
+				next = end
+			}
+
+			if err := s.rollupWindow(ctx, tier, start, next); err != nil {
+				return err
+			}
+			start = next
+		}
+	}
+	return nil
+}
+
+// RollupMerge adds readings that arrived behind the watermark of a tier to the buckets of that
+// tier. The buckets are merged rather than rebuilt, as retention may already have deleted the
+// readings and finer rollups they summarize. Readings ahead of a watermark are left to RollupRun.
+func (s *rollupService) RollupMerge(ctx context.Context, readings []models.Reading) error {
+	for _, tier := range models.RollupTiers {
+		state, err := s.rollupRepo.RollupStateFind(ctx, tier)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		buckets := newRollupBuckets(tier)
+		for i := range readings {
+			if readings[i].RecordedAt.Before(state.RolledUpTo) {
+				buckets.addReading(&readings[i])
+			}
+		}
+		if err := s.rollupRepo.RollupMerge(ctx, buckets.rollups()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rollupState loads the watermark of a tier, starting a new tier at the first stored reading.
+func (s *rollupService) rollupState(ctx context.Context, tier models.RollupTier, end time.Time) (*models.RollupState, error) {
+	state, err := s.rollupRepo.RollupStateFind(ctx, tier)
+	if err == nil {
+		return state, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	first, err := s.readingRepo.ReadingFirstRecordedAt(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	state = &models.RollupState{Tier: tier, RolledUpTo: end}
+	if first != nil && first.Before(end) {
+		state.RolledUpTo = first.UTC().Truncate(tier.Duration())
+	}
+	return state, nil
+}
+
+func (s *rollupService) rollupWindow(ctx context.Context, tier models.RollupTier, from time.Time, to time.Time) error {
+	buckets := newRollupBuckets(tier)
+
+	if tier == models.RollupTierMinute {
+		readings, err := s.readingRepo.ReadingFindBetween(ctx, from, to)
+		if err != nil {
+			return err
+		}
+		for i := range readings {
+			buckets.addReading(&readings[i])
+		}
+	} else {
+		rollups, err := s.rollupRepo.RollupFindBetween(ctx, tier.Finer(), from, to)
+		if err != nil {
+			return err
+		}
+		for i := range rollups {
+			buckets.addRollup(&rollups[i])
+		}
+	}
+
+	return s.rollupRepo.RollupUpsert(ctx, buckets.rollups())
+}
+
+type rollupKey struct {
+	deviceID uint
+	metric   string
+	bucket   int64
+}
+
+// rollupBuckets accumulates readings or finer rollups into the buckets of one tier.
+type rollupBuckets struct {
+	tier    models.RollupTier
+	buckets map[rollupKey]*models.ReadingRollup
+	order   []rollupKey
+}
+
+func newRollupBuckets(tier models.RollupTier) *rollupBuckets {
+	return &rollupBuckets{tier: tier, buckets: make(map[rollupKey]*models.ReadingRollup)}
+}
+
+func (b *rollupBuckets) addReading(reading *models.Reading) {
+	b.add(&models.ReadingRollup{
+		DeviceID:    reading.DeviceID,
+		Metric:      reading.Metric,
+		BucketStart: reading.RecordedAt,
+		Min:         reading.Value,
+		Max:         reading.Value,
+		Sum:         reading.Value,
+		Count:       1,
+		Last:        reading.Value,
+		LastAt:      reading.RecordedAt,
+	})
+}
+
+func (b *rollupBuckets) addRollup(rollup *models.ReadingRollup) {
+	b.add(rollup)
+}
+
+func (b *rollupBuckets) add(source *models.ReadingRollup) {
+	start := source.BucketStart.UTC().Truncate(b.tier.Duration())
+	key := rollupKey{deviceID: source.DeviceID, metric: source.Metric, bucket: start.Unix()}
+
+	rollup, exists := b.buckets[key]
+	if !exists {
+		rollup = &models.ReadingRollup{DeviceID: source.DeviceID, Metric: source.Metric, Tier: b.tier, BucketStart: start}
+		b.buckets[key] = rollup
+		b.order = append(b.order, key)
+	}
+	rollup.Merge(source)
+}
+
+func (b *rollupBuckets) rollups() []models.ReadingRollup {
+	rollups := make([]models.ReadingRollup, 0, len(b.order))
+	for _, key := range b.order {
+		rollups = append(rollups, *b.buckets[key])
+	}
+	return rollups
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"home-monitor-backend/models"
+	"home-monitor-backend/repositories"
+	"home-monitor-backend/utils"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// SetupService creates the first administrator of a fresh installation. While no administrator
+// exists, SetupStart opens the setup with a random one-time token; SetupComplete consumes it.
+type SetupService interface {
+	SetupStart(ctx context.Context) (string, error)
+	SetupStatus(ctx context.Context) (bool, error)
+	SetupComplete(ctx context.Context, input models.SetupRequest) (*models.User, string, error)
+}
+
+type setupService struct {
+	userRepo repositories.UserRepository
+	signer   *utils.JWTSigner
+
+	mu    sync.Mutex
+	token string
+}
+
+func NewSetupService(userRepo repositories.UserRepository, signer *utils.JWTSigner) SetupService {
+	return &setupService{userRepo: userRepo, signer: signer}
+}
+
+// SetupStart returns the setup token, or an empty string when an administrator already exists.
+func (s *setupService) SetupStart(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	admins, err := s.userRepo.UserCountByRole(ctx, models.UserRoleAdmin)
+	if err != nil {
+		return "", err
+	}
+	if admins > 0 {
+		s.token = ""
+		return "", nil
+	}
+
+	token, err := utils.GenerateSetupToken()
+	if err != nil {
+		return "", err
+	}
+	s.token = token
+	return token, nil
+}
+
+// SetupStatus reports whether the setup is still open. An administrator created from the command
+// line in the meantime closes it.
+func (s *setupService) SetupStatus(ctx context.Context) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == "" {
+		return false, nil
+	}
+	admins, err := s.userRepo.UserCountByRole(ctx, models.UserRoleAdmin)
+	if err != nil {
+		return false, err
+	}
+	return admins == 0, nil
+}
+
+func (s *setupService) SetupComplete(ctx context.Context, input models.SetupRequest) (*models.User, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == "" {
+		return nil, "", ErrSetupCompleted
+	}
+	if subtle.ConstantTimeCompare([]byte(input.Token), []byte(s.token)) != 1 {
+		return nil, "", ErrInvalidSetupToken
+	}
+
+	admins, err := s.userRepo.UserCountByRole(ctx, models.UserRoleAdmin)
+	if err != nil {
+		return nil, "", err
+	}
+	if admins > 0 {
+		s.token = ""
+		return nil, "", ErrSetupCompleted
+	}
+	if _, err := s.userRepo.UserFindByUsername(ctx, input.Username); err == nil {
+		return nil, "", ErrUsernameTaken
+	}
+
+	user := &models.User{
+		UUID:     uuid.New(),
+		Username: input.Username,
+		Password: input.Password,
+		Role:     models.UserRoleAdmin,
+	}
+	if err := s.userRepo.UserCreate(ctx, user); err != nil {
+		return nil, "", err
+	}
+	s.token = ""
+
+	token, err := s.signer.Generate(user.UUID)
+	if err != nil {
+		return nil, "", err
+	}
+	return user, token, nil
+}
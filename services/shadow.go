@@ -0,0 +1,192 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"home-monitor-backend/apperrors"
+	"home-monitor-backend/events"
+	"home-monitor-backend/models"
+	"home-monitor-backend/repositories"
+	"home-monitor-backend/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const ShadowEventDelta = "delta"
+
+type ShadowService interface {
+	ShadowGet(ctx context.Context, userUUID uuid.UUID, deviceUUID uuid.UUID) (*models.ShadowResponse, error)
+	ShadowUpdateDesired(ctx context.Context, input models.ShadowDesiredRequest, userUUID uuid.UUID, deviceUUID uuid.UUID) (*models.ShadowResponse, error)
+	ShadowSubscribe(ctx context.Context, userUUID uuid.UUID, deviceUUID uuid.UUID) (<-chan events.Event, func(), error)
+	ShadowDeviceGet(ctx context.Context, device *models.Device) (*models.ShadowResponse, error)
+	ShadowUpdateReported(ctx context.Context, input models.ShadowReportedRequest, device *models.Device) (*models.ShadowResponse, error)
+	ShadowDeviceSubscribe(ctx context.Context, device *models.Device) (<-chan events.Event, func())
+}
+
+type shadowService struct {
+	userRepo   repositories.UserRepository
+	deviceRepo repositories.DeviceRepository
+	shadowRepo repositories.ShadowRepository
+	broker     *events.Broker
+}
+
+func NewShadowService(userRepo repositories.UserRepository, deviceRepo repositories.DeviceRepository, shadowRepo repositories.ShadowRepository, broker *events.Broker) ShadowService {
+	return &shadowService{userRepo: userRepo, deviceRepo: deviceRepo, shadowRepo: shadowRepo, broker: broker}
+}
+
+func (s *shadowService) ShadowGet(ctx context.Context, userUUID uuid.UUID, deviceUUID uuid.UUID) (*models.ShadowResponse, error) {
+	_, device, err := findUserDevice(ctx, s.userRepo, s.deviceRepo, userUUID, deviceUUID)
+	if err != nil {
+		return nil, err
+	}
+	return s.ShadowDeviceGet(ctx, device)
+}
+
+func (s *shadowService) ShadowUpdateDesired(ctx context.Context, input models.ShadowDesiredRequest, userUUID uuid.UUID, deviceUUID uuid.UUID) (*models.ShadowResponse, error) {
+	_, device, err := findUserDevice(ctx, s.userRepo, s.deviceRepo, userUUID, deviceUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.shadowUpdate(ctx, device, input.Version, func(shadow *models.DeviceShadow) error {
+		desired, err := utils.MergePatch(shadow.Desired, input.Desired)
+		if err != nil {
+			return err
+		}
+		shadow.Desired = desired
+		return nil
+	})
+}
+
+func (s *shadowService) ShadowSubscribe(ctx context.Context, userUUID uuid.UUID, deviceUUID uuid.UUID) (<-chan events.Event, func(), error) {
+	_, device, err := findUserDevice(ctx, s.userRepo, s.deviceRepo, userUUID, deviceUUID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ch, unsubscribe := s.ShadowDeviceSubscribe(ctx, device)
+	return ch, unsubscribe, nil
+}
+
+func (s *shadowService) ShadowDeviceGet(ctx context.Context, device *models.Device) (*models.ShadowResponse, error) {
+	shadow, err := s.shadowFind(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := shadowResponse(device, shadow)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (s *shadowService) ShadowUpdateReported(ctx context.Context, input models.ShadowReportedRequest, device *models.Device) (*models.ShadowResponse, error) {
+	return s.shadowUpdate(ctx, device, input.Version, func(shadow *models.DeviceShadow) error {
+		reported, err := utils.MergePatch(shadow.Reported, input.Reported)
+		if err != nil {
+			return err
+		}
+		shadow.Reported = reported
+		return nil
+	})
+}
+
+func (s *shadowService) ShadowDeviceSubscribe(ctx context.Context, device *models.Device) (<-chan events.Event, func()) {
+	return s.broker.Subscribe(shadowTopic(device))
+}
+
+func (s *shadowService) shadowFind(ctx context.Context, device *models.Device) (*models.DeviceShadow, error) {
+	shadow, err := s.shadowRepo.ShadowFindByDeviceID(ctx, device.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.DeviceShadow{DeviceID: device.ID}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return shadow, nil
+}
+
+// shadowUpdate applies change to the current shadow and stores it under the next version.
+// A stale expectedVersion, or a concurrent writer, results in a conflict.
+func (s *shadowService) shadowUpdate(ctx context.Context, device *models.Device, expectedVersion *uint, change func(shadow *models.DeviceShadow) error) (*models.ShadowResponse, error) {
+	shadow, err := s.shadowFind(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+
+	if expectedVersion != nil && *expectedVersion != shadow.Version {
+		return nil, ErrShadowVersion
+	}
+
+	oldDelta, err := utils.JSONDelta(shadow.Desired, shadow.Reported)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := change(shadow); err != nil {
+		return nil, apperrors.Validation("invalid_merge_patch", "invalid merge patch: {0}", err.Error())
+	}
+
+	previousVersion := shadow.Version
+	shadow.Version++
+	if previousVersion == 0 {
+		created, err := s.shadowRepo.ShadowCreate(ctx, shadow)
+		if err != nil {
+			return nil, err
+		}
+		if !created {
+			return nil, ErrShadowModified
+		}
+	} else {
+		updated, err := s.shadowRepo.ShadowUpdate(ctx, shadow, previousVersion)
+		if err != nil {
+			return nil, err
+		}
+		if !updated {
+			return nil, ErrShadowModified
+		}
+	}
+
+	response, err := shadowResponse(device, shadow)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(oldDelta, response.Delta) {
+		s.broker.Publish(shadowTopic(device), events.Event{Type: ShadowEventDelta, Data: response})
+	}
+	return response, nil
+}
+
+func shadowTopic(device *models.Device) string {
+	return "shadow:" + device.UUID.String()
+}
+
+func shadowResponse(device *models.Device, shadow *models.DeviceShadow) (*models.ShadowResponse, error) {
+	delta, err := utils.JSONDelta(shadow.Desired, shadow.Reported)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.ShadowResponse{
+		DeviceUUID: device.UUID,
+		Desired:    emptyObject(shadow.Desired),
+		Reported:   emptyObject(shadow.Reported),
+		Delta:      delta,
+		Version:    shadow.Version,
+	}
+	if shadow.Version > 0 {
+		response.UpdatedAt = &shadow.UpdatedAt
+	}
+	return response, nil
+}
+
+func emptyObject(data []byte) []byte {
+	if len(data) == 0 {
+		return []byte("{}")
+	}
+	return data
+}
@@ -1,44 +1,57 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"home-monitor-backend/apperrors"
+	"home-monitor-backend/metrics"
 	"home-monitor-backend/models"
 	"home-monitor-backend/repositories"
 	"home-monitor-backend/utils"
-	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type UserService interface {
-	UserRegister(input models.UserRegisterRequest, userUUID uuid.UUID) (*models.User, int, error)
-	UserLogin(input models.UserLoginRequest) (*models.User, string, int, error)
-	UserProfile(userUUID uuid.UUID) (*models.User, int, error)
-	UserUpdate(userUUID uuid.UUID, userUpdate *models.UserUpdateRequest) (*models.User, int, error)
+	UserRegister(ctx context.Context, input models.UserRegisterRequest, userUUID uuid.UUID) (*models.User, error)
+	UserLogin(ctx context.Context, input models.UserLoginRequest) (*models.User, string, error)
+	UserProfile(ctx context.Context, userUUID uuid.UUID) (*models.User, error)
+	UserUpdate(ctx context.Context, userUUID uuid.UUID, userUpdate *models.UserUpdateRequest) (*models.User, error)
+	UserPreferencesUpdate(ctx context.Context, userUUID uuid.UUID, input models.UserPreferencesRequest) (*models.User, error)
+
+	// The following act with administrator rights and back the command line.
+	UserCreate(ctx context.Context, input models.UserCreateRequest) (*models.User, error)
+	UserList(ctx context.Context) ([]models.User, error)
+	UserDetail(ctx context.Context, username string) (*models.User, error)
+	UserResetPassword(ctx context.Context, username string, password string) (*models.User, error)
+	UserSetRole(ctx context.Context, username string, role models.UserRole) (*models.User, error)
+	UserIssueToken(ctx context.Context, username string, expiration time.Duration) (*models.User, string, error)
 }
 
 type userService struct {
 	userRepo repositories.UserRepository
+	signer   *utils.JWTSigner
 }
 
-func NewUserService(userRepo repositories.UserRepository) UserService {
-	return &userService{userRepo: userRepo}
+func NewUserService(userRepo repositories.UserRepository, signer *utils.JWTSigner) UserService {
+	return &userService{userRepo: userRepo, signer: signer}
 }
 
-func (s *userService) UserRegister(input models.UserRegisterRequest, userUUID uuid.UUID) (*models.User, int, error) {
-	user, err := s.userRepo.UserFindByUUID(userUUID)
+func (s *userService) UserRegister(ctx context.Context, input models.UserRegisterRequest, userUUID uuid.UUID) (*models.User, error) {
+	user, err := s.userRepo.UserFindByUUID(ctx, userUUID)
 	if err != nil {
-		return nil, http.StatusNotFound, errors.New("user not found")
+		return nil, ErrUserNotFound
 	}
 
 	if user.Role != models.UserRoleAdmin {
-		return nil, http.StatusForbidden, errors.New("only admin can register new users")
+		return nil, errAdminRequired("register new users")
 	}
 
-	_, err = s.userRepo.UserFindByUsername(input.Username)
+	_, err = s.userRepo.UserFindByUsername(ctx, input.Username)
 	if err == nil {
-		return nil, http.StatusConflict, errors.New("username already exists")
+		return nil, ErrUsernameTaken
 	}
 
 	newUser := &models.User{
@@ -48,54 +61,55 @@ func (s *userService) UserRegister(input models.UserRegisterRequest, userUUID uu
 		Role:     models.UserRoleUser,
 	}
 
-	if err := s.userRepo.UserCreate(newUser); err != nil {
-		return nil, http.StatusInternalServerError, err
+	if err := s.userRepo.UserCreate(ctx, newUser); err != nil {
+		return nil, err
 	}
-	return newUser, http.StatusCreated, nil
+	return newUser, nil
 }
 
-func (s *userService) UserLogin(input models.UserLoginRequest) (*models.User, string, int, error) {
-	user, err := s.userRepo.UserFindByUsername(input.Username)
+func (s *userService) UserLogin(ctx context.Context, input models.UserLoginRequest) (*models.User, string, error) {
+	user, err := s.userRepo.UserFindByUsername(ctx, input.Username)
 	if err != nil || !user.CheckPassword(input.Password) {
-		return nil, "", http.StatusUnauthorized, errors.New("invalid username or password")
+		metrics.LoginFailures.Inc()
+		return nil, "", ErrInvalidCredentials
 	}
 
-	token, err := utils.GenerateJWT(user.UUID)
+	token, err := s.signer.Generate(user.UUID)
 	if err != nil {
-		return nil, "", http.StatusInternalServerError, err
+		return nil, "", err
 	}
-	return user, token, http.StatusOK, nil
+	return user, token, nil
 }
 
-func (s *userService) UserProfile(userUUID uuid.UUID) (*models.User, int, error) {
-	user, err := s.userRepo.UserFindByUUID(userUUID)
+func (s *userService) UserProfile(ctx context.Context, userUUID uuid.UUID) (*models.User, error) {
+	user, err := s.userRepo.UserFindByUUID(ctx, userUUID)
 	if err != nil {
-		return nil, http.StatusNotFound, errors.New("user not found")
+		return nil, ErrUserNotFound
 	}
-	return user, http.StatusOK, nil
+	return user, nil
 }
 
-func (s *userService) UserUpdate(userUUID uuid.UUID, userUpdate *models.UserUpdateRequest) (*models.User, int, error) {
-	user, err := s.userRepo.UserFindByUUID(userUUID)
+func (s *userService) UserUpdate(ctx context.Context, userUUID uuid.UUID, userUpdate *models.UserUpdateRequest) (*models.User, error) {
+	user, err := s.userRepo.UserFindByUUID(ctx, userUUID)
 	if err != nil {
-		return user, http.StatusNotFound, errors.New("user not found")
+		return user, ErrUserNotFound
 	}
 
 	if userUpdate.Username == "" && userUpdate.Password == "" {
-		return user, http.StatusBadRequest, errors.New("need to provide username or password to update")
+		return user, apperrors.Validation("credentials_required", "need to provide username or password to update")
 	}
 
-	userToUpdate, err := s.userRepo.UserFindByUsername(userUpdate.Username)
+	userToUpdate, err := s.userRepo.UserFindByUsername(ctx, userUpdate.Username)
 	if err != nil {
-		return user, http.StatusUnauthorized, errors.New("username or password is incorrect")
+		return user, ErrInvalidCredentials
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(userToUpdate.Password), []byte(userUpdate.Password)); err != nil {
-		return user, http.StatusUnauthorized, errors.New("username or password is incorrect")
+		return user, ErrInvalidCredentials
 	}
 
 	if userUpdate.NewUsername == "" && userUpdate.NewPassword == "" && userUpdate.Role == nil {
-		return user, http.StatusBadRequest, errors.New("need to provide at least one field to update")
+		return user, ErrNothingToUpdate
 	}
 
 	if userUpdate.NewUsername != "" {
@@ -106,18 +120,138 @@ func (s *userService) UserUpdate(userUUID uuid.UUID, userUpdate *models.UserUpda
 		userToUpdate.Password = userUpdate.NewPassword
 		err = userToUpdate.HashPassword()
 		if err != nil {
-			return user, http.StatusInternalServerError, errors.New("failed to update")
+			return user, errors.New("failed to update")
 		}
 	}
 
 	if userUpdate.Role != nil {
 		if user.Role != models.UserRoleAdmin {
-			return user, http.StatusForbidden, errors.New("only admin can update role")
+			return user, errAdminRequired("update role")
 		} else if user.Role == models.UserRoleAdmin && user.UUID == userToUpdate.UUID {
-			return user, http.StatusForbidden, errors.New("admin cannot change their own role")
+			return user, apperrors.Forbidden("own_role", "admin cannot change their own role")
 		}
 		userToUpdate.Role = *userUpdate.Role
 	}
 
-	return userToUpdate, http.StatusOK, s.userRepo.UserUpdate(userToUpdate)
+	return userToUpdate, s.userRepo.UserUpdate(ctx, userToUpdate)
+}
+
+// UserPreferencesUpdate merges the given unit preferences into the stored ones; an empty unit
+// removes the preference for that measurement type.
+func (s *userService) UserPreferencesUpdate(ctx context.Context, userUUID uuid.UUID, input models.UserPreferencesRequest) (*models.User, error) {
+	user, err := s.userRepo.UserFindByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	preferredUnits := models.UnitPreferences{}
+	for quantity, unit := range user.PreferredUnits {
+		preferredUnits[quantity] = unit
+	}
+
+	for quantity, unit := range input.Units {
+		if unit == "" {
+			delete(preferredUnits, quantity)
+			continue
+		}
+
+		unitQuantity, ok := utils.UnitQuantity(unit)
+		if !ok {
+			return nil, apperrors.Validation("unknown_unit", "unknown unit {0}", unit)
+		}
+		if unitQuantity != quantity {
+			return nil, apperrors.Validation("unit_mismatch", "{0} is not a unit of {1}", unit, quantity)
+		}
+		preferredUnits[quantity] = utils.NormalizeUnit(unit)
+	}
+	user.PreferredUnits = preferredUnits
+
+	if input.Timezone != nil {
+		if err := user.SetTimezone(*input.Timezone); err != nil {
+			return nil, apperrors.Validation("unknown_timezone", "unknown timezone {0}", *input.Timezone)
+		}
+	}
+
+	if err := s.userRepo.UserUpdate(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *userService) UserCreate(ctx context.Context, input models.UserCreateRequest) (*models.User, error) {
+	if _, err := s.userRepo.UserFindByUsername(ctx, input.Username); err == nil {
+		return nil, ErrUsernameTaken
+	}
+
+	user := &models.User{
+		UUID:     uuid.New(),
+		Username: input.Username,
+		Password: input.Password,
+		Role:     input.Role,
+	}
+	if err := s.userRepo.UserCreate(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *userService) UserList(ctx context.Context) ([]models.User, error) {
+	users, err := s.userRepo.UserFindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (s *userService) UserDetail(ctx context.Context, username string) (*models.User, error) {
+	user, err := s.userRepo.UserFindByUsername(ctx, username)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (s *userService) UserResetPassword(ctx context.Context, username string, password string) (*models.User, error) {
+	user, err := s.userRepo.UserFindByUsername(ctx, username)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	user.Password = password
+	if err := user.HashPassword(); err != nil {
+		return nil, errors.New("failed to update")
+	}
+	if err := s.userRepo.UserUpdate(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *userService) UserSetRole(ctx context.Context, username string, role models.UserRole) (*models.User, error) {
+	user, err := s.userRepo.UserFindByUsername(ctx, username)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	user.Role = role
+	if err := s.userRepo.UserUpdate(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *userService) UserIssueToken(ctx context.Context, username string, expiration time.Duration) (*models.User, string, error) {
+	user, err := s.userRepo.UserFindByUsername(ctx, username)
+	if err != nil {
+		return nil, "", ErrUserNotFound
+	}
+
+	if expiration == 0 {
+		expiration = s.signer.Expiration()
+	}
+	token, err := s.signer.GenerateWithExpiration(user.UUID, expiration)
+	if err != nil {
+		return nil, "", err
+	}
+	return user, token, nil
 }
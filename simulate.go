@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"home-monitor-backend/models"
+	"home-monitor-backend/services"
+	"home-monitor-backend/simulator"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// runSimulate implements the simulate command, which exercises a running server with virtual
+// devices. Devices are registered for -owner, or existing ones are reused from a token file.
+func runSimulate(ctx context.Context, userService services.UserService, deviceTypeService services.DeviceTypeService, deviceService services.DeviceService, args []string) error {
+	flags := flag.NewFlagSet("simulate", flag.ExitOnError)
+	urlFlag := flags.String("url", "http://localhost:8080", "base URL of the server")
+	ownerFlag := flags.String("owner", "", "username to register the virtual devices for")
+	devicesFlag := flags.Int("devices", 10, "number of devices to register")
+	typeFlag := flags.String("type", "", "device type, by UUID or name; its metrics are sent")
+	tokensFlag := flags.String("tokens", "", "file with one device token per line, instead of registering devices")
+	saveTokensFlag := flags.String("save-tokens", "", "write the tokens of the registered devices to this file")
+
+	intervalFlag := flags.Duration("interval", time.Second, "time between requests of a device")
+	batchFlag := flags.Int("batch", 1, "samples per request, spread over the interval")
+	jitterFlag := flags.Float64("jitter", 0.1, "random shift of each request, as a fraction of the interval")
+	dropoutFlag := flags.Float64("dropout", 0, "chance per request that the device goes offline")
+	dropoutLengthFlag := flags.Duration("dropout-length", 30*time.Second, "longest time a device stays offline")
+
+	pollFlag := flags.Duration("poll", 5*time.Second, "how often devices fetch commands, 0 to ignore commands")
+	ackDelayFlag := flags.Duration("ack-delay", 200*time.Millisecond, "time a device takes to execute a command")
+	ackFailureFlag := flags.Float64("ack-failure", 0, "chance a command is reported as failed")
+	ackIgnoreFlag := flags.Float64("ack-ignore", 0, "chance a command is never acknowledged")
+
+	durationFlag := flags.Duration("duration", time.Minute, "how long to run, 0 to run until interrupted")
+	reportFlag := flags.Duration("report", 10*time.Second, "progress report interval, 0 to disable")
+	timeoutFlag := flags.Duration("timeout", 10*time.Second, "HTTP request timeout")
+	flags.Parse(args)
+
+	if *intervalFlag <= 0 || *batchFlag < 1 {
+		return errors.New("-interval and -batch must be positive")
+	}
+	for name, p := range map[string]float64{"jitter": *jitterFlag, "dropout": *dropoutFlag, "ack-failure": *ackFailureFlag, "ack-ignore": *ackIgnoreFlag} {
+		if p < 0 || p > 1 {
+			return fmt.Errorf("-%s must be between 0 and 1", name)
+		}
+	}
+
+	var metrics []models.MetricSchema
+	var deviceType *models.DeviceType
+	if *typeFlag != "" {
+		var err error
+		deviceType, err = findDeviceType(ctx, deviceTypeService, *typeFlag)
+		if err != nil {
+			return err
+		}
+		metrics = deviceType.Capabilities.Metrics
+	}
+	if *batchFlag*max(len(metrics), 1) > models.ReadingIngestMaxBatch {
+		return fmt.Errorf("-batch times the number of metrics must not exceed %d readings", models.ReadingIngestMaxBatch)
+	}
+
+	var tokens []string
+	var err error
+	switch {
+	case *tokensFlag != "":
+		tokens, err = readTokens(*tokensFlag)
+	case *ownerFlag != "":
+		tokens, err = registerDevices(ctx, userService, deviceService, *ownerFlag, *devicesFlag, deviceType)
+		if err == nil && *saveTokensFlag != "" {
+			err = os.WriteFile(*saveTokensFlag, []byte(strings.Join(tokens, "\n")+"\n"), 0o600)
+		}
+	default:
+		flags.Usage()
+		return errors.New("-owner or -tokens is required")
+	}
+	if err != nil {
+		return err
+	}
+
+	devices := make([]simulator.Device, len(tokens))
+	for i, token := range tokens {
+		devices[i] = simulator.Device{Token: token, Metrics: metrics}
+	}
+
+	sim := simulator.New(simulator.Config{
+		URL:           *urlFlag,
+		Interval:      *intervalFlag,
+		Batch:         *batchFlag,
+		Jitter:        *jitterFlag,
+		Dropout:       *dropoutFlag,
+		DropoutLength: *dropoutLengthFlag,
+		Poll:          *pollFlag,
+		AckDelay:      *ackDelayFlag,
+		AckFailure:    *ackFailureFlag,
+		AckIgnore:     *ackIgnoreFlag,
+		Timeout:       *timeoutFlag,
+	})
+
+	if *durationFlag > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *durationFlag)
+		defer cancel()
+	}
+	if *reportFlag > 0 {
+		go func() {
+			ticker := time.NewTicker(*reportFlag)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					printProgress(sim.Stats())
+				}
+			}
+		}()
+	}
+
+	fmt.Printf("Simulating %d devices against %s\n", len(devices), *urlFlag)
+	printSummary(sim.Run(ctx, devices))
+	return nil
+}
+
+// registerDevices creates count devices for the owner and returns their tokens.
+func registerDevices(ctx context.Context, userService services.UserService, deviceService services.DeviceService, username string, count int, deviceType *models.DeviceType) ([]string, error) {
+	if count < 1 {
+		return nil, errors.New("-devices must be at least 1")
+	}
+	owner, err := userService.UserDetail(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	input := models.DeviceCreateRequest{Home: "Simulator"}
+	if deviceType != nil {
+		input.DeviceTypeUUID = &deviceType.UUID
+	}
+
+	run := time.Now().Format("20060102-150405")
+	tokens := make([]string, count)
+	for i := range tokens {
+		input.Name = fmt.Sprintf("sim-%s-%d", run, i+1)
+		_, token, err := deviceService.DeviceCreate(ctx, input, owner.UUID)
+		if err != nil {
+			return nil, err
+		}
+		tokens[i] = token
+	}
+
+	fmt.Printf("Registered %d devices for %s in home Simulator\n", count, owner.Username)
+	return tokens, nil
+}
+
+func readTokens(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var tokens []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		tokens = append(tokens, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("no tokens in %s", path)
+	}
+	return tokens, nil
+}
+
+func printProgress(s simulator.Snapshot) {
+	fmt.Printf("%6s  requests %d (%.1f/s)  readings %d (%.1f/s)  errors %.2f%%  p95 %s\n",
+		s.Elapsed.Round(time.Second), s.Requests, s.RequestsPerSecond(), s.Accepted, s.ReadingsPerSecond(),
+		s.ErrorRate()*100, s.P95.Round(time.Microsecond))
+}
+
+func printSummary(s simulator.Snapshot) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Duration\t%s\n", s.Elapsed.Round(time.Millisecond))
+	fmt.Fprintf(w, "Requests\t%d (%.1f/s)\n", s.Requests, s.RequestsPerSecond())
+	fmt.Fprintf(w, "Errors\t%d (%.2f%%)\n", s.Errors, s.ErrorRate()*100)
+	for _, status := range slices.Sorted(maps.Keys(s.Statuses)) {
+		label := fmt.Sprint(status)
+		if status == 0 {
+			label = "no response"
+		}
+		fmt.Fprintf(w, "  %s\t%d\n", label, s.Statuses[status])
+	}
+	fmt.Fprintf(w, "Latency\tp50 %s, p95 %s, p99 %s\n", s.P50.Round(time.Microsecond), s.P95.Round(time.Microsecond), s.P99.Round(time.Microsecond))
+	fmt.Fprintf(w, "Readings accepted\t%d (%.1f/s), %d flagged\n", s.Accepted, s.ReadingsPerSecond(), s.Flagged)
+	fmt.Fprintf(w, "Readings rejected\t%d\n", s.Rejected)
+	fmt.Fprintf(w, "Readings dropped\t%d in %d dropouts\n", s.Dropped, s.Dropouts)
+	fmt.Fprintf(w, "Commands\t%d received, %d acked, %d failed, %d ignored\n", s.Commands, s.Acked, s.Failed, s.Ignored)
+	w.Flush()
+}
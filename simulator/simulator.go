@@ -0,0 +1,318 @@
+package simulator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"home-monitor-backend/models"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Config controls how the virtual devices behave. Probabilities are between 0 and 1.
+type Config struct {
+	URL      string
+	Interval time.Duration
+	// Batch is the number of samples per request, taken evenly over the interval.
+	Batch int
+	// Jitter shifts every send by up to this fraction of the interval.
+	Jitter float64
+	// Dropout is the chance per send that the device goes offline for up to DropoutLength.
+	// Samples taken while offline are lost.
+	Dropout       float64
+	DropoutLength time.Duration
+
+	// Poll is how often devices fetch their commands; zero disables command handling.
+	Poll       time.Duration
+	AckDelay   time.Duration
+	AckFailure float64
+	// AckIgnore is the chance a command is never acknowledged and expires on the server.
+	AckIgnore float64
+
+	Timeout time.Duration
+}
+
+// Device is a registered device the simulator authenticates as. Metrics are the readings it
+// sends; without metrics it sends a single numeric "value".
+type Device struct {
+	Token   string
+	Metrics []models.MetricSchema
+}
+
+// Simulator drives virtual devices against a running server over its HTTP device API.
+type Simulator struct {
+	config Config
+	client *http.Client
+	stats  *stats
+}
+
+func New(config Config) *Simulator {
+	return &Simulator{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		stats:  newStats(),
+	}
+}
+
+// Stats returns a snapshot of the counters so far.
+func (s *Simulator) Stats() Snapshot {
+	return s.stats.snapshot()
+}
+
+// Run simulates the devices until the context is cancelled and returns the final counters.
+func (s *Simulator) Run(ctx context.Context, devices []Device) Snapshot {
+	var wg sync.WaitGroup
+	for i, device := range devices {
+		rng := rand.New(rand.NewPCG(uint64(time.Now().UnixNano()), uint64(i)))
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.sendReadings(ctx, device, rng)
+		}()
+
+		if s.config.Poll > 0 {
+			ackRng := rand.New(rand.NewPCG(uint64(time.Now().UnixNano()), uint64(i)+1<<32))
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.handleCommands(ctx, device, ackRng)
+			}()
+		}
+	}
+	wg.Wait()
+	return s.stats.snapshot()
+}
+
+func (s *Simulator) sendReadings(ctx context.Context, device Device, rng *rand.Rand) {
+	metrics := device.Metrics
+	if len(metrics) == 0 {
+		metrics = []models.MetricSchema{{Name: "value", DataType: models.DataTypeNumber}}
+	}
+	values := newWalk(metrics, rng)
+
+	// Devices start spread over the first interval instead of all at once.
+	if !sleep(ctx, time.Duration(rng.Float64()*float64(s.config.Interval))) {
+		return
+	}
+
+	var offlineUntil time.Time
+	for {
+		start := time.Now()
+		if start.Before(offlineUntil) {
+			s.stats.dropped.Add(int64(s.config.Batch * len(metrics)))
+		} else if s.config.Dropout > 0 && rng.Float64() < s.config.Dropout {
+			offlineUntil = start.Add(time.Duration(rng.Float64() * float64(s.config.DropoutLength)))
+			s.stats.dropouts.Add(1)
+			s.stats.dropped.Add(int64(s.config.Batch * len(metrics)))
+		} else {
+			s.send(ctx, device, values.batch(start, s.config.Interval, s.config.Batch))
+		}
+
+		wait := s.config.Interval - time.Since(start)
+		if s.config.Jitter > 0 {
+			wait += time.Duration((rng.Float64()*2 - 1) * s.config.Jitter * float64(s.config.Interval))
+		}
+		if !sleep(ctx, wait) {
+			return
+		}
+	}
+}
+
+func (s *Simulator) send(ctx context.Context, device Device, readings []models.ReadingInput) {
+	var response models.ReadingIngestResponse
+	status, latency, err := s.request(ctx, device, http.MethodPost, "/api/device/readings", models.ReadingIngestRequest{Readings: readings}, &response)
+	if ctx.Err() != nil {
+		return
+	}
+	s.stats.record(status, latency, err)
+	if err == nil && status == http.StatusOK {
+		s.stats.accepted.Add(int64(response.Accepted))
+		s.stats.flagged.Add(int64(response.Flagged))
+		s.stats.rejected.Add(int64(len(response.Rejected)))
+	} else {
+		s.stats.rejected.Add(int64(len(readings)))
+	}
+}
+
+func (s *Simulator) handleCommands(ctx context.Context, device Device, rng *rand.Rand) {
+	// The server hands out unacknowledged commands again, so each one is only handled once.
+	handled := map[uuid.UUID]bool{}
+	for sleep(ctx, s.config.Poll) {
+		var commands []models.CommandResponse
+		status, latency, err := s.request(ctx, device, http.MethodGet, "/api/device/commands", nil, &commands)
+		if ctx.Err() != nil {
+			return
+		}
+		s.stats.record(status, latency, err)
+		if err != nil || status != http.StatusOK {
+			continue
+		}
+
+		for _, command := range commands {
+			if handled[command.UUID] {
+				continue
+			}
+			handled[command.UUID] = true
+			s.stats.commands.Add(1)
+			if rng.Float64() < s.config.AckIgnore {
+				s.stats.ignored.Add(1)
+				continue
+			}
+			if !sleep(ctx, s.config.AckDelay) {
+				return
+			}
+
+			ack := models.CommandAckRequest{Status: models.CommandStatusAcked, Result: json.RawMessage(`{"simulated":true}`)}
+			if rng.Float64() < s.config.AckFailure {
+				ack = models.CommandAckRequest{Status: models.CommandStatusFailed, Error: "simulated failure"}
+			}
+			status, latency, err := s.request(ctx, device, http.MethodPost, "/api/device/commands/"+command.UUID.String()+"/ack", ack, nil)
+			if ctx.Err() != nil {
+				return
+			}
+			s.stats.record(status, latency, err)
+			if err == nil && status == http.StatusOK {
+				if ack.Status == models.CommandStatusAcked {
+					s.stats.acked.Add(1)
+				} else {
+					s.stats.failed.Add(1)
+				}
+			}
+		}
+	}
+}
+
+func (s *Simulator) request(ctx context.Context, device Device, method string, path string, body any, out any) (int, time.Duration, error) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return 0, 0, err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(s.config.URL, "/")+path, reader)
+	if err != nil {
+		return 0, 0, err
+	}
+	req.Header.Set("X-Device-Token", device.Token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, time.Since(start), err
+	}
+	defer resp.Body.Close()
+
+	if out != nil && resp.StatusCode == http.StatusOK {
+		err = json.NewDecoder(resp.Body).Decode(out)
+	} else {
+		_, err = io.Copy(io.Discard, resp.Body)
+	}
+	latency := time.Since(start)
+	if err != nil {
+		return resp.StatusCode, latency, fmt.Errorf("reading response: %w", err)
+	}
+	return resp.StatusCode, latency, nil
+}
+
+// walk produces plausible values: numbers wander within the declared range and booleans
+// change now and then.
+type walk struct {
+	metrics []models.MetricSchema
+	values  []float64
+	rng     *rand.Rand
+}
+
+func newWalk(metrics []models.MetricSchema, rng *rand.Rand) *walk {
+	w := &walk{metrics: metrics, values: make([]float64, len(metrics)), rng: rng}
+	for i, metric := range metrics {
+		if metric.DataType == models.DataTypeBoolean {
+			w.values[i] = float64(rng.IntN(2))
+			continue
+		}
+		low, high := bounds(metric)
+		w.values[i] = low + (high-low)*(0.25+0.5*rng.Float64())
+	}
+	return w
+}
+
+func bounds(metric models.MetricSchema) (float64, float64) {
+	low, high := 0.0, 100.0
+	if metric.Min != nil {
+		low = *metric.Min
+		if metric.Max == nil {
+			high = low + 100
+		}
+	}
+	if metric.Max != nil {
+		high = *metric.Max
+		if metric.Min == nil {
+			low = high - 100
+		}
+	}
+	return low, high
+}
+
+func (w *walk) batch(end time.Time, interval time.Duration, samples int) []models.ReadingInput {
+	readings := make([]models.ReadingInput, 0, samples*len(w.metrics))
+	step := interval / time.Duration(samples)
+	for sample := samples - 1; sample >= 0; sample-- {
+		recordedAt := end.Add(-time.Duration(sample) * step).UTC()
+		for i, metric := range w.metrics {
+			readings = append(readings, models.ReadingInput{Metric: metric.Name, Value: w.next(i, metric), RecordedAt: &recordedAt})
+		}
+	}
+	return readings
+}
+
+func (w *walk) next(i int, metric models.MetricSchema) any {
+	if metric.DataType == models.DataTypeBoolean {
+		if w.rng.Float64() < 0.05 {
+			w.values[i] = 1 - w.values[i]
+		}
+		return w.values[i] >= 0.5
+	}
+
+	low, high := bounds(metric)
+	value := w.values[i] + w.rng.NormFloat64()*(high-low)*0.01
+	value = math.Max(low, math.Min(high, value))
+	w.values[i] = value
+
+	if metric.DataType == models.DataTypeInteger {
+		return math.Round(value)
+	}
+	if metric.Precision != nil {
+		scale := math.Pow(10, float64(*metric.Precision))
+		return math.Round(value*scale) / scale
+	}
+	return value
+}
+
+// sleep waits for d and reports false when the context was cancelled first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,131 @@
+package simulator
+
+import (
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type stats struct {
+	start time.Time
+
+	accepted atomic.Int64
+	flagged  atomic.Int64
+	rejected atomic.Int64
+	dropped  atomic.Int64
+	dropouts atomic.Int64
+
+	commands atomic.Int64
+	acked    atomic.Int64
+	failed   atomic.Int64
+	ignored  atomic.Int64
+
+	mu        sync.Mutex
+	requests  int64
+	errors    int64
+	statuses  map[int]int64
+	latencies []time.Duration
+}
+
+// Snapshot holds the counters at one point in time. Requests counts every HTTP request, Errors
+// those that failed or did not return 200, with the failures without a response under status 0.
+type Snapshot struct {
+	Elapsed  time.Duration
+	Requests int64
+	Errors   int64
+	Statuses map[int]int64
+
+	Accepted int64
+	Flagged  int64
+	Rejected int64
+	Dropped  int64
+	Dropouts int64
+
+	Commands int64
+	Acked    int64
+	Failed   int64
+	Ignored  int64
+
+	P50 time.Duration
+	P95 time.Duration
+	P99 time.Duration
+}
+
+func newStats() *stats {
+	return &stats{start: time.Now(), statuses: map[int]int64{}}
+}
+
+func (s *stats) record(status int, latency time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests++
+	if err != nil || status != http.StatusOK {
+		s.errors++
+	}
+	s.statuses[status]++
+	s.latencies = append(s.latencies, latency)
+}
+
+func (s *stats) snapshot() Snapshot {
+	s.mu.Lock()
+	snapshot := Snapshot{
+		Elapsed:  time.Since(s.start),
+		Requests: s.requests,
+		Errors:   s.errors,
+		Statuses: make(map[int]int64, len(s.statuses)),
+	}
+	for status, count := range s.statuses {
+		snapshot.Statuses[status] = count
+	}
+	latencies := slices.Clone(s.latencies)
+	s.mu.Unlock()
+
+	slices.Sort(latencies)
+	snapshot.P50 = percentile(latencies, 0.50)
+	snapshot.P95 = percentile(latencies, 0.95)
+	snapshot.P99 = percentile(latencies, 0.99)
+
+	snapshot.Accepted = s.accepted.Load()
+	snapshot.Flagged = s.flagged.Load()
+	snapshot.Rejected = s.rejected.Load()
+	snapshot.Dropped = s.dropped.Load()
+	snapshot.Dropouts = s.dropouts.Load()
+	snapshot.Commands = s.commands.Load()
+	snapshot.Acked = s.acked.Load()
+	snapshot.Failed = s.failed.Load()
+	snapshot.Ignored = s.ignored.Load()
+	return snapshot
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[int(float64(len(sorted)-1)*p)]
+}
+
+// ErrorRate is the share of requests that failed.
+func (s Snapshot) ErrorRate() float64 {
+	if s.Requests == 0 {
+		return 0
+	}
+	return float64(s.Errors) / float64(s.Requests)
+}
+
+// ReadingsPerSecond is the rate of readings the server accepted, flagged ones included.
+func (s Snapshot) ReadingsPerSecond() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Accepted) / s.Elapsed.Seconds()
+}
+
+func (s Snapshot) RequestsPerSecond() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Requests) / s.Elapsed.Seconds()
+}
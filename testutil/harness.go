@@ -0,0 +1,178 @@
+// Package testutil boots the API against a throwaway database for end-to-end tests.
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"home-monitor-backend/config"
+	"home-monitor-backend/controllers"
+	"home-monitor-backend/database"
+	"home-monitor-backend/logging"
+	"home-monitor-backend/middlewares"
+	"home-monitor-backend/models"
+	"home-monitor-backend/ratelimit"
+	"home-monitor-backend/repositories"
+	"home-monitor-backend/routes"
+	"home-monitor-backend/services"
+	"home-monitor-backend/utils"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const JWTSecret = "test-secret"
+
+var setupOnce sync.Once
+
+// Harness serves the API routes from a migrated SQLite database of its own. The database is a
+// file in the test's temporary directory rather than :memory:, because the migrations run on a
+// connection of their own and an in-memory database exists only for the connection that opened it.
+// Harnesses share no state, so tests using them can run in parallel.
+type Harness struct {
+	t      testing.TB
+	DB     *gorm.DB
+	Signer *utils.JWTSigner
+	Router *gin.Engine
+}
+
+func New(t testing.TB) *Harness {
+	t.Helper()
+
+	setupOnce.Do(func() {
+		gin.SetMode(gin.TestMode)
+		logging.Setup("error")
+		if err := utils.SetupValidator(); err != nil {
+			panic(err)
+		}
+	})
+
+	cfg := config.Database{
+		Driver:       config.DriverSQLite,
+		Path:         filepath.Join(t.TempDir(), "test.db"),
+		QueryTimeout: 30 * time.Second,
+	}
+
+	m, err := database.Migrator(cfg)
+	if err != nil {
+		t.Fatalf("migrations: %v", err)
+	}
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		t.Fatalf("migrations: %v", err)
+	}
+	m.Close()
+
+	db, err := database.ConnectDB(cfg)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("database handle: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	signer := utils.NewJWTSigner(JWTSecret, time.Hour)
+	userService := services.NewUserService(repositories.NewUserRepository(db), signer)
+
+	r := gin.New()
+	r.Use(middlewares.RequestID(), middlewares.Recovery(), middlewares.Errors())
+	routes.RootRoute(r)
+	routes.UserRoutes(r, controllers.NewUserController(userService), signer, &ratelimit.Limiter{})
+
+	return &Harness{t: t, DB: db, Signer: signer, Router: r}
+}
+
+// CreateUser stores a user directly in the database, bypassing the API.
+func (h *Harness) CreateUser(username string, password string, role models.UserRole) *models.User {
+	h.t.Helper()
+
+	user := &models.User{Username: username, Password: password, Role: role}
+	if err := h.DB.Create(user).Error; err != nil {
+		h.t.Fatalf("create user %s: %v", username, err)
+	}
+	return user
+}
+
+// CreateDeviceType stores a device type without capabilities directly in the database.
+func (h *Harness) CreateDeviceType(name string) *models.DeviceType {
+	h.t.Helper()
+
+	deviceType := &models.DeviceType{Name: name}
+	if err := h.DB.Create(deviceType).Error; err != nil {
+		h.t.Fatalf("create device type %s: %v", name, err)
+	}
+	return deviceType
+}
+
+// CreateDevice stores a device directly in the database, bypassing the API. A device without a
+// token hash gets a unique one.
+func (h *Harness) CreateDevice(device models.Device) *models.Device {
+	h.t.Helper()
+
+	if device.TokenHash == "" {
+		device.TokenHash = uuid.NewString()
+	}
+	if err := h.DB.Create(&device).Error; err != nil {
+		h.t.Fatalf("create device %s: %v", device.Name, err)
+	}
+	return &device
+}
+
+// Login logs in through the API and returns the value of the Authorization header.
+func (h *Harness) Login(username string, password string) string {
+	h.t.Helper()
+
+	w := h.Do(http.MethodPost, "/api/user/login", "", models.UserLoginRequest{Username: username, Password: password})
+	if w.Code != http.StatusOK {
+		h.t.Fatalf("login %s: status %d: %s", username, w.Code, w.Body)
+	}
+	return Decode[models.UserLoginResponse](h.t, w).Token
+}
+
+// Do sends a request to the router. The body is encoded as JSON unless it is nil, and
+// authorization is sent as the Authorization header unless it is empty.
+func (h *Harness) Do(method string, path string, authorization string, body any) *httptest.ResponseRecorder {
+	h.t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			h.t.Fatalf("encode request body: %v", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	w := httptest.NewRecorder()
+	h.Router.ServeHTTP(w, req)
+	return w
+}
+
+// Decode decodes a JSON response body.
+func Decode[T any](t testing.TB, w *httptest.ResponseRecorder) T {
+	t.Helper()
+
+	var v T
+	if err := json.Unmarshal(w.Body.Bytes(), &v); err != nil {
+		t.Fatalf("decode response %q: %v", w.Body, err)
+	}
+	return v
+}
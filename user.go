@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"home-monitor-backend/models"
+	"home-monitor-backend/services"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+// runUser implements the user command, which manages accounts with administrator rights. It is
+// how the first administrator is created on a fresh database.
+func runUser(ctx context.Context, userService services.UserService, args []string) error {
+	action, args, err := subcommand("user", "create|list|reset-password|set-role", args)
+	if err != nil {
+		return err
+	}
+
+	flags := flag.NewFlagSet("user "+action, flag.ExitOnError)
+	switch action {
+	case "create":
+		usernameFlag := flags.String("username", "", "name of the new user")
+		roleFlag := flags.String("role", "user", "admin or user")
+		stdinFlag := flags.Bool("password-stdin", false, "read the password from stdin instead of generating one")
+		flags.Parse(args)
+
+		role, err := models.ParseUserRole(*roleFlag)
+		if err != nil {
+			return err
+		}
+		password, generated, err := readPassword(*stdinFlag)
+		if err != nil {
+			return err
+		}
+
+		input := models.UserCreateRequest{Username: *usernameFlag, Password: password, Role: role}
+		if err := binding.Validator.ValidateStruct(&input); err != nil {
+			return fmt.Errorf("invalid user: %w", err)
+		}
+		user, err := userService.UserCreate(ctx, input)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Created %s %s (%s)\n", user.Role, user.Username, user.UUID)
+		if generated {
+			fmt.Printf("Password: %s\n", password)
+		}
+		return nil
+
+	case "list":
+		flags.Parse(args)
+
+		users, err := userService.UserList(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "UUID\tUSERNAME\tROLE\tCREATED")
+		for _, user := range users {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", user.UUID, user.Username, user.Role, user.CreatedAt.Format(time.RFC3339))
+		}
+		return w.Flush()
+
+	case "reset-password":
+		usernameFlag := flags.String("username", "", "user whose password is replaced")
+		stdinFlag := flags.Bool("password-stdin", false, "read the password from stdin instead of generating one")
+		flags.Parse(args)
+
+		if *usernameFlag == "" {
+			flags.Usage()
+			return errors.New("-username is required")
+		}
+		password, generated, err := readPassword(*stdinFlag)
+		if err != nil {
+			return err
+		}
+		user, err := userService.UserResetPassword(ctx, *usernameFlag, password)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Password of %s reset\n", user.Username)
+		if generated {
+			fmt.Printf("Password: %s\n", password)
+		}
+		return nil
+
+	case "set-role":
+		usernameFlag := flags.String("username", "", "user whose role is changed")
+		roleFlag := flags.String("role", "", "admin or user")
+		flags.Parse(args)
+
+		if *usernameFlag == "" {
+			flags.Usage()
+			return errors.New("-username is required")
+		}
+		role, err := models.ParseUserRole(*roleFlag)
+		if err != nil {
+			return err
+		}
+		user, err := userService.UserSetRole(ctx, *usernameFlag, role)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%s is now %s\n", user.Username, user.Role)
+		return nil
+	}
+	return fmt.Errorf("unknown user action %q, expected create, list, reset-password or set-role", action)
+}
+
+// runToken implements the token command, which issues API tokens without a login, e.g. for
+// scripts. The token is printed alone so that it can be captured.
+func runToken(ctx context.Context, userService services.UserService, args []string) error {
+	action, args, err := subcommand("token", "issue", args)
+	if err != nil {
+		return err
+	}
+	if action != "issue" {
+		return fmt.Errorf("unknown token action %q, expected issue", action)
+	}
+
+	flags := flag.NewFlagSet("token issue", flag.ExitOnError)
+	usernameFlag := flags.String("username", "", "user the token authenticates")
+	expiresFlag := flags.Duration("expires", 0, "token lifetime (default JWT_EXPIRATION)")
+	flags.Parse(args)
+
+	if *usernameFlag == "" {
+		flags.Usage()
+		return errors.New("-username is required")
+	}
+	if *expiresFlag < 0 {
+		return errors.New("-expires must not be negative")
+	}
+
+	_, token, err := userService.UserIssueToken(ctx, *usernameFlag, *expiresFlag)
+	if err != nil {
+		return err
+	}
+	fmt.Println(token)
+	return nil
+}
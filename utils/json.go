@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+)
+
+// MergePatch applies an RFC 7386 JSON merge patch to target. Both documents must be JSON objects;
+// an empty target is treated as an empty object.
+func MergePatch(target []byte, patch []byte) ([]byte, error) {
+	targetMap, err := jsonObject(target)
+	if err != nil {
+		return nil, err
+	}
+
+	patchMap, err := jsonObject(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(mergePatch(targetMap, patchMap))
+}
+
+// JSONDelta returns the members of desired that are missing from or differ in reported,
+// descending into nested objects.
+func JSONDelta(desired []byte, reported []byte) ([]byte, error) {
+	desiredMap, err := jsonObject(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	reportedMap, err := jsonObject(reported)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(jsonDelta(desiredMap, reportedMap))
+}
+
+func jsonObject(data []byte) (map[string]any, error) {
+	object := make(map[string]any)
+	if len(data) == 0 {
+		return object, nil
+	}
+
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return object, nil
+	case map[string]any:
+		return v, nil
+	default:
+		return nil, errors.New("JSON value must be an object")
+	}
+}
+
+func mergePatch(target any, patch any) any {
+	patchMap, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetMap, ok := target.(map[string]any)
+	if !ok {
+		targetMap = make(map[string]any)
+	}
+
+	for key, value := range patchMap {
+		if value == nil {
+			delete(targetMap, key)
+			continue
+		}
+		targetMap[key] = mergePatch(targetMap[key], value)
+	}
+	return targetMap
+}
+
+func jsonDelta(desired map[string]any, reported map[string]any) map[string]any {
+	delta := make(map[string]any)
+	for key, desiredValue := range desired {
+		reportedValue, exists := reported[key]
+
+		if desiredMap, ok := desiredValue.(map[string]any); ok {
+			reportedMap, _ := reportedValue.(map[string]any)
+			if nested := jsonDelta(desiredMap, reportedMap); len(nested) > 0 {
+				delta[key] = nested
+			}
+			continue
+		}
+
+		if !exists || !reflect.DeepEqual(desiredValue, reportedValue) {
+			delta[key] = desiredValue
+		}
+	}
+	return delta
+}
@@ -0,0 +1,93 @@
+package utils_test
+
+import (
+	"encoding/json"
+	"home-monitor-backend/utils"
+	"reflect"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		target  string
+		patch   string
+		want    string
+		wantErr bool
+	}{
+		{"add a key", `{"a":1}`, `{"b":2}`, `{"a":1,"b":2}`, false},
+		{"replace a key", `{"a":1}`, `{"a":"x"}`, `{"a":"x"}`, false},
+		{"null deletes a key", `{"a":1,"b":2}`, `{"a":null}`, `{"b":2}`, false},
+		{"null of a missing key", `{"a":1}`, `{"b":null}`, `{"a":1}`, false},
+		{"nested merge", `{"led":{"color":"red","on":true},"mode":"auto"}`, `{"led":{"color":"blue","brightness":50}}`, `{"led":{"brightness":50,"color":"blue","on":true},"mode":"auto"}`, false},
+		{"nested null", `{"led":{"color":"red","on":true}}`, `{"led":{"on":null}}`, `{"led":{"color":"red"}}`, false},
+		{"object replaces a value", `{"a":1}`, `{"a":{"b":null,"c":2}}`, `{"a":{"c":2}}`, false},
+		{"arrays are replaced", `{"a":[1,2]}`, `{"a":[3]}`, `{"a":[3]}`, false},
+		{"empty target", ``, `{"a":1}`, `{"a":1}`, false},
+		{"patch is not an object", `{"a":1}`, `[1]`, "", true},
+		{"target is not an object", `"text"`, `{"a":1}`, "", true},
+		{"invalid JSON", `{"a":1}`, `{`, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := utils.MergePatch([]byte(tt.target), []byte(tt.patch))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("MergePatch() = %s, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertJSONEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestJSONDelta(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		desired  string
+		reported string
+		want     string
+	}{
+		{"in sync", `{"a":1,"b":"x"}`, `{"a":1,"b":"x"}`, `{}`},
+		{"differing value", `{"a":1,"b":"x"}`, `{"a":2,"b":"x"}`, `{"a":1}`},
+		{"missing from reported", `{"a":1}`, `{}`, `{"a":1}`},
+		{"only reported", `{}`, `{"a":1}`, `{}`},
+		{"nested difference", `{"led":{"color":"blue","on":true}}`, `{"led":{"color":"red","on":true}}`, `{"led":{"color":"blue"}}`},
+		{"nested in sync", `{"led":{"on":true}}`, `{"led":{"on":true,"extra":1}}`, `{}`},
+		{"reported is not an object there", `{"led":{"on":true}}`, `{"led":"off"}`, `{"led":{"on":true}}`},
+		{"arrays compare whole", `{"a":[1,2]}`, `{"a":[1]}`, `{"a":[1,2]}`},
+		{"empty reported", `{"a":1}`, ``, `{"a":1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := utils.JSONDelta([]byte(tt.desired), []byte(tt.reported))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertJSONEqual(t, got, tt.want)
+		})
+	}
+}
+
+func assertJSONEqual(t *testing.T, got []byte, want string) {
+	t.Helper()
+
+	var gotValue, wantValue any
+	if err := json.Unmarshal(got, &gotValue); err != nil {
+		t.Fatalf("decode %s: %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+		t.Fatalf("decode %s: %v", want, err)
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
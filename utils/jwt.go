@@ -1,22 +1,35 @@
 package utils
 
 import (
-	"os"
-	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
-var JWTSecret = os.Getenv("JWT_SECRET")
+// JWTSigner issues and validates the login tokens of users. It is built from the loaded
+// configuration and handed to the services and middleware that need it.
+type JWTSigner struct {
+	secret     []byte
+	expiration time.Duration
+}
 
-func GenerateJWT(userUUID uuid.UUID) (string, error) {
-	Expiration, err := strconv.Atoi(os.Getenv("JWT_EXPIRATION_HOURS"))
-	if err != nil {
-		return "", err
-	}
-	ExpirationTime := time.Now().Add(time.Duration(Expiration) * time.Minute).Unix()
+func NewJWTSigner(secret string, expiration time.Duration) *JWTSigner {
+	return &JWTSigner{secret: []byte(secret), expiration: expiration}
+}
+
+// Expiration is the configured token lifetime.
+func (s *JWTSigner) Expiration() time.Duration {
+	return s.expiration
+}
+
+func (s *JWTSigner) Generate(userUUID uuid.UUID) (string, error) {
+	return s.GenerateWithExpiration(userUUID, s.expiration)
+}
+
+// GenerateWithExpiration issues a token with a lifetime other than the configured one.
+func (s *JWTSigner) GenerateWithExpiration(userUUID uuid.UUID, expiration time.Duration) (string, error) {
+	ExpirationTime := time.Now().Add(expiration).Unix()
 
 	claim := jwt.MapClaims{
 		"user_uuid": userUUID.String(),
@@ -24,12 +37,12 @@ func GenerateJWT(userUUID uuid.UUID) (string, error) {
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claim)
-	return token.SignedString([]byte(JWTSecret))
+	return token.SignedString(s.secret)
 }
 
-func ValidateJWT(tokenString string) (uuid.UUID, error) {
+func (s *JWTSigner) Validate(tokenString string) (uuid.UUID, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
-		return []byte(JWTSecret), nil
+		return s.secret, nil
 	})
 
 	if err != nil || !token.Valid {
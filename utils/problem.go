@@ -0,0 +1,170 @@
+package utils
+
+import (
+	"home-monitor-backend/apperrors"
+	"home-monitor-backend/models"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// problemTitles are the Indonesian titles of the statuses problem documents are sent with.
+var problemTitles = map[int]string{
+	http.StatusBadRequest:            "Permintaan Tidak Valid",
+	http.StatusUnauthorized:          "Tidak Terautentikasi",
+	http.StatusForbidden:             "Dilarang",
+	http.StatusNotFound:              "Tidak Ditemukan",
+	http.StatusConflict:              "Konflik",
+	http.StatusRequestEntityTooLarge: "Muatan Terlalu Besar",
+	http.StatusTooManyRequests:       "Terlalu Banyak Permintaan",
+	http.StatusInternalServerError:   "Kesalahan Server Internal",
+}
+
+// problemDetails holds the Indonesian message template of every error code. Like the English
+// message, it has a placeholder {i} for each parameter of the error.
+var problemDetails = map[string]string{
+	apperrors.CodeInternal:     "kesalahan server internal",
+	"admin_required":           "hanya admin yang dapat {0}",
+	"api_key_not_found":        "kunci API tidak ditemukan",
+	"api_key_required":         "kunci API wajib diisi",
+	"command_completed":        "perintah sudah {0}",
+	"command_expired":          "perintah sudah kedaluwarsa",
+	"command_not_found":        "perintah tidak ditemukan",
+	"command_not_supported":    "perintah tidak didukung oleh tipe perangkat",
+	"credentials_required":     "perlu mengisi nama pengguna atau kata sandi yang diperbarui",
+	"device_not_found":         "perangkat tidak ditemukan",
+	"device_token_required":    "header X-Device-Token wajib diisi",
+	"device_type_exists":       "tipe perangkat sudah ada",
+	"device_type_not_found":    "tipe perangkat tidak ditemukan",
+	"export_job_not_completed": "tugas ekspor belum selesai",
+	"export_job_not_found":     "tugas ekspor tidak ditemukan",
+	"file_required":            "berkas wajib diisi",
+	"file_too_large":           "berkas terlalu besar",
+	"import_job_completed":     "tugas impor sudah selesai",
+	"import_job_not_found":     "tugas impor tidak ditemukan",
+	"import_job_running":       "tugas impor sedang dijalankan oleh proses lain",
+	"invalid_api_key":          "kunci API tidak valid",
+	"invalid_authorization":    "format otorisasi tidak valid",
+	"invalid_bucket":           "bucket harus berupa durasi minimal 1s",
+	"invalid_capabilities":     "kapabilitas tidak valid: {0}",
+	"invalid_command_status":   "status perintah tidak valid",
+	"invalid_credentials":      "nama pengguna atau kata sandi salah",
+	"invalid_device_token":     "token perangkat tidak valid",
+	"invalid_file":             "berkas tidak valid",
+	"invalid_mapping":          "pemetaan tidak valid: {0}",
+	"invalid_merge_patch":      "merge patch tidak valid: {0}",
+	"invalid_metrics_token":    "token metrik tidak valid",
+	"invalid_params":           "params tidak valid: {0}",
+	"invalid_request":          "permintaan tidak valid",
+	"invalid_retention_scope":  "kebijakan retensi berlaku untuk tipe perangkat atau rumah, tidak keduanya",
+	"invalid_setup_token":      "token penyiapan tidak valid",
+	"invalid_time_range":       "from harus sebelum to",
+	"invalid_token":            "token tidak valid",
+	"invalid_uuid":             "UUID tidak valid {0}",
+	"nothing_to_update":        "perlu mengisi setidaknya satu field yang diperbarui",
+	"origin_not_allowed":       "origin tidak diizinkan",
+	"own_role":                 "admin tidak dapat mengubah perannya sendiri",
+	"rate_limited":             "terlalu banyak permintaan",
+	"route_not_found":          "rute tidak ditemukan",
+	"setup_completed":          "penyiapan sudah selesai",
+	"shadow_modified":          "shadow diubah secara bersamaan",
+	"shadow_version_mismatch":  "versi shadow tidak cocok",
+	"token_required":           "header Authorization wajib diisi",
+	"too_many_buckets":         "terlalu banyak bucket, gunakan bucket yang lebih besar atau rentang yang lebih pendek",
+	"unauthorized":             "tidak terautentikasi",
+	"unit_mismatch":            "{0} bukan satuan {1}",
+	"unknown_timezone":         "zona waktu tidak dikenal {0}",
+	"unknown_unit":             "satuan tidak dikenal {0}",
+	"user_not_found":           "pengguna tidak ditemukan",
+	"username_taken":           "nama pengguna sudah ada",
+	"validation_failed":        "validasi permintaan gagal",
+}
+
+// problemParams translates the parameters that are words rather than values: the actions an admin
+// is needed for, command statuses, quantities and fixed reasons. Other parameters, such as a unit or
+// the text of a parse error, are inserted as they are.
+var problemParams = map[string]string{
+	"create device types":       "membuat tipe perangkat",
+	"manage retention policies": "mengelola kebijakan retensi",
+	"register new users":        "mendaftarkan pengguna baru",
+	"update role":               "mengubah peran",
+
+	string(models.CommandStatusPending): "menunggu",
+	string(models.CommandStatusSent):    "terkirim",
+	string(models.CommandStatusAcked):   "diterima",
+	string(models.CommandStatusFailed):  "gagal",
+	string(models.CommandStatusExpired): "kedaluwarsa",
+
+	QuantityTemperature: "suhu",
+	QuantityPressure:    "tekanan",
+	QuantityEnergy:      "energi",
+	QuantityVolume:      "volume",
+	QuantitySpeed:       "kecepatan",
+
+	"must be a JSON object": "harus berupa objek JSON",
+}
+
+// registerProblemTranslations adds the problem titles, details and parameters to the Indonesian
+// translator. English needs none: the titles and messages are English already.
+func registerProblemTranslations() error {
+	trans, _ := validationTranslator.GetTranslator("id")
+	for status, title := range problemTitles {
+		if err := trans.Add(problemTitleKey(status), title, false); err != nil {
+			return err
+		}
+	}
+	for code, detail := range problemDetails {
+		if err := trans.Add(problemDetailKey(code), detail, false); err != nil {
+			return err
+		}
+	}
+	for param, text := range problemParams {
+		if err := trans.Add(problemParamKey(param), text, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ProblemText returns the title and detail of a problem document in the best match for an
+// Accept-Language header. The title is that of the status and the detail is the template of the
+// error code filled with the error's parameters; either stays in English without a translation.
+func ProblemText(status int, appErr *apperrors.Error, acceptLanguage string) (title string, detail string) {
+	title, detail = http.StatusText(status), appErr.Message
+
+	trans := validationLocale(acceptLanguage)
+	if trans == nil {
+		return title, detail
+	}
+	if text, err := trans.T(problemTitleKey(status)); err == nil {
+		title = text
+	}
+
+	// A template with more placeholders than the error has parameters cannot be filled.
+	if template, ok := problemDetails[appErr.Code]; !ok || strings.Count(template, "{") > len(appErr.Params) {
+		return title, detail
+	}
+	params := make([]string, len(appErr.Params))
+	for i, param := range appErr.Params {
+		params[i] = param
+		if text, err := trans.T(problemParamKey(param)); err == nil {
+			params[i] = text
+		}
+	}
+	if text, err := trans.T(problemDetailKey(appErr.Code), params...); err == nil {
+		detail = text
+	}
+	return title, detail
+}
+
+func problemTitleKey(status int) string {
+	return "problem.title." + strconv.Itoa(status)
+}
+
+func problemDetailKey(code string) string {
+	return "problem.detail." + code
+}
+
+func problemParamKey(param string) string {
+	return "problem.param." + param
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"home-monitor-backend/apperrors"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// apperrorsConstructors are the functions of package apperrors that take a code, by the index of
+// the code argument.
+var apperrorsConstructors = map[string]int{
+	"New":          1,
+	"Validation":   0,
+	"Unauthorized": 0,
+	"Forbidden":    0,
+	"NotFound":     0,
+	"Conflict":     0,
+	"RateLimited":  0,
+	"TooLarge":     0,
+}
+
+// TestProblemDetailsCoverEveryCode finds every error the code base creates and checks that its code
+// has an Indonesian template with as many placeholders as the English message.
+func TestProblemDetailsCoverEveryCode(t *testing.T) {
+	placeholders := map[string]int{apperrors.CodeInternal: 0}
+
+	fset := token.NewFileSet()
+	err := filepath.WalkDir("..", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() && (entry.Name() == "docs" || strings.HasPrefix(entry.Name(), ".")) && path != ".." {
+			return filepath.SkipDir
+		}
+		if entry.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			selector, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			pkg, ok := selector.X.(*ast.Ident)
+			index, constructor := apperrorsConstructors[selector.Sel.Name]
+			if !ok || pkg.Name != "apperrors" || !constructor || len(call.Args) <= index+1 {
+				return true
+			}
+
+			code, ok := stringLiteral(call.Args[index])
+			if !ok {
+				t.Errorf("%s: error code is not a string literal", fset.Position(call.Pos()))
+				return true
+			}
+			message, ok := stringLiteral(call.Args[index+1])
+			if !ok {
+				t.Errorf("%s: message of %s is not a string literal", fset.Position(call.Pos()), code)
+				return true
+			}
+			placeholders[code] = strings.Count(message, "{")
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for code, count := range placeholders {
+		template, ok := problemDetails[code]
+		if !ok {
+			t.Errorf("%s: no id template", code)
+			continue
+		}
+		if got := strings.Count(template, "{"); got != count {
+			t.Errorf("%s: id template has %d placeholders, the English message %d", code, got, count)
+		}
+	}
+	for code := range problemDetails {
+		if _, ok := placeholders[code]; !ok {
+			t.Errorf("%s: id template of an unused code", code)
+		}
+	}
+}
+
+func stringLiteral(expr ast.Expr) (string, bool) {
+	literal, ok := expr.(*ast.BasicLit)
+	if !ok || literal.Kind != token.STRING {
+		return "", false
+	}
+	value, err := strconv.Unquote(literal.Value)
+	return value, err == nil
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// APIKeyPrefix marks API keys so they are recognizable in configuration files and secret scanners.
+const APIKeyPrefix = "hmk_"
+
+// GenerateDeviceToken returns a random device token and the hash that is stored in place of it.
+func GenerateDeviceToken() (string, string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	return token, HashDeviceToken(token), nil
+}
+
+func HashDeviceToken(token string) string {
+	return hashToken(token)
+}
+
+// GenerateAPIKey returns a random API key and the hash that is stored in place of it.
+func GenerateAPIKey() (string, string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	key := APIKeyPrefix + token
+	return key, HashAPIKey(key), nil
+}
+
+func HashAPIKey(key string) string {
+	return hashToken(key)
+}
+
+// GenerateSetupToken returns the one-time token that guards the first-run setup. It lives only in
+// memory, so it is not hashed.
+func GenerateSetupToken() (string, error) {
+	return randomToken()
+}
+
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+const (
+	QuantityTemperature = "temperature"
+	QuantityPressure    = "pressure"
+	QuantityEnergy      = "energy"
+	QuantityVolume      = "volume"
+	QuantitySpeed       = "speed"
+)
+
+// unit converts linearly to the base unit of its quantity: base = value*scale + offset.
+type unit struct {
+	quantity string
+	scale    float64
+	offset   float64
+}
+
+var units = map[string]unit{
+	"K":  {QuantityTemperature, 1, 0},
+	"°C": {QuantityTemperature, 1, 273.15},
+	"°F": {QuantityTemperature, 5.0 / 9.0, 273.15 - 32*5.0/9.0},
+
+	"Pa":   {QuantityPressure, 1, 0},
+	"hPa":  {QuantityPressure, 100, 0},
+	"kPa":  {QuantityPressure, 1000, 0},
+	"mbar": {QuantityPressure, 100, 0},
+	"bar":  {QuantityPressure, 100000, 0},
+	"psi":  {QuantityPressure, 6894.757293168, 0},
+	"inHg": {QuantityPressure, 3386.389, 0},
+	"mmHg": {QuantityPressure, 133.322387415, 0},
+
+	"J":    {QuantityEnergy, 1, 0},
+	"kJ":   {QuantityEnergy, 1e3, 0},
+	"MJ":   {QuantityEnergy, 1e6, 0},
+	"Wh":   {QuantityEnergy, 3600, 0},
+	"kWh":  {QuantityEnergy, 3.6e6, 0},
+	"MWh":  {QuantityEnergy, 3.6e9, 0},
+	"BTU":  {QuantityEnergy, 1055.05585262, 0},
+	"kcal": {QuantityEnergy, 4184, 0},
+
+	"m³":  {QuantityVolume, 1, 0},
+	"L":   {QuantityVolume, 1e-3, 0},
+	"mL":  {QuantityVolume, 1e-6, 0},
+	"gal": {QuantityVolume, 3.785411784e-3, 0},
+	"ft³": {QuantityVolume, 0.028316846592, 0},
+
+	"m/s":  {QuantitySpeed, 1, 0},
+	"km/h": {QuantitySpeed, 1 / 3.6, 0},
+	"mph":  {QuantitySpeed, 0.44704, 0},
+	"kn":   {QuantitySpeed, 1852.0 / 3600.0, 0},
+	"ft/s": {QuantitySpeed, 0.3048, 0},
+}
+
+var unitAliases = map[string]string{
+	"c": "°C", "degc": "°C", "celsius": "°C",
+	"f": "°F", "degf": "°F", "fahrenheit": "°F",
+	"kelvin": "K",
+	"m3":     "m³", "ft3": "ft³", "l": "L", "ml": "mL", "liter": "L", "litre": "L",
+	"kmh": "km/h", "kph": "km/h", "mps": "m/s", "knot": "kn", "knots": "kn", "kt": "kn", "fps": "ft/s",
+	"btu": "BTU", "kwh": "kWh", "wh": "Wh", "mwh": "MWh",
+	"hpa": "hPa", "kpa": "kPa", "pa": "Pa", "inhg": "inHg", "mmhg": "mmHg",
+}
+
+// NormalizeUnit returns the canonical symbol for a unit or one of its common spellings,
+// or an empty string when the unit is unknown.
+func NormalizeUnit(symbol string) string {
+	symbol = strings.TrimSpace(symbol)
+	if _, ok := units[symbol]; ok {
+		return symbol
+	}
+	if canonical, ok := unitAliases[strings.ToLower(strings.ReplaceAll(symbol, "°", "deg"))]; ok {
+		return canonical
+	}
+	if canonical, ok := unitAliases[strings.ToLower(symbol)]; ok {
+		return canonical
+	}
+	return ""
+}
+
+// UnitQuantity returns the measurement type of a unit, e.g. "temperature" for "°F".
+func UnitQuantity(symbol string) (string, bool) {
+	u, ok := units[NormalizeUnit(symbol)]
+	return u.quantity, ok
+}
+
+// ConvertUnit converts a value between two units of the same quantity.
+func ConvertUnit(value float64, from string, to string) (float64, error) {
+	fromUnit, ok := units[NormalizeUnit(from)]
+	if !ok {
+		return 0, errors.New("unknown unit " + from)
+	}
+
+	toUnit, ok := units[NormalizeUnit(to)]
+	if !ok {
+		return 0, errors.New("unknown unit " + to)
+	}
+
+	if fromUnit.quantity != toUnit.quantity {
+		return 0, errors.New("cannot convert " + fromUnit.quantity + " to " + toUnit.quantity)
+	}
+
+	// Going through the base unit leaves floating point noise such as 70.69999999999993;
+	// nine decimals is far beyond the resolution of any sensor.
+	base := value*fromUnit.scale + fromUnit.offset
+	return math.Round((base-toUnit.offset)/toUnit.scale*1e9) / 1e9, nil
+}
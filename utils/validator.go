@@ -2,36 +2,114 @@ package utils
 
 import (
 	"errors"
+	"home-monitor-backend/apperrors"
+	"reflect"
+	"strings"
 
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
+	entranslations "github.com/go-playground/validator/v10/translations/en"
+	idtranslations "github.com/go-playground/validator/v10/translations/id"
+	"golang.org/x/text/language"
 )
 
-func ValidationError(err error) map[string]string {
-	if err == nil {
-		return nil
+// validationTranslator holds the validation messages of every supported locale. English is the
+// fallback for clients that accept none of them.
+var validationTranslator *ut.UniversalTranslator
+
+// SetupValidator names fields by their JSON or query parameter name in validation errors and
+// registers the English and Indonesian messages of every validation tag and problem code. It must
+// run before the first request is bound.
+func SetupValidator() error {
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("unexpected validator engine")
 	}
+	validate.RegisterTagNameFunc(fieldName)
 
-	errorsMap := make(map[string]string)
+	english := en.New()
+	validationTranslator = ut.New(english, english, id.New())
+
+	registrations := map[string]func(v *validator.Validate, trans ut.Translator) error{
+		"en": entranslations.RegisterDefaultTranslations,
+		"id": idtranslations.RegisterDefaultTranslations,
+	}
+	for locale, register := range registrations {
+		trans, _ := validationTranslator.GetTranslator(locale)
+		if err := register(validate, trans); err != nil {
+			return err
+		}
+	}
+	return registerProblemTranslations()
+}
 
+// fieldName returns the name a client uses for a struct field: its JSON name for bodies, its
+// form name for query parameters.
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// ValidationError turns a binding error into a validation error. The messages of the invalid
+// fields are rendered later in the client's language, see ValidationMessages.
+func ValidationError(err error) error {
 	var ve validator.ValidationErrors
 	if !errors.As(err, &ve) {
-		errorsMap["error"] = "Invalid request"
-		return errorsMap
+		return apperrors.Validation("invalid_request", "Invalid request")
 	}
 
+	validationErr := apperrors.Validation("validation_failed", "Request validation failed")
+	validationErr.Err = ve
+	return validationErr
+}
+
+// ValidationMessages returns a message per invalid field of err, keyed by the path of the field,
+// e.g. readings[2].metric, in the best match for an Accept-Language header. It returns nil when
+// err holds no validation errors.
+func ValidationMessages(err error, acceptLanguage string) map[string]string {
+	var ve validator.ValidationErrors
+	if !errors.As(err, &ve) {
+		return nil
+	}
+
+	trans := validationLocale(acceptLanguage)
+	messages := make(map[string]string, len(ve))
 	for _, fieldError := range ve {
-		field := fieldError.Field()
-		switch fieldError.Tag() {
-		case "required":
-			errorsMap[field] = field + " is required"
-		case "min":
-			errorsMap[field] = field + " must be at least " + fieldError.Param() + " characters"
-		case "max":
-			errorsMap[field] = field + " must be at most " + fieldError.Param() + " characters"
-		default:
-			errorsMap[field] = "Invalid value for " + field
+		// The namespace starts with the name of the request struct, which means nothing to clients.
+		_, path, found := strings.Cut(fieldError.Namespace(), ".")
+		if !found {
+			path = fieldError.Field()
 		}
+		messages[path] = fieldError.Translate(trans)
+	}
+	return messages
+}
+
+// validationLocale picks the translator for the most preferred supported language of an
+// Accept-Language header. Without SetupValidator there is none, and messages stay untranslated.
+func validationLocale(acceptLanguage string) ut.Translator {
+	if validationTranslator == nil {
+		return nil
+	}
+
+	tags, _, _ := language.ParseAcceptLanguage(acceptLanguage)
+	candidates := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		base, _ := tag.Base()
+		candidates = append(candidates, base.String())
 	}
 
-	return errorsMap
+	trans, _ := validationTranslator.FindTranslator(candidates...)
+	return trans
 }
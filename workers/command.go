@@ -0,0 +1,35 @@
+// Package workers runs the periodic background jobs. Every worker returns once its context is
+// cancelled; jobs cut short are picked up again on the next start.
+package workers
+
+import (
+	"context"
+	"home-monitor-backend/logging"
+	"home-monitor-backend/services"
+	"log/slog"
+	"time"
+)
+
+// CommandExpiry periodically moves commands whose timeout has passed to the expired state.
+func CommandExpiry(ctx context.Context, commandService services.CommandService, interval time.Duration) {
+	ctx = logging.With(ctx, slog.String("worker", "command_expiry"))
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		expired, err := commandService.CommandExpire(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "Command expiry failed", "error", err)
+			continue
+		}
+		if expired > 0 {
+			slog.InfoContext(ctx, "Expired commands", "count", expired)
+		}
+	}
+}
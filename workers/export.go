@@ -0,0 +1,44 @@
+package workers
+
+import (
+	"context"
+	"home-monitor-backend/logging"
+	"home-monitor-backend/services"
+	"log/slog"
+	"time"
+)
+
+// Export runs queued export jobs and removes the files of expired ones. Jobs interrupted by a
+// restart are queued again first.
+func Export(ctx context.Context, exportService services.ExportService, interval time.Duration) {
+	ctx = logging.With(ctx, slog.String("worker", "export"))
+	if requeued, err := exportService.ExportRequeue(ctx); err != nil {
+		slog.ErrorContext(ctx, "Export requeue failed", "error", err)
+	} else if requeued > 0 {
+		slog.InfoContext(ctx, "Requeued interrupted export jobs", "count", requeued)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := exportService.ExportRun(ctx); err != nil {
+			slog.ErrorContext(ctx, "Export run failed", "error", err)
+		}
+
+		removed, err := exportService.ExportCleanup(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "Export cleanup failed", "error", err)
+			continue
+		}
+		if removed > 0 {
+			slog.InfoContext(ctx, "Removed expired export jobs", "count", removed)
+		}
+	}
+}
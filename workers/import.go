@@ -0,0 +1,40 @@
+package workers
+
+import (
+	"context"
+	"home-monitor-backend/logging"
+	"home-monitor-backend/services"
+	"log/slog"
+	"time"
+)
+
+// Import runs queued CSV imports. Jobs whose process died are queued again once their lease is
+// stale and continue from their last checkpoint.
+func Import(ctx context.Context, importService services.ImportService, interval time.Duration) {
+	ctx = logging.With(ctx, slog.String("worker", "import"))
+	importRequeue(ctx, importService)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		importRequeue(ctx, importService)
+		if err := importService.ImportRun(ctx); err != nil {
+			slog.ErrorContext(ctx, "Import run failed", "error", err)
+		}
+	}
+}
+
+func importRequeue(ctx context.Context, importService services.ImportService) {
+	if requeued, err := importService.ImportRequeue(ctx); err != nil {
+		slog.ErrorContext(ctx, "Import requeue failed", "error", err)
+	} else if requeued > 0 {
+		slog.InfoContext(ctx, "Requeued interrupted import jobs", "count", requeued)
+	}
+}
@@ -0,0 +1,47 @@
+package workers
+
+import (
+	"context"
+	"home-monitor-backend/logging"
+	"home-monitor-backend/services"
+	"log/slog"
+	"time"
+)
+
+// Rollup periodically materializes the 1m, 1h and 1d reading aggregates.
+func Rollup(ctx context.Context, rollupService services.RollupService, interval time.Duration) {
+	ctx = logging.With(ctx, slog.String("worker", "rollup"))
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := rollupService.RollupRun(ctx); err != nil {
+			slog.ErrorContext(ctx, "Reading rollup failed", "error", err)
+		}
+	}
+}
+
+// Retention periodically deletes readings and rollups past their retention policy.
+func Retention(ctx context.Context, retentionService services.RetentionService, interval time.Duration) {
+	ctx = logging.With(ctx, slog.String("worker", "retention"))
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := retentionService.RetentionRun(ctx); err != nil {
+			slog.ErrorContext(ctx, "Reading retention failed", "error", err)
+		}
+	}
+}